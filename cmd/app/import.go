@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"pr-review-service/internal/domain"
+	"pr-review-service/internal/importer"
+	"pr-review-service/internal/service"
+)
+
+// runImport loads a bundle from path: either a JSON file or a directory with
+// one <table>.csv file per table (teams.csv, users.csv, pull_requests.csv,
+// assignments.csv).
+func runImport(ctx context.Context, importService *service.ImportService, path string) error {
+	bundle, err := readImportBundle(path)
+	if err == nil {
+		var summary *domain.ImportSummary
+		summary, err = importService.Import(service.WithActor(ctx, "cli"), bundle)
+		if err == nil {
+			fmt.Printf("Imported %d teams, %d users, %d pull requests, %d assignments\n",
+				summary.Teams, summary.Users, summary.PullRequests, summary.Assignments)
+			return nil
+		}
+	}
+
+	var importErr *domain.ImportError
+	if errors.As(err, &importErr) {
+		for _, row := range importErr.Rows {
+			fmt.Fprintf(os.Stderr, "%s row %d: %s\n", row.Table, row.Row, row.Message)
+		}
+	}
+	return err
+}
+
+func readImportBundle(path string) (*domain.ImportBundle, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return importer.DecodeJSON(f)
+	}
+
+	tables := make(map[string]io.Reader)
+	for _, table := range []string{
+		domain.ImportTableTeams,
+		domain.ImportTableUsers,
+		domain.ImportTablePullRequests,
+		domain.ImportTableAssignments,
+	} {
+		f, err := os.Open(filepath.Join(path, table+".csv"))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		tables[table] = f
+	}
+	return importer.DecodeCSV(tables)
+}
@@ -7,13 +7,17 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"pr-review-service/internal/config"
+	"pr-review-service/internal/repository/migrate"
 	"pr-review-service/internal/repository/postgres"
+	"pr-review-service/internal/repository/sqlite"
 	"pr-review-service/internal/service"
 	httpTransport "pr-review-service/internal/transport/http"
+	"pr-review-service/migration"
 )
 
 func main() {
@@ -25,31 +29,79 @@ func main() {
 
 	ctx := context.Background()
 
-	log.Println("Connecting to database...")
-	db, err := postgres.Connect(ctx, cfg.DatabaseURL)
-	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
-	}
-	defer db.Close()
+	var store storage
+	switch {
+	case cfg.Storage == config.StorageMemory:
+		if len(os.Args) > 1 && os.Args[1] == "migrate" {
+			log.Fatalf("The migrate command needs %s storage", config.StorageDatabase)
+		}
+		log.Println("Using in-memory storage, data is lost on restart")
+		store = newMemoryStorage()
+
+	case isSQLiteURL(cfg.DatabaseURL):
+		log.Println("Opening SQLite database...")
+		db, err := sqlite.Connect(ctx, cfg.DatabaseURL)
+		if err != nil {
+			log.Fatalf("Failed to open database: %v", err)
+		}
+		defer db.Close()
 
-	log.Println("✓ Database connected")
+		log.Println("✓ Database opened")
 
-	// Run migrations
-	log.Println("Running migrations...")
-	if err := postgres.RunMigrations(ctx, db, cfg.MigrationsPath); err != nil {
-		log.Fatalf("Failed to run migrations: %v", err)
-	}
-	log.Println("✓ Migrations completed")
+		migrator, err := sqlite.NewMigrator(db, migrationSource(cfg, migration.SQLite, "sqlite"))
+		if err != nil {
+			log.Fatalf("Failed to load migrations: %v", err)
+		}
+		if handleMigrations(ctx, migrator, cfg) {
+			return
+		}
+
+		store = newSQLiteStorage(db)
+
+	default:
+		log.Println("Connecting to database...")
+		db, err := postgres.Connect(ctx, cfg.DatabaseURL)
+		if err != nil {
+			log.Fatalf("Failed to connect to database: %v", err)
+		}
+		defer db.Close()
+
+		log.Println("✓ Database connected")
+
+		migrator, err := postgres.NewMigrator(db, migrationSource(cfg, migration.FS, ""))
+		if err != nil {
+			log.Fatalf("Failed to load migrations: %v", err)
+		}
+		if handleMigrations(ctx, migrator, cfg) {
+			return
+		}
 
-	teamRepo := postgres.NewTeamRepo(db)
-	userRepo := postgres.NewUserRepo(db)
-	prRepo := postgres.NewPullRequestRepo(db)
+		store = newPostgresStorage(db)
+	}
 
-	teamService := service.NewTeamService(teamRepo, userRepo)
-	userService := service.NewUserService(userRepo, prRepo)
-	prService := service.NewPRService(prRepo, userRepo, teamRepo)
+	teamService := service.NewTeamService(store.Team, store.User, store.UoW)
+	prService := service.NewPRService(store.PullRequest, store.User, store.Team, store.History, store.UoW)
+	userService := service.NewUserService(store.User, store.PullRequest, prService, store.UoW)
+	auditService := service.NewAuditService(store.Audit)
+	statsService := service.NewStatsService(store.Stats, store.Team, store.User)
+	importService := service.NewImportService(store.UoW)
+
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "import":
+			if len(os.Args) != 3 {
+				log.Fatalf("Usage: %s import <bundle.json | directory with CSV files>", os.Args[0])
+			}
+			if err := runImport(ctx, importService, os.Args[2]); err != nil {
+				log.Fatalf("Import failed: %v", err)
+			}
+			return
+		default:
+			log.Fatalf("Unknown command %q", os.Args[1])
+		}
+	}
 
-	handler := httpTransport.NewHandler(teamService, userService, prService)
+	handler := httpTransport.NewHandler(teamService, userService, prService, auditService, statsService, importService)
 	router := httpTransport.NewRouter(handler)
 
 	server := &http.Server{
@@ -82,3 +134,37 @@ func main() {
 
 	fmt.Println("✓ Server gracefully stopped")
 }
+
+// isSQLiteURL reports whether DATABASE_URL names a SQLite database rather
+// than a Postgres one.
+func isSQLiteURL(databaseURL string) bool {
+	for _, scheme := range []string{"sqlite:", "sqlite3:", "file:"} {
+		if strings.HasPrefix(databaseURL, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// handleMigrations runs the migrate command if it was given, reporting true
+// so the caller exits, or otherwise applies pending migrations unless
+// RUN_MIGRATIONS turns that off.
+func handleMigrations(ctx context.Context, migrator *migrate.Migrator, cfg *config.Config) bool {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(ctx, migrator, cfg, os.Args[2:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return true
+	}
+
+	if cfg.RunMigrations {
+		log.Println("Running migrations...")
+		if err := runMigrate(ctx, migrator, cfg, []string{"up"}); err != nil {
+			log.Fatalf("Failed to run migrations: %v", err)
+		}
+		log.Println("✓ Migrations completed")
+	} else {
+		log.Println("Skipping migrations (RUN_MIGRATIONS=false)")
+	}
+	return false
+}
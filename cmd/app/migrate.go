@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/tabwriter"
+
+	"pr-review-service/internal/config"
+	"pr-review-service/internal/repository/migrate"
+)
+
+const migrateUsage = "migrate up | down [steps] | status | redo"
+
+// runMigrate implements the migrate subcommand.
+func runMigrate(ctx context.Context, migrator *migrate.Migrator, cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: %s", migrateUsage)
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx, cfg.SeedDemoData)
+		for _, m := range applied {
+			fmt.Printf("✓ Applied %s\n", m)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("Nothing to apply")
+		}
+		return err
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			var err error
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("steps must be a positive number, got %q", args[1])
+			}
+		}
+		rolledBack, err := migrator.Down(ctx, steps)
+		for _, m := range rolledBack {
+			fmt.Printf("✓ Rolled back %s\n", m)
+		}
+		return err
+
+	case "redo":
+		m, err := migrator.Redo(ctx)
+		if err != nil {
+			return err
+		}
+		if m == nil {
+			fmt.Println("Nothing to redo")
+			return nil
+		}
+		fmt.Printf("✓ Redone %s\n", m)
+		return nil
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		return printMigrationStatus(statuses)
+
+	default:
+		return fmt.Errorf("unknown migrate command %q, usage: %s", args[0], migrateUsage)
+	}
+}
+
+// migrationSource returns the embedded migrations unless MIGRATIONS_PATH
+// points at a directory to use instead. MIGRATIONS_PATH is laid out like
+// migration/, so backends with migrations of their own read them from subdir
+// of it, just as embedded is that subdirectory of the embedded files.
+func migrationSource(cfg *config.Config, embedded fs.FS, subdir string) fs.FS {
+	if cfg.MigrationsPath != "" {
+		return os.DirFS(filepath.Join(cfg.MigrationsPath, subdir))
+	}
+	return embedded
+}
+
+func printMigrationStatus(statuses []migrate.Status) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KIND\tVERSION\tNAME\tAPPLIED AT\tNOTE")
+	for _, s := range statuses {
+		appliedAt := "pending"
+		if s.AppliedAt != nil {
+			appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+
+		note := ""
+		switch {
+		case s.Missing:
+			note = "not in source"
+		case s.Modified:
+			note = "modified since applied"
+		}
+
+		fmt.Fprintf(w, "%s\t%02d\t%s\t%s\t%s\n", s.Kind, s.Version, s.Name, appliedAt, note)
+	}
+	return w.Flush()
+}
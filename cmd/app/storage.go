@@ -0,0 +1,62 @@
+package main
+
+import (
+	"database/sql"
+
+	"pr-review-service/internal/repository"
+	"pr-review-service/internal/repository/memory"
+	"pr-review-service/internal/repository/postgres"
+	"pr-review-service/internal/repository/sqlite"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// storage is the set of repositories the services are built on.
+type storage struct {
+	repository.Repository
+	Stats repository.StatsRepository
+	UoW   repository.UnitOfWork
+}
+
+func newPostgresStorage(db *pgxpool.Pool) storage {
+	return storage{
+		Repository: repository.Repository{
+			Team:        postgres.NewTeamRepo(db),
+			User:        postgres.NewUserRepo(db),
+			PullRequest: postgres.NewPullRequestRepo(db),
+			Audit:       postgres.NewAuditRepo(db),
+			History:     postgres.NewAssignmentHistoryRepo(db),
+		},
+		Stats: postgres.NewStatsRepo(db),
+		UoW:   postgres.NewUnitOfWork(db),
+	}
+}
+
+func newSQLiteStorage(db *sql.DB) storage {
+	return storage{
+		Repository: repository.Repository{
+			Team:        sqlite.NewTeamRepo(db),
+			User:        sqlite.NewUserRepo(db),
+			PullRequest: sqlite.NewPullRequestRepo(db),
+			Audit:       sqlite.NewAuditRepo(db),
+			History:     sqlite.NewAssignmentHistoryRepo(db),
+		},
+		Stats: sqlite.NewStatsRepo(db),
+		UoW:   sqlite.NewUnitOfWork(db),
+	}
+}
+
+func newMemoryStorage() storage {
+	store := memory.NewStore()
+	return storage{
+		Repository: repository.Repository{
+			Team:        memory.NewTeamRepo(store),
+			User:        memory.NewUserRepo(store),
+			PullRequest: memory.NewPullRequestRepo(store),
+			Audit:       memory.NewAuditRepo(store),
+			History:     memory.NewAssignmentHistoryRepo(store),
+		},
+		Stats: memory.NewStatsRepo(store),
+		UoW:   memory.NewUnitOfWork(store),
+	}
+}
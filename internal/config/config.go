@@ -6,10 +6,29 @@ import (
 	"github.com/kelseyhightower/envconfig"
 )
 
+const (
+	// StorageDatabase keeps data in the database DATABASE_URL points at,
+	// Postgres or SQLite depending on its scheme.
+	StorageDatabase = "database"
+	// StoragePostgres is the former name of StorageDatabase.
+	StoragePostgres = "postgres"
+	// StorageMemory keeps all data in process memory, for tests and demos.
+	StorageMemory = "memory"
+)
+
 type Config struct {
-	ServerPort     string `envconfig:"SERVER_PORT" default:"8080"`
-	DatabaseURL    string `envconfig:"DATABASE_URL" required:"true"`
-	MigrationsPath string `envconfig:"MIGRATIONS_PATH" default:"./migrations"`
+	ServerPort string `envconfig:"SERVER_PORT" default:"8080"`
+	Storage    string `envconfig:"STORAGE" default:"database"`
+	// DatabaseURL is required with database storage. sqlite: and file: URLs
+	// select SQLite, anything else Postgres.
+	DatabaseURL string `envconfig:"DATABASE_URL"`
+	// MigrationsPath overrides the migrations embedded in the binary.
+	MigrationsPath string `envconfig:"MIGRATIONS_PATH"`
+	// RunMigrations applies pending migrations at startup. Deployments that
+	// migrate with the migrate command beforehand can turn it off.
+	RunMigrations bool `envconfig:"RUN_MIGRATIONS" default:"true"`
+	// SeedDemoData also applies the demo data migrations.
+	SeedDemoData bool `envconfig:"SEED_DEMO_DATA" default:"false"`
 }
 
 func Load() (*Config, error) {
@@ -17,5 +36,17 @@ func Load() (*Config, error) {
 	if err := envconfig.Process("", &cfg); err != nil {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
+
+	switch cfg.Storage {
+	case StorageDatabase, StoragePostgres:
+		cfg.Storage = StorageDatabase
+		if cfg.DatabaseURL == "" {
+			return nil, fmt.Errorf("failed to load config: DATABASE_URL is required with %s storage", cfg.Storage)
+		}
+	case StorageMemory:
+	default:
+		return nil, fmt.Errorf("failed to load config: unknown STORAGE %q", cfg.Storage)
+	}
+
 	return &cfg, nil
 }
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	AuditEntityTeam        = "team"
+	AuditEntityUser        = "user"
+	AuditEntityPullRequest = "pull_request"
+	AuditEntityImport      = "import"
+)
+
+const (
+	AuditActionTeamCreate     = "team.create"
+	AuditActionTeamDeactivate = "team.deactivate"
+	AuditActionTeamActivate   = "team.activate"
+	AuditActionUserSetActive  = "user.set_active"
+	AuditActionUserRename     = "user.rename"
+	AuditActionUserDelete     = "user.delete"
+	AuditActionUserAnonymize  = "user.anonymize"
+	AuditActionPRCreate       = "pr.create"
+	AuditActionPRMerge        = "pr.merge"
+	AuditActionPRReassign     = "pr.reassign"
+	AuditActionPRAssign       = "pr.assign"
+	AuditActionPRUnassign     = "pr.unassign"
+	AuditActionPRUpdate       = "pr.update"
+	AuditActionImport         = "import.load"
+)
+
+type AuditEvent struct {
+	ID         int64           `json:"id"`
+	Actor      string          `json:"actor"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   string          `json:"entity_id"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// AuditFilter selects audit events, newest first. Empty fields do not filter;
+// BeforeID continues a previous page.
+type AuditFilter struct {
+	EntityType string
+	EntityID   string
+	Actor      string
+	From       *time.Time
+	To         *time.Time
+	BeforeID   int64
+	Limit      int
+}
+
+// RedactUsername replaces the username of every object carrying userID
+// anywhere inside an audit snapshot.
+func RedactUsername(snapshot json.RawMessage, userID, replacement string) (json.RawMessage, error) {
+	if len(snapshot) == 0 {
+		return snapshot, nil
+	}
+
+	var doc any
+	if err := json.Unmarshal(snapshot, &doc); err != nil {
+		return nil, err
+	}
+
+	var redact func(v any) any
+	redact = func(v any) any {
+		switch v := v.(type) {
+		case map[string]any:
+			for key, value := range v {
+				v[key] = redact(value)
+			}
+			if id, ok := v["user_id"].(string); ok && id == userID {
+				if _, ok := v["username"]; ok {
+					v["username"] = replacement
+				}
+			}
+		case []any:
+			for i := range v {
+				v[i] = redact(v[i])
+			}
+		}
+		return v
+	}
+
+	return json.Marshal(redact(doc))
+}
@@ -9,6 +9,19 @@ const (
 	ErrCodeNotAssigned = "NOT_ASSIGNED"
 	ErrCodeNoCandidate = "NO_CANDIDATE"
 	ErrCodeNotFound    = "NOT_FOUND"
+
+	ErrCodeAlreadyAssigned  = "ALREADY_ASSIGNED"
+	ErrCodeReviewerInactive = "REVIEWER_INACTIVE"
+	ErrCodeReviewerIsAuthor = "REVIEWER_IS_AUTHOR"
+	ErrCodeTooManyReviewers = "TOO_MANY_REVIEWERS"
+	ErrCodeAuthorImmutable  = "AUTHOR_IMMUTABLE"
+
+	ErrCodeUserDeleted    = "USER_DELETED"
+	ErrCodeUserHasOpenPRs = "USER_HAS_OPEN_PRS"
+	ErrCodeUserExists     = "USER_EXISTS"
+
+	ErrCodeVersionMismatch = "VERSION_MISMATCH"
+	ErrCodeInvalidInput    = "INVALID_INPUT"
 )
 
 type DomainError struct {
@@ -37,6 +50,26 @@ var (
 	ErrUserNotFound   = NewDomainError(ErrCodeNotFound, "user not found")
 	ErrPRNotFound     = NewDomainError(ErrCodeNotFound, "pull request not found")
 	ErrAuthorNotFound = NewDomainError(ErrCodeNotFound, "author not found")
+	ErrNotTeamMember  = NewDomainError(ErrCodeNotFound, "user is not a member of the team")
+
+	ErrAlreadyAssigned  = NewDomainError(ErrCodeAlreadyAssigned, "reviewer is already assigned to this PR")
+	ErrReviewerInactive = NewDomainError(ErrCodeReviewerInactive, "reviewer is not active")
+	ErrReviewerIsAuthor = NewDomainError(ErrCodeReviewerIsAuthor, "author cannot review their own PR")
+	ErrTooManyReviewers = NewDomainError(ErrCodeTooManyReviewers, "PR already has the maximum number of reviewers")
+	ErrAuthorImmutable  = NewDomainError(ErrCodeAuthorImmutable, "PR author cannot be changed")
+
+	ErrUserDeleted    = NewDomainError(ErrCodeUserDeleted, "user is deleted")
+	ErrUserHasOpenPRs = NewDomainError(ErrCodeUserHasOpenPRs, "user is the author of open pull requests")
+	ErrUserExists     = NewDomainError(ErrCodeUserExists, "user already exists")
+
+	ErrVersionMismatch = NewDomainError(ErrCodeVersionMismatch, "resource was modified, version does not match")
+	ErrInvalidCursor   = NewDomainError(ErrCodeInvalidInput, "invalid cursor")
+	ErrInvalidSort     = NewDomainError(ErrCodeInvalidInput, "unsupported sort field")
+	ErrInvalidWindow   = NewDomainError(ErrCodeInvalidInput, "invalid time window")
+	ErrInvalidPRName   = NewDomainError(ErrCodeInvalidInput, "pull request name must not be empty")
+	ErrInvalidPRURL    = NewDomainError(ErrCodeInvalidInput, "pull request URL must be an absolute http(s) URL")
+	ErrInvalidPRSize   = NewDomainError(ErrCodeInvalidInput, "unsupported pull request size")
+	ErrInvalidLabel    = NewDomainError(ErrCodeInvalidInput, "labels must not be empty")
 )
 
 var (
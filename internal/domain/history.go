@@ -0,0 +1,47 @@
+package domain
+
+import "time"
+
+// AssignmentReason explains why a reviewer was assigned to or removed from a PR.
+type AssignmentReason string
+
+const (
+	AssignmentReasonInitial          AssignmentReason = "INITIAL"
+	AssignmentReasonRequested        AssignmentReason = "REQUESTED"
+	AssignmentReasonManualReassign   AssignmentReason = "MANUAL_REASSIGN"
+	AssignmentReasonManual           AssignmentReason = "MANUAL"
+	AssignmentReasonReroll           AssignmentReason = "REROLL"
+	AssignmentReasonTeamDeactivation AssignmentReason = "TEAM_DEACTIVATION"
+	AssignmentReasonUserDeleted      AssignmentReason = "USER_DELETED"
+	AssignmentReasonRebalance        AssignmentReason = "REBALANCE"
+)
+
+// AssignmentRecord is one stint of a reviewer on a PR. Unassigned fields stay
+// empty while the reviewer is still assigned.
+type AssignmentRecord struct {
+	PullRequestID  string           `json:"pull_request_id"`
+	UserID         string           `json:"user_id"`
+	AssignedAt     time.Time        `json:"assigned_at"`
+	AssignedBy     string           `json:"assigned_by"`
+	AssignReason   AssignmentReason `json:"assign_reason"`
+	UnassignedAt   *time.Time       `json:"unassigned_at,omitempty"`
+	UnassignedBy   string           `json:"unassigned_by,omitempty"`
+	UnassignReason AssignmentReason `json:"unassign_reason,omitempty"`
+}
+
+// ReassignmentOutcome tells what became of a review taken from a reviewer.
+type ReassignmentOutcome string
+
+const (
+	ReassignmentOutcomeReassigned     ReassignmentOutcome = "REASSIGNED"
+	ReassignmentOutcomeLeftUnassigned ReassignmentOutcome = "LEFT_UNASSIGNED"
+)
+
+// ReviewerReassignment moves one review from OldReviewerID to NewReviewerID.
+// NewReviewerID is empty when nobody could take the review over.
+type ReviewerReassignment struct {
+	PullRequestID string              `json:"pull_request_id"`
+	OldReviewerID string              `json:"old_reviewer_id"`
+	NewReviewerID string              `json:"new_reviewer_id,omitempty"`
+	Outcome       ReassignmentOutcome `json:"outcome"`
+}
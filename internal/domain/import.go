@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Tables of an import bundle, also used as CSV file and form field names.
+const (
+	ImportTableTeams        = "teams"
+	ImportTableUsers        = "users"
+	ImportTablePullRequests = "pull_requests"
+	ImportTableAssignments  = "assignments"
+)
+
+// ImportBundle is a batch of records migrated from another tool. Everything
+// in it is new: rows that collide with stored ones are rejected.
+type ImportBundle struct {
+	Teams        []ImportTeam        `json:"teams"`
+	Users        []ImportUser        `json:"users"`
+	PullRequests []ImportPullRequest `json:"pull_requests"`
+	Assignments  []ImportAssignment  `json:"assignments"`
+}
+
+type ImportTeam struct {
+	TeamName string `json:"team_name"`
+}
+
+// ImportUser is active unless IsActive says otherwise.
+type ImportUser struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	TeamName string `json:"team_name"`
+	IsActive *bool  `json:"is_active"`
+}
+
+// ImportPullRequest defaults to OPEN and to being created at import time.
+type ImportPullRequest struct {
+	PullRequestID   string     `json:"pull_request_id"`
+	PullRequestName string     `json:"pull_request_name"`
+	AuthorID        string     `json:"author_id"`
+	Status          PRStatus   `json:"status"`
+	CreatedAt       *time.Time `json:"created_at"`
+	MergedAt        *time.Time `json:"merged_at"`
+}
+
+// ImportAssignment is a current reviewer of an imported PR. AssignedAt
+// defaults to the PR creation time.
+type ImportAssignment struct {
+	PullRequestID string     `json:"pull_request_id"`
+	UserID        string     `json:"user_id"`
+	AssignedAt    *time.Time `json:"assigned_at"`
+}
+
+type ImportSummary struct {
+	Teams        int `json:"teams"`
+	Users        int `json:"users"`
+	PullRequests int `json:"pull_requests"`
+	Assignments  int `json:"assignments"`
+}
+
+// ImportRowError points at an invalid row; Row is 1-based within its table.
+type ImportRowError struct {
+	Table   string `json:"table"`
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
+
+// ImportError rejects a whole bundle because some of its rows are invalid.
+type ImportError struct {
+	Rows []ImportRowError
+}
+
+func (e *ImportError) Error() string {
+	return fmt.Sprintf("import bundle has %d invalid rows", len(e.Rows))
+}
@@ -12,6 +12,7 @@ const (
 type Team struct {
 	TeamName string       `json:"team_name"`
 	Members  []TeamMember `json:"members"`
+	Version  int          `json:"-"`
 }
 
 type TeamMember struct {
@@ -20,23 +21,113 @@ type TeamMember struct {
 	IsActive bool   `json:"is_active"`
 }
 
+// User is kept after deletion so PR history stays intact; DeletedAt marks
+// such users, which are no longer team members.
 type User struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	TeamName string `json:"team_name"`
-	IsActive bool   `json:"is_active"`
+	UserID    string     `json:"user_id"`
+	Username  string     `json:"username"`
+	TeamName  string     `json:"team_name"`
+	IsActive  bool       `json:"is_active"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// AnonymizedUsername replaces the username of anonymized users.
+const AnonymizedUsername = "anonymized user"
+
+// UserActivity is computed from a user's reviews and authored PRs.
+type UserActivity struct {
+	OpenReviewCount     int        `json:"open_review_count"`
+	AuthoredOpenPRCount int        `json:"authored_open_pr_count"`
+	LastAssignedAt      *time.Time `json:"last_assigned_at,omitempty"`
+}
+
+// UserProfile is a user together with its activity, as served by the user
+// directory.
+type UserProfile struct {
+	User
+	UserActivity
 }
 
+// UserFilter selects users for the directory, ordered by username and ID.
+// Empty fields do not filter; After continues a previous page.
+type UserFilter struct {
+	TeamName   string
+	IsActive   *bool
+	NamePrefix string
+	After      *UserPageKey
+	Limit      int
+}
+
+type UserPageKey struct {
+	Username string
+	UserID   string
+}
+
+// PRSize is a rough estimate of how big a change is.
+type PRSize string
+
+const (
+	PRSizeXS PRSize = "XS"
+	PRSizeS  PRSize = "S"
+	PRSizeM  PRSize = "M"
+	PRSizeL  PRSize = "L"
+	PRSizeXL PRSize = "XL"
+)
+
+// PullRequest metadata (Description through Size) is optional and empty until
+// set through an update.
 type PullRequest struct {
 	PullRequestID     string     `json:"pull_request_id"`
 	PullRequestName   string     `json:"pull_request_name"`
 	AuthorID          string     `json:"author_id"`
 	Status            PRStatus   `json:"status"`
 	AssignedReviewers []string   `json:"assigned_reviewers"`
+	Description       string     `json:"description"`
+	URL               string     `json:"url"`
+	Repository        string     `json:"repository"`
+	Labels            []string   `json:"labels"`
+	Size              PRSize     `json:"size"`
 	CreatedAt         *time.Time `json:"createdAt,omitempty"`
 	MergedAt          *time.Time `json:"mergedAt,omitempty"`
+	Version           int        `json:"-"`
 }
 
+// VersionMatch is the If-Match precondition of a write. The zero value, for a
+// request without one, matches every version; otherwise only the listed
+// versions match, so a header with nothing but weak tags matches none.
+type VersionMatch struct {
+	Required bool
+	Versions []int
+}
+
+// Matches reports whether version satisfies the precondition.
+func (m VersionMatch) Matches(version int) bool {
+	if !m.Required {
+		return true
+	}
+	for _, v := range m.Versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// PRUpdate lists the PR fields to change; nil fields keep their value. The
+// author cannot change, so AuthorID may only repeat the current one.
+type PRUpdate struct {
+	PullRequestName *string
+	AuthorID        *string
+	Description     *string
+	URL             *string
+	Repository      *string
+	Labels          *[]string
+	Size            *PRSize
+}
+
+// MaxReviewers is how many reviewers a PR may have at once.
+const MaxReviewers = 2
+
 type PullRequestShort struct {
 	PullRequestID   string   `json:"pull_request_id"`
 	PullRequestName string   `json:"pull_request_name"`
@@ -49,3 +140,36 @@ type UserStats struct {
 	Username    string `json:"username"`
 	ReviewCount int    `json:"review_count"`
 }
+
+// PRSortField is a column PR listings can be ordered by.
+type PRSortField string
+
+const (
+	PRSortCreatedAt PRSortField = "created_at"
+	PRSortMergedAt  PRSortField = "merged_at"
+	PRSortName      PRSortField = "pull_request_name"
+)
+
+// PRFilter selects pull requests for listing. Empty fields do not filter;
+// After continues a previous page.
+type PRFilter struct {
+	Status      PRStatus
+	AuthorID    string
+	ReviewerID  string
+	TeamName    string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	MergedFrom  *time.Time
+	MergedTo    *time.Time
+	SortBy      PRSortField
+	Descending  bool
+	After       *PRPageKey
+	Limit       int
+}
+
+// PRPageKey is the position of the last PR of a page: its sort value in text
+// form and its ID as a tie-breaker.
+type PRPageKey struct {
+	SortValue     string
+	PullRequestID string
+}
@@ -0,0 +1,120 @@
+package domain
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// UserStatsFilter selects the reviewer leaderboard. A nil From or To leaves
+// that side of the assignment time window open.
+type UserStatsFilter struct {
+	From  *time.Time
+	To    *time.Time
+	Limit int
+}
+
+// MetricsFilter scopes review metrics to the window [From, To) and optionally
+// to one team or one user. PR metrics follow the PR author, review metrics
+// follow the reviewer.
+type MetricsFilter struct {
+	TeamName string
+	UserID   string
+	From     time.Time
+	To       time.Time
+}
+
+// DurationStats summarizes a set of durations. Median and P90 are nil when
+// Count is zero.
+type DurationStats struct {
+	Count         int      `json:"count"`
+	MedianSeconds *float64 `json:"median_seconds"`
+	P90Seconds    *float64 `json:"p90_seconds"`
+}
+
+// NewDurationStats summarizes seconds, sorting it in place. Percentiles
+// interpolate between the two closest values, like percentile_cont.
+func NewDurationStats(seconds []float64) DurationStats {
+	stats := DurationStats{Count: len(seconds)}
+	if len(seconds) == 0 {
+		return stats
+	}
+
+	sort.Float64s(seconds)
+	percentile := func(p float64) *float64 {
+		pos := p * float64(len(seconds)-1)
+		lower := int(math.Floor(pos))
+		value := seconds[lower]
+		if lower+1 < len(seconds) {
+			value += (pos - float64(lower)) * (seconds[lower+1] - seconds[lower])
+		}
+		return &value
+	}
+	stats.MedianSeconds = percentile(0.5)
+	stats.P90Seconds = percentile(0.9)
+	return stats
+}
+
+// WeeklyThroughput counts PRs opened and merged in the week starting on
+// Monday WeekStart.
+type WeeklyThroughput struct {
+	WeekStart time.Time `json:"week_start"`
+	Opened    int       `json:"opened"`
+	Merged    int       `json:"merged"`
+}
+
+// ReviewMetrics are computed over MetricsFilter's window:
+//   - TimeToMerge covers PRs merged in the window;
+//   - TimeToFirstReview is the time from PR creation to its first reviewer
+//     assignment, for PRs created in the window;
+//   - ReviewsCompleted counts reviewers still assigned when their PR was
+//     merged in the window.
+type ReviewMetrics struct {
+	TeamName          string             `json:"team_name,omitempty"`
+	UserID            string             `json:"user_id,omitempty"`
+	From              time.Time          `json:"from"`
+	To                time.Time          `json:"to"`
+	TimeToMerge       DurationStats      `json:"time_to_merge"`
+	TimeToFirstReview DurationStats      `json:"time_to_first_review"`
+	Throughput        []WeeklyThroughput `json:"throughput"`
+	ReviewsCompleted  int                `json:"reviews_completed"`
+}
+
+// FairnessFilter selects the review load of current members over [From, To),
+// of one team or of all teams when TeamName is empty.
+type FairnessFilter struct {
+	TeamName string
+	From     time.Time
+	To       time.Time
+}
+
+// MemberLoad compares the reviews a member got with the share of the team's
+// reviews their active time entitles them to. LoadRatio is nil for members
+// who were never active in the window.
+type MemberLoad struct {
+	UserID              string   `json:"user_id"`
+	Username            string   `json:"username"`
+	TeamName            string   `json:"-"`
+	Assignments         int      `json:"assignments"`
+	ActiveDays          float64  `json:"active_days"`
+	ExpectedAssignments float64  `json:"expected_assignments"`
+	LoadRatio           *float64 `json:"load_ratio"`
+}
+
+// TeamFairness is the review load distribution of one team. Gini is computed
+// over assignments per active day: 0 is a perfectly even load, values close
+// to 1 mean a few members get almost all reviews.
+type TeamFairness struct {
+	TeamName    string       `json:"team_name"`
+	Assignments int          `json:"assignments"`
+	Gini        float64      `json:"gini"`
+	Members     []MemberLoad `json:"members"`
+	Overloaded  []string     `json:"overloaded"`
+	Underloaded []string     `json:"underloaded"`
+}
+
+type FairnessReport struct {
+	From  time.Time      `json:"from"`
+	To    time.Time      `json:"to"`
+	Teams []TeamFairness `json:"teams"`
+}
@@ -0,0 +1,179 @@
+// Package importer reads import bundles from the formats clients upload.
+package importer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"pr-review-service/internal/domain"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DecodeJSON reads a bundle shaped like domain.ImportBundle.
+func DecodeJSON(r io.Reader) (*domain.ImportBundle, error) {
+	var bundle domain.ImportBundle
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&bundle); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+	return &bundle, nil
+}
+
+// DecodeCSV reads a bundle from one CSV per table, keyed by the
+// domain.ImportTable* names. Missing tables are empty. Each CSV starts with a
+// header naming its columns, in any order; is_active, status and the
+// timestamps (RFC 3339) may be left empty to use their defaults.
+func DecodeCSV(tables map[string]io.Reader) (*domain.ImportBundle, error) {
+	order := []string{
+		domain.ImportTableTeams,
+		domain.ImportTableUsers,
+		domain.ImportTablePullRequests,
+		domain.ImportTableAssignments,
+	}
+	for table := range tables {
+		if !slices.Contains(order, table) {
+			return nil, fmt.Errorf("%w: unknown table %q", domain.ErrInvalidInput, table)
+		}
+	}
+
+	bundle := &domain.ImportBundle{}
+	var rowErrors []domain.ImportRowError
+
+	for _, table := range order {
+		r, ok := tables[table]
+		if !ok {
+			continue
+		}
+		rows, err := readTable(r)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", domain.ErrInvalidInput, table, err)
+		}
+
+		for i, row := range rows {
+			var err error
+			switch table {
+			case domain.ImportTableTeams:
+				bundle.Teams = append(bundle.Teams, domain.ImportTeam{TeamName: row["team_name"]})
+			case domain.ImportTableUsers:
+				err = appendUser(bundle, row)
+			case domain.ImportTablePullRequests:
+				err = appendPullRequest(bundle, row)
+			case domain.ImportTableAssignments:
+				err = appendAssignment(bundle, row)
+			}
+			if err != nil {
+				rowErrors = append(rowErrors, domain.ImportRowError{Table: table, Row: i + 1, Message: err.Error()})
+			}
+		}
+	}
+
+	if len(rowErrors) > 0 {
+		return nil, &domain.ImportError{Rows: rowErrors}
+	}
+	return bundle, nil
+}
+
+// readTable returns the data rows of a CSV as column name -> value.
+func readTable(r io.Reader) ([]map[string]string, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+
+	var rows []map[string]string
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]string, len(header))
+		for i, name := range header {
+			row[name] = record[i]
+		}
+		rows = append(rows, row)
+	}
+}
+
+func appendUser(bundle *domain.ImportBundle, row map[string]string) error {
+	user := domain.ImportUser{
+		UserID:   row["user_id"],
+		Username: row["username"],
+		TeamName: row["team_name"],
+	}
+	if value := row["is_active"]; value != "" {
+		isActive, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.New("is_active must be true or false")
+		}
+		user.IsActive = &isActive
+	}
+
+	bundle.Users = append(bundle.Users, user)
+	return nil
+}
+
+func appendPullRequest(bundle *domain.ImportBundle, row map[string]string) error {
+	pr := domain.ImportPullRequest{
+		PullRequestID:   row["pull_request_id"],
+		PullRequestName: row["pull_request_name"],
+		AuthorID:        row["author_id"],
+		Status:          domain.PRStatus(row["status"]),
+	}
+
+	var err error
+	if pr.CreatedAt, err = parseTime(row, "created_at"); err != nil {
+		return err
+	}
+	if pr.MergedAt, err = parseTime(row, "merged_at"); err != nil {
+		return err
+	}
+
+	bundle.PullRequests = append(bundle.PullRequests, pr)
+	return nil
+}
+
+func appendAssignment(bundle *domain.ImportBundle, row map[string]string) error {
+	assignment := domain.ImportAssignment{
+		PullRequestID: row["pull_request_id"],
+		UserID:        row["user_id"],
+	}
+
+	var err error
+	if assignment.AssignedAt, err = parseTime(row, "assigned_at"); err != nil {
+		return err
+	}
+
+	bundle.Assignments = append(bundle.Assignments, assignment)
+	return nil
+}
+
+func parseTime(row map[string]string, column string) (*time.Time, error) {
+	value := row[column]
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an RFC 3339 timestamp", column)
+	}
+	return &t, nil
+}
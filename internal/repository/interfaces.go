@@ -10,6 +10,13 @@ type TeamRepository interface {
 	Get(ctx context.Context, teamName string) (*domain.Team, error)
 	Exists(ctx context.Context, teamName string) (bool, error)
 	DeactivateAll(ctx context.Context, teamName string) error
+	// ActivateAll activates the listed members of the team, or every member
+	// when userIDs is empty. Deleted users stay inactive.
+	ActivateAll(ctx context.Context, teamName string, userIDs []string) error
+	// BumpVersion increments the team version and returns the new one. A
+	// non-zero expectedVersion must match the stored version, otherwise
+	// domain.ErrVersionMismatch is returned.
+	BumpVersion(ctx context.Context, teamName string, expectedVersion int) (int, error)
 }
 
 type UserRepository interface {
@@ -18,28 +25,122 @@ type UserRepository interface {
 	Get(ctx context.Context, userID string) (*domain.User, error)
 	GetByTeam(ctx context.Context, teamName string) ([]domain.User, error)
 	GetActiveByTeam(ctx context.Context, teamName string) ([]domain.User, error)
+	// GetByIDs returns the users among userIDs that exist, deleted ones
+	// included, keyed by ID.
+	GetByIDs(ctx context.Context, userIDs []string) (map[string]domain.User, error)
+	// GetActiveByTeams is GetActiveByTeam for several teams in one round trip,
+	// keyed by team name. Teams without active members are absent.
+	GetActiveByTeams(ctx context.Context, teamNames []string) (map[string][]domain.User, error)
 	SetIsActive(ctx context.Context, userID string, isActive bool) (*domain.User, error)
-	GetStats(ctx context.Context, limit int) ([]domain.UserStats, error)
+	GetStats(ctx context.Context, filter domain.UserStatsFilter) ([]domain.UserStats, error)
+	// StreamStats calls fn for every row GetStats would return without
+	// loading them all; a zero filter.Limit means no limit.
+	StreamStats(ctx context.Context, filter domain.UserStatsFilter, fn func(*domain.UserStats) error) error
+	List(ctx context.Context, filter domain.UserFilter) ([]domain.User, error)
+	// GetActivity computes activity for all userIDs in one round trip. Every
+	// requested ID is present in the result, unknown ones with zero activity.
+	GetActivity(ctx context.Context, userIDs []string) (map[string]domain.UserActivity, error)
 }
 
 type PullRequestRepository interface {
 	Create(ctx context.Context, pr *domain.PullRequest) error
 	Get(ctx context.Context, prID string) (*domain.PullRequest, error)
+	// GetForUpdate is Get that also locks the PR until the surrounding
+	// transaction ends, serializing concurrent mutations of the same PR.
+	GetForUpdate(ctx context.Context, prID string) (*domain.PullRequest, error)
+	// Update saves pr only if pr.Version still matches the stored version and
+	// then increments pr.Version; otherwise it returns domain.ErrVersionMismatch.
 	Update(ctx context.Context, pr *domain.PullRequest) error
 	Exists(ctx context.Context, prID string) (bool, error)
 	GetByReviewer(ctx context.Context, userID string) ([]domain.PullRequestShort, error)
+	// List returns PRs matching filter, with reviewers, in filter.SortBy order
+	// with the PR ID as tie-breaker.
+	List(ctx context.Context, filter domain.PRFilter) ([]domain.PullRequest, error)
+	// Stream calls fn for every PR List would return without loading them
+	// all; a zero filter.Limit means no limit.
+	Stream(ctx context.Context, filter domain.PRFilter, fn func(*domain.PullRequest) error) error
 
 	AssignReviewer(ctx context.Context, prID, userID string) error
 	RemoveReviewer(ctx context.Context, prID, userID string) error
 	GetReviewers(ctx context.Context, prID string) ([]string, error)
 	IsReviewer(ctx context.Context, prID, userID string) (bool, error)
 
+	// GetOpenPRsByReviewers returns the open PRs any of userIDs reviews, in PR
+	// ID order.
 	GetOpenPRsByReviewers(ctx context.Context, userIDs []string) ([]domain.PullRequest, error)
+	// GetOpenPRsByReviewersForUpdate is GetOpenPRsByReviewers that also locks
+	// the PRs like GetForUpdate. Locks are taken in PR ID order, so concurrent
+	// callers cannot deadlock.
+	GetOpenPRsByReviewersForUpdate(ctx context.Context, userIDs []string) ([]domain.PullRequest, error)
+	// ReassignReviewersInBatch replaces oldUserID on every PR of newAssignments
+	// (PR ID -> new reviewer) with a constant number of statements. An empty
+	// new reviewer only removes oldUserID.
 	ReassignReviewersInBatch(ctx context.Context, oldUserID string, newAssignments map[string]string) error
+	// BumpVersions increments the version of every PR in prIDs with one
+	// statement, whatever their current version, and returns the new versions
+	// by PR ID. Unknown IDs are skipped.
+	BumpVersions(ctx context.Context, prIDs []string) (map[string]int, error)
+}
+
+// StatsRepository computes read-only aggregates over PRs and assignments.
+type StatsRepository interface {
+	GetReviewMetrics(ctx context.Context, filter domain.MetricsFilter) (*domain.ReviewMetrics, error)
+	// GetMemberLoad returns assignments and active days of every member in
+	// scope; the expected load and ratio are left for the caller.
+	GetMemberLoad(ctx context.Context, filter domain.FairnessFilter) ([]domain.MemberLoad, error)
+}
+
+// AuditRepository is append-only: events are never deleted, and the only
+// update allowed is scrubbing an anonymized user's name from snapshots.
+type AuditRepository interface {
+	Append(ctx context.Context, event *domain.AuditEvent) error
+	// AppendBatch appends events in order with one statement. Unlike Append,
+	// it leaves their IDs and timestamps unset.
+	AppendBatch(ctx context.Context, events []domain.AuditEvent) error
+	// RedactUsername replaces the username of userID in every stored snapshot.
+	RedactUsername(ctx context.Context, userID, replacement string) error
+	List(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEvent, error)
+}
+
+// AssignmentHistoryRepository keeps every assignment and unassignment of
+// reviewers, which pr_reviewers forgets once a reviewer is removed.
+type AssignmentHistoryRepository interface {
+	RecordAssigned(ctx context.Context, prID, userID string, reason domain.AssignmentReason, actor string) error
+	// RecordUnassigned closes the open assignment of userID on prID.
+	RecordUnassigned(ctx context.Context, prID, userID string, reason domain.AssignmentReason, actor string) error
+	// RecordReassignments closes the assignments of the old reviewers and
+	// opens those of the new ones for every entry at once.
+	RecordReassignments(ctx context.Context, reassignments []domain.ReviewerReassignment, reason domain.AssignmentReason, actor string) error
+	ListByPR(ctx context.Context, prID string) ([]domain.AssignmentRecord, error)
+	// Stream calls fn for every entry of the PRs matching filter's row
+	// filters; sorting and paging fields are ignored.
+	Stream(ctx context.Context, filter domain.PRFilter, fn func(*domain.AssignmentRecord) error) error
+}
+
+// ImportRepository bulk-loads validated import bundles.
+type ImportRepository interface {
+	// ExistingTeams, ExistingUsers and ExistingPullRequests return the subset
+	// of the given keys that is already stored.
+	ExistingTeams(ctx context.Context, teamNames []string) (map[string]bool, error)
+	ExistingUsers(ctx context.Context, userIDs []string) (map[string]bool, error)
+	ExistingPullRequests(ctx context.Context, prIDs []string) (map[string]bool, error)
+	// Load inserts the bundle, which must be valid and have all defaults
+	// filled in. Assignments are recorded in the history as well.
+	Load(ctx context.Context, bundle *domain.ImportBundle, actor string) error
 }
 
 type Repository struct {
 	Team        TeamRepository
 	User        UserRepository
 	PullRequest PullRequestRepository
+	Audit       AuditRepository
+	History     AssignmentHistoryRepository
+	Import      ImportRepository
+}
+
+// UnitOfWork runs fn in a single transaction. The repositories handed to fn
+// are bound to that transaction: returning an error from fn rolls back every
+// change made through them, returning nil commits.
+type UnitOfWork interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context, repos Repository) error) error
 }
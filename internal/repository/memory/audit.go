@@ -0,0 +1,79 @@
+package memory
+
+import (
+	"context"
+	"pr-review-service/internal/domain"
+	"time"
+)
+
+type AuditRepo struct {
+	db db
+}
+
+func NewAuditRepo(store *Store) *AuditRepo {
+	return &AuditRepo{db: store}
+}
+
+func (r *AuditRepo) Append(ctx context.Context, event *domain.AuditEvent) error {
+	return r.db.write(func(s *state) error {
+		s.nextAuditID++
+		event.ID = s.nextAuditID
+		event.CreatedAt = time.Now().UTC()
+		s.audit = append(s.audit, *event)
+		return nil
+	})
+}
+
+func (r *AuditRepo) AppendBatch(ctx context.Context, events []domain.AuditEvent) error {
+	return r.db.write(func(s *state) error {
+		createdAt := time.Now().UTC()
+		for _, event := range events {
+			s.nextAuditID++
+			event.ID = s.nextAuditID
+			event.CreatedAt = createdAt
+			s.audit = append(s.audit, event)
+		}
+		return nil
+	})
+}
+
+func (r *AuditRepo) RedactUsername(ctx context.Context, userID, replacement string) error {
+	return r.db.write(func(s *state) error {
+		for i := range s.audit {
+			event := &s.audit[i]
+			before, err := domain.RedactUsername(event.Before, userID, replacement)
+			if err != nil {
+				return err
+			}
+			after, err := domain.RedactUsername(event.After, userID, replacement)
+			if err != nil {
+				return err
+			}
+			event.Before, event.After = before, after
+		}
+		return nil
+	})
+}
+
+func (r *AuditRepo) List(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEvent, error) {
+	events := []domain.AuditEvent{}
+	err := r.db.read(func(s *state) error {
+		for i := len(s.audit) - 1; i >= 0 && len(events) < filter.Limit; i-- {
+			event := s.audit[i]
+			switch {
+			case filter.EntityType != "" && event.EntityType != filter.EntityType:
+			case filter.EntityID != "" && event.EntityID != filter.EntityID:
+			case filter.Actor != "" && event.Actor != filter.Actor:
+			case !inWindow(event.CreatedAt, filter.From, filter.To):
+			case filter.BeforeID > 0 && event.ID >= filter.BeforeID:
+			default:
+				events = append(events, event)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
@@ -0,0 +1,161 @@
+package memory
+
+import (
+	"context"
+	"pr-review-service/internal/domain"
+	"sort"
+	"time"
+)
+
+type AssignmentHistoryRepo struct {
+	db db
+}
+
+func NewAssignmentHistoryRepo(store *Store) *AssignmentHistoryRepo {
+	return &AssignmentHistoryRepo{db: store}
+}
+
+func (r *AssignmentHistoryRepo) RecordAssigned(ctx context.Context, prID, userID string, reason domain.AssignmentReason, actor string) error {
+	return r.db.write(func(s *state) error {
+		if openAssignment(s, prID, userID) != nil {
+			return nil
+		}
+
+		s.nextHistoryID++
+		s.history = append(s.history, historyRow{
+			id: s.nextHistoryID,
+			AssignmentRecord: domain.AssignmentRecord{
+				PullRequestID: prID,
+				UserID:        userID,
+				AssignedAt:    time.Now().UTC(),
+				AssignedBy:    actor,
+				AssignReason:  reason,
+			},
+		})
+		return nil
+	})
+}
+
+func (r *AssignmentHistoryRepo) RecordUnassigned(ctx context.Context, prID, userID string, reason domain.AssignmentReason, actor string) error {
+	return r.db.write(func(s *state) error {
+		row := openAssignment(s, prID, userID)
+		if row == nil {
+			return nil
+		}
+
+		now := time.Now().UTC()
+		row.UnassignedAt = &now
+		row.UnassignedBy = actor
+		row.UnassignReason = reason
+		return nil
+	})
+}
+
+func (r *AssignmentHistoryRepo) RecordReassignments(ctx context.Context, reassignments []domain.ReviewerReassignment, reason domain.AssignmentReason, actor string) error {
+	return r.db.write(func(s *state) error {
+		now := time.Now().UTC()
+		for _, ra := range reassignments {
+			if row := openAssignment(s, ra.PullRequestID, ra.OldReviewerID); row != nil {
+				unassignedAt := now
+				row.UnassignedAt = &unassignedAt
+				row.UnassignedBy = actor
+				row.UnassignReason = reason
+			}
+		}
+
+		for _, ra := range reassignments {
+			if ra.NewReviewerID == "" || openAssignment(s, ra.PullRequestID, ra.NewReviewerID) != nil {
+				continue
+			}
+			s.nextHistoryID++
+			s.history = append(s.history, historyRow{
+				id: s.nextHistoryID,
+				AssignmentRecord: domain.AssignmentRecord{
+					PullRequestID: ra.PullRequestID,
+					UserID:        ra.NewReviewerID,
+					AssignedAt:    now,
+					AssignedBy:    actor,
+					AssignReason:  reason,
+				},
+			})
+		}
+		return nil
+	})
+}
+
+// openAssignment returns the entry of userID on prID that is not closed yet.
+func openAssignment(s *state, prID, userID string) *historyRow {
+	for i := range s.history {
+		h := &s.history[i]
+		if h.PullRequestID == prID && h.UserID == userID && h.UnassignedAt == nil {
+			return h
+		}
+	}
+	return nil
+}
+
+func (r *AssignmentHistoryRepo) ListByPR(ctx context.Context, prID string) ([]domain.AssignmentRecord, error) {
+	var rows []historyRow
+	err := r.db.read(func(s *state) error {
+		for _, h := range s.history {
+			if h.PullRequestID == prID {
+				rows = append(rows, h)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sortHistory(rows)
+	records := []domain.AssignmentRecord{}
+	for _, h := range rows {
+		records = append(records, h.AssignmentRecord)
+	}
+	return records, nil
+}
+
+// Stream calls fn for every history entry of the PRs matching filter's row
+// filters, grouped by PR in assignment order.
+func (r *AssignmentHistoryRepo) Stream(ctx context.Context, filter domain.PRFilter, fn func(*domain.AssignmentRecord) error) error {
+	var rows []historyRow
+	err := r.db.read(func(s *state) error {
+		matches := make(map[string]bool)
+		for prID := range s.prs {
+			pr, _ := s.pullRequest(prID)
+			matches[prID] = matchesPRFilter(s, &pr, filter)
+		}
+		for _, h := range s.history {
+			if matches[h.PullRequestID] {
+				rows = append(rows, h)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	sortHistory(rows)
+	for i := range rows {
+		if err := fn(&rows[i].AssignmentRecord); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sortHistory orders entries by PR, then by assignment time and insertion.
+func sortHistory(rows []historyRow) {
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if a.PullRequestID != b.PullRequestID {
+			return a.PullRequestID < b.PullRequestID
+		}
+		if !a.AssignedAt.Equal(b.AssignedAt) {
+			return a.AssignedAt.Before(b.AssignedAt)
+		}
+		return a.id < b.id
+	})
+}
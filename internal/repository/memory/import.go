@@ -0,0 +1,103 @@
+package memory
+
+import (
+	"context"
+	"pr-review-service/internal/domain"
+	"time"
+)
+
+// ImportRepo only works inside a unit of work, like its Postgres counterpart.
+type ImportRepo struct {
+	db db
+}
+
+func (r *ImportRepo) ExistingTeams(ctx context.Context, teamNames []string) (map[string]bool, error) {
+	found := make(map[string]bool)
+	err := r.db.read(func(s *state) error {
+		for _, name := range teamNames {
+			if _, ok := s.teams[name]; ok {
+				found[name] = true
+			}
+		}
+		return nil
+	})
+	return found, err
+}
+
+func (r *ImportRepo) ExistingUsers(ctx context.Context, userIDs []string) (map[string]bool, error) {
+	found := make(map[string]bool)
+	err := r.db.read(func(s *state) error {
+		for _, id := range userIDs {
+			if _, ok := s.users[id]; ok {
+				found[id] = true
+			}
+		}
+		return nil
+	})
+	return found, err
+}
+
+func (r *ImportRepo) ExistingPullRequests(ctx context.Context, prIDs []string) (map[string]bool, error) {
+	found := make(map[string]bool)
+	err := r.db.read(func(s *state) error {
+		for _, id := range prIDs {
+			if _, ok := s.prs[id]; ok {
+				found[id] = true
+			}
+		}
+		return nil
+	})
+	return found, err
+}
+
+func (r *ImportRepo) Load(ctx context.Context, bundle *domain.ImportBundle, actor string) error {
+	return r.db.write(func(s *state) error {
+		now := time.Now().UTC()
+		for _, t := range bundle.Teams {
+			if _, exists := s.teams[t.TeamName]; exists {
+				return domain.ErrTeamExists
+			}
+			s.teams[t.TeamName] = teamRow{version: 1, createdAt: now}
+		}
+
+		for _, u := range bundle.Users {
+			if _, exists := s.users[u.UserID]; exists {
+				return domain.ErrUserExists
+			}
+			s.putUser(domain.User{UserID: u.UserID, Username: u.Username, TeamName: u.TeamName, IsActive: *u.IsActive}, now)
+		}
+
+		for _, pr := range bundle.PullRequests {
+			if _, exists := s.prs[pr.PullRequestID]; exists {
+				return domain.ErrPRExists
+			}
+			s.prs[pr.PullRequestID] = domain.PullRequest{
+				PullRequestID:   pr.PullRequestID,
+				PullRequestName: pr.PullRequestName,
+				AuthorID:        pr.AuthorID,
+				Status:          pr.Status,
+				CreatedAt:       pr.CreatedAt,
+				MergedAt:        pr.MergedAt,
+				Version:         1,
+			}
+		}
+
+		for _, a := range bundle.Assignments {
+			if err := s.addReviewer(a.PullRequestID, a.UserID, *a.AssignedAt); err != nil {
+				return err
+			}
+			s.nextHistoryID++
+			s.history = append(s.history, historyRow{
+				id: s.nextHistoryID,
+				AssignmentRecord: domain.AssignmentRecord{
+					PullRequestID: a.PullRequestID,
+					UserID:        a.UserID,
+					AssignedAt:    *a.AssignedAt,
+					AssignedBy:    actor,
+					AssignReason:  domain.AssignmentReasonInitial,
+				},
+			})
+		}
+		return nil
+	})
+}
@@ -0,0 +1,449 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"pr-review-service/internal/domain"
+	"sort"
+	"time"
+)
+
+type PullRequestRepo struct {
+	db db
+}
+
+func NewPullRequestRepo(store *Store) *PullRequestRepo {
+	return &PullRequestRepo{db: store}
+}
+
+func (r *PullRequestRepo) Create(ctx context.Context, pr *domain.PullRequest) error {
+	if pr.CreatedAt == nil {
+		now := time.Now()
+		pr.CreatedAt = &now
+	}
+
+	return r.db.write(func(s *state) error {
+		if _, exists := s.prs[pr.PullRequestID]; exists {
+			return domain.ErrPRExists
+		}
+		if _, ok := s.users[pr.AuthorID]; !ok {
+			return fmt.Errorf("%w: author %q does not exist", errForeignKey, pr.AuthorID)
+		}
+
+		// Reviewers are checked first so a failed Create leaves nothing behind.
+		for _, reviewerID := range pr.AssignedReviewers {
+			if _, ok := s.users[reviewerID]; !ok {
+				return fmt.Errorf("%w: reviewer %q does not exist", errForeignKey, reviewerID)
+			}
+		}
+
+		createdAt := pr.CreatedAt.UTC()
+		s.prs[pr.PullRequestID] = domain.PullRequest{
+			PullRequestID:   pr.PullRequestID,
+			PullRequestName: pr.PullRequestName,
+			AuthorID:        pr.AuthorID,
+			Status:          pr.Status,
+			Description:     pr.Description,
+			URL:             pr.URL,
+			Repository:      pr.Repository,
+			Labels:          append([]string{}, pr.Labels...),
+			Size:            pr.Size,
+			CreatedAt:       &createdAt,
+			Version:         1,
+		}
+
+		now := time.Now().UTC()
+		for _, reviewerID := range pr.AssignedReviewers {
+			if err := s.addReviewer(pr.PullRequestID, reviewerID, now); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *PullRequestRepo) Get(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	var pr domain.PullRequest
+	err := r.db.read(func(s *state) error {
+		var ok bool
+		if pr, ok = s.pullRequest(prID); !ok {
+			return domain.ErrPRNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pr, nil
+}
+
+// GetForUpdate needs no row lock: units of work already run one at a time.
+func (r *PullRequestRepo) GetForUpdate(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	return r.Get(ctx, prID)
+}
+
+func (r *PullRequestRepo) Update(ctx context.Context, pr *domain.PullRequest) error {
+	err := r.db.write(func(s *state) error {
+		stored, ok := s.prs[pr.PullRequestID]
+		if !ok {
+			return domain.ErrPRNotFound
+		}
+		if stored.Version != pr.Version {
+			return domain.ErrVersionMismatch
+		}
+
+		stored.PullRequestName = pr.PullRequestName
+		stored.AuthorID = pr.AuthorID
+		stored.Status = pr.Status
+		stored.Description = pr.Description
+		stored.URL = pr.URL
+		stored.Repository = pr.Repository
+		stored.Labels = append([]string{}, pr.Labels...)
+		stored.Size = pr.Size
+		stored.MergedAt = nil
+		if pr.MergedAt != nil {
+			mergedAt := pr.MergedAt.UTC()
+			stored.MergedAt = &mergedAt
+		}
+		stored.Version++
+		s.prs[pr.PullRequestID] = stored
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	pr.Version++
+	return nil
+}
+
+func (r *PullRequestRepo) Exists(ctx context.Context, prID string) (bool, error) {
+	var exists bool
+	err := r.db.read(func(s *state) error {
+		_, exists = s.prs[prID]
+		return nil
+	})
+	return exists, err
+}
+
+func (r *PullRequestRepo) GetByReviewer(ctx context.Context, userID string) ([]domain.PullRequestShort, error) {
+	var reviewed []domain.PullRequest
+	err := r.db.read(func(s *state) error {
+		for prID, rows := range s.reviewers {
+			for _, row := range rows {
+				if row.userID == userID {
+					reviewed = append(reviewed, s.prs[prID])
+					break
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(reviewed, func(i, j int) bool {
+		if !reviewed[i].CreatedAt.Equal(*reviewed[j].CreatedAt) {
+			return reviewed[i].CreatedAt.After(*reviewed[j].CreatedAt)
+		}
+		return reviewed[i].PullRequestID < reviewed[j].PullRequestID
+	})
+
+	prs := []domain.PullRequestShort{}
+	for _, pr := range reviewed {
+		prs = append(prs, domain.PullRequestShort{
+			PullRequestID:   pr.PullRequestID,
+			PullRequestName: pr.PullRequestName,
+			AuthorID:        pr.AuthorID,
+			Status:          pr.Status,
+		})
+	}
+	return prs, nil
+}
+
+func (r *PullRequestRepo) List(ctx context.Context, filter domain.PRFilter) ([]domain.PullRequest, error) {
+	prs := []domain.PullRequest{}
+	err := r.Stream(ctx, filter, func(pr *domain.PullRequest) error {
+		prs = append(prs, *pr)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return prs, nil
+}
+
+// Stream selects the PRs under the lock but calls fn after releasing it, so a
+// slow consumer does not hold up writers.
+func (r *PullRequestRepo) Stream(ctx context.Context, filter domain.PRFilter, fn func(*domain.PullRequest) error) error {
+	sortKey, ok := prSortKeys[filter.SortBy]
+	if !ok {
+		return domain.ErrInvalidSort
+	}
+
+	var after *prSortValue
+	if filter.After != nil {
+		value, err := sortKey.parse(filter.After.SortValue)
+		if err != nil {
+			return err
+		}
+		after = &value
+	}
+
+	var prs []domain.PullRequest
+	err := r.db.read(func(s *state) error {
+		for prID := range s.prs {
+			pr, _ := s.pullRequest(prID)
+			if !matchesPRFilter(s, &pr, filter) {
+				continue
+			}
+			if after != nil {
+				c := comparePRs(sortKey.value(&pr), pr.PullRequestID, *after, filter.After.PullRequestID)
+				if (!filter.Descending && c <= 0) || (filter.Descending && c >= 0) {
+					continue
+				}
+			}
+			prs = append(prs, pr)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(prs, func(i, j int) bool {
+		c := comparePRs(sortKey.value(&prs[i]), prs[i].PullRequestID, sortKey.value(&prs[j]), prs[j].PullRequestID)
+		if filter.Descending {
+			return c > 0
+		}
+		return c < 0
+	})
+	if filter.Limit > 0 && len(prs) > filter.Limit {
+		prs = prs[:filter.Limit]
+	}
+
+	for i := range prs {
+		if err := fn(&prs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// prSortValue is the value a PR is sorted by: a time for the timestamp
+// columns, where open PRs have no merged_at and sort as infinity, or text.
+type prSortValue struct {
+	time     time.Time
+	infinity bool
+	text     string
+}
+
+type prSortKey struct {
+	value func(pr *domain.PullRequest) prSortValue
+	// parse reads a page key in the text form the service renders it in.
+	parse func(s string) (prSortValue, error)
+}
+
+func parseTimeSortValue(s string) (prSortValue, error) {
+	if s == "infinity" {
+		return prSortValue{infinity: true}, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return prSortValue{}, domain.ErrInvalidCursor
+	}
+	return prSortValue{time: t}, nil
+}
+
+var prSortKeys = map[domain.PRSortField]prSortKey{
+	domain.PRSortCreatedAt: {
+		value: func(pr *domain.PullRequest) prSortValue { return prSortValue{time: *pr.CreatedAt} },
+		parse: parseTimeSortValue,
+	},
+	domain.PRSortMergedAt: {
+		value: func(pr *domain.PullRequest) prSortValue {
+			if pr.MergedAt == nil {
+				return prSortValue{infinity: true}
+			}
+			return prSortValue{time: *pr.MergedAt}
+		},
+		parse: parseTimeSortValue,
+	},
+	domain.PRSortName: {
+		value: func(pr *domain.PullRequest) prSortValue { return prSortValue{text: pr.PullRequestName} },
+		parse: func(s string) (prSortValue, error) { return prSortValue{text: s}, nil },
+	},
+}
+
+// comparePRs orders PRs by sort value with the PR ID as tie-breaker.
+func comparePRs(a prSortValue, aID string, b prSortValue, bID string) int {
+	switch {
+	case a.infinity != b.infinity:
+		if a.infinity {
+			return 1
+		}
+		return -1
+	case !a.time.Equal(b.time):
+		if a.time.Before(b.time) {
+			return -1
+		}
+		return 1
+	case a.text != b.text:
+		if a.text < b.text {
+			return -1
+		}
+		return 1
+	case aID != bID:
+		if aID < bID {
+			return -1
+		}
+		return 1
+	}
+	return 0
+}
+
+// matchesPRFilter applies the row filters of filter; sorting and paging are
+// left to the caller.
+func matchesPRFilter(s *state, pr *domain.PullRequest, filter domain.PRFilter) bool {
+	if filter.Status != "" && pr.Status != filter.Status {
+		return false
+	}
+	if filter.AuthorID != "" && pr.AuthorID != filter.AuthorID {
+		return false
+	}
+	if filter.ReviewerID != "" {
+		found := false
+		for _, row := range s.reviewers[pr.PullRequestID] {
+			found = found || row.userID == filter.ReviewerID
+		}
+		if !found {
+			return false
+		}
+	}
+	if filter.TeamName != "" && s.users[pr.AuthorID].TeamName != filter.TeamName {
+		return false
+	}
+	if !inWindow(*pr.CreatedAt, filter.CreatedFrom, filter.CreatedTo) {
+		return false
+	}
+	if filter.MergedFrom != nil || filter.MergedTo != nil {
+		if pr.MergedAt == nil || !inWindow(*pr.MergedAt, filter.MergedFrom, filter.MergedTo) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *PullRequestRepo) AssignReviewer(ctx context.Context, prID, userID string) error {
+	return r.db.write(func(s *state) error {
+		if _, ok := s.prs[prID]; !ok {
+			return fmt.Errorf("%w: pull request %q does not exist", errForeignKey, prID)
+		}
+		return s.addReviewer(prID, userID, time.Now().UTC())
+	})
+}
+
+func (r *PullRequestRepo) RemoveReviewer(ctx context.Context, prID, userID string) error {
+	return r.db.write(func(s *state) error {
+		s.removeReviewer(prID, userID)
+		return nil
+	})
+}
+
+func (r *PullRequestRepo) GetReviewers(ctx context.Context, prID string) ([]string, error) {
+	var reviewers []string
+	err := r.db.read(func(s *state) error {
+		reviewers = s.reviewerIDs(prID)
+		return nil
+	})
+	return reviewers, err
+}
+
+func (r *PullRequestRepo) IsReviewer(ctx context.Context, prID, userID string) (bool, error) {
+	var isReviewer bool
+	err := r.db.read(func(s *state) error {
+		for _, row := range s.reviewers[prID] {
+			isReviewer = isReviewer || row.userID == userID
+		}
+		return nil
+	})
+	return isReviewer, err
+}
+
+func (r *PullRequestRepo) GetOpenPRsByReviewers(ctx context.Context, userIDs []string) ([]domain.PullRequest, error) {
+	wanted := make(map[string]bool, len(userIDs))
+	for _, id := range userIDs {
+		wanted[id] = true
+	}
+
+	prs := []domain.PullRequest{}
+	err := r.db.read(func(s *state) error {
+		for prID, rows := range s.reviewers {
+			if s.prs[prID].Status != domain.PRStatusOpen {
+				continue
+			}
+			for _, row := range rows {
+				if wanted[row.userID] {
+					pr, _ := s.pullRequest(prID)
+					prs = append(prs, pr)
+					break
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(prs, func(i, j int) bool { return prs[i].PullRequestID < prs[j].PullRequestID })
+	return prs, nil
+}
+
+// GetOpenPRsByReviewersForUpdate needs no row locks, for the same reason as
+// GetForUpdate.
+func (r *PullRequestRepo) GetOpenPRsByReviewersForUpdate(ctx context.Context, userIDs []string) ([]domain.PullRequest, error) {
+	return r.GetOpenPRsByReviewers(ctx, userIDs)
+}
+
+func (r *PullRequestRepo) ReassignReviewersInBatch(ctx context.Context, oldUserID string, newAssignments map[string]string) error {
+	return r.db.write(func(s *state) error {
+		for _, newUserID := range newAssignments {
+			if _, ok := s.users[newUserID]; newUserID != "" && !ok {
+				return fmt.Errorf("%w: reviewer %q does not exist", errForeignKey, newUserID)
+			}
+		}
+
+		now := time.Now().UTC()
+		for prID, newUserID := range newAssignments {
+			s.removeReviewer(prID, oldUserID)
+			if newUserID != "" {
+				if err := s.addReviewer(prID, newUserID, now); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func (r *PullRequestRepo) BumpVersions(ctx context.Context, prIDs []string) (map[string]int, error) {
+	versions := make(map[string]int, len(prIDs))
+	err := r.db.write(func(s *state) error {
+		for _, prID := range prIDs {
+			stored, ok := s.prs[prID]
+			if !ok {
+				continue
+			}
+			stored.Version++
+			s.prs[prID] = stored
+			versions[prID] = stored.Version
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
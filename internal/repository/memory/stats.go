@@ -0,0 +1,165 @@
+package memory
+
+import (
+	"context"
+	"pr-review-service/internal/domain"
+	"sort"
+	"time"
+)
+
+type StatsRepo struct {
+	db db
+}
+
+func NewStatsRepo(store *Store) *StatsRepo {
+	return &StatsRepo{db: store}
+}
+
+func (r *StatsRepo) GetReviewMetrics(ctx context.Context, filter domain.MetricsFilter) (*domain.ReviewMetrics, error) {
+	metrics := &domain.ReviewMetrics{
+		TeamName:   filter.TeamName,
+		UserID:     filter.UserID,
+		From:       filter.From,
+		To:         filter.To,
+		Throughput: []domain.WeeklyThroughput{},
+	}
+	from, to := filter.From, filter.To
+
+	// PR metrics follow the author, review metrics follow the reviewer.
+	inScope := func(s *state, userID string) bool {
+		return (filter.TeamName == "" || s.users[userID].TeamName == filter.TeamName) &&
+			(filter.UserID == "" || userID == filter.UserID)
+	}
+
+	weeks := make(map[time.Time]*domain.WeeklyThroughput)
+	for week := startOfWeek(from); week.Before(to); week = week.AddDate(0, 0, 7) {
+		metrics.Throughput = append(metrics.Throughput, domain.WeeklyThroughput{WeekStart: week})
+	}
+	for i := range metrics.Throughput {
+		weeks[metrics.Throughput[i].WeekStart] = &metrics.Throughput[i]
+	}
+
+	err := r.db.read(func(s *state) error {
+		firstAssigned := make(map[string]time.Time)
+		for _, h := range s.history {
+			if first, ok := firstAssigned[h.PullRequestID]; !ok || h.AssignedAt.Before(first) {
+				firstAssigned[h.PullRequestID] = h.AssignedAt
+			}
+		}
+
+		var toMerge, toFirstReview []float64
+		for _, pr := range s.prs {
+			if !inScope(s, pr.AuthorID) {
+				continue
+			}
+
+			if inWindow(*pr.CreatedAt, &from, &to) {
+				if week, ok := weeks[startOfWeek(*pr.CreatedAt)]; ok {
+					week.Opened++
+				}
+				if first, ok := firstAssigned[pr.PullRequestID]; ok {
+					toFirstReview = append(toFirstReview, first.Sub(*pr.CreatedAt).Seconds())
+				}
+			}
+			if pr.MergedAt != nil && inWindow(*pr.MergedAt, &from, &to) {
+				if week, ok := weeks[startOfWeek(*pr.MergedAt)]; ok {
+					week.Merged++
+				}
+				toMerge = append(toMerge, pr.MergedAt.Sub(*pr.CreatedAt).Seconds())
+			}
+		}
+		metrics.TimeToMerge = domain.NewDurationStats(toMerge)
+		metrics.TimeToFirstReview = domain.NewDurationStats(toFirstReview)
+
+		for _, h := range s.history {
+			pr := s.prs[h.PullRequestID]
+			if h.UnassignedAt == nil && pr.MergedAt != nil && inWindow(*pr.MergedAt, &from, &to) && inScope(s, h.UserID) {
+				metrics.ReviewsCompleted++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return metrics, nil
+}
+
+// startOfWeek truncates t to Monday midnight UTC, like date_trunc('week', t)
+// on the UTC timestamps the database stores.
+func startOfWeek(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+// GetMemberLoad returns the members in team and username order. Active days
+// are the time each member spent active within the window.
+func (r *StatsRepo) GetMemberLoad(ctx context.Context, filter domain.FairnessFilter) ([]domain.MemberLoad, error) {
+	loads := []domain.MemberLoad{}
+	err := r.db.read(func(s *state) error {
+		var members []domain.User
+		for _, user := range s.users {
+			if user.DeletedAt == nil && (filter.TeamName == "" || user.TeamName == filter.TeamName) {
+				members = append(members, user)
+			}
+		}
+		sort.Slice(members, func(i, j int) bool {
+			if members[i].TeamName != members[j].TeamName {
+				return members[i].TeamName < members[j].TeamName
+			}
+			return members[i].Username < members[j].Username ||
+				members[i].Username == members[j].Username && members[i].UserID < members[j].UserID
+		})
+
+		assignments := make(map[string]int)
+		for _, rows := range s.reviewers {
+			for _, row := range rows {
+				if inWindow(row.assignedAt, &filter.From, &filter.To) {
+					assignments[row.userID]++
+				}
+			}
+		}
+
+		// Activations are appended in time order, so each one lasts until the
+		// next one of the same user.
+		activeSeconds := make(map[string]float64)
+		last := make(map[string]activationRow)
+		closeInterval := func(a activationRow, ends time.Time) {
+			starts := a.changedAt
+			if starts.Before(filter.From) {
+				starts = filter.From
+			}
+			if ends.After(filter.To) {
+				ends = filter.To
+			}
+			if a.isActive && ends.After(starts) {
+				activeSeconds[a.userID] += ends.Sub(starts).Seconds()
+			}
+		}
+		for _, a := range s.activations {
+			if prev, ok := last[a.userID]; ok {
+				closeInterval(prev, a.changedAt)
+			}
+			last[a.userID] = a
+		}
+		for _, a := range last {
+			closeInterval(a, filter.To)
+		}
+
+		for _, m := range members {
+			loads = append(loads, domain.MemberLoad{
+				TeamName:    m.TeamName,
+				UserID:      m.UserID,
+				Username:    m.Username,
+				Assignments: assignments[m.UserID],
+				ActiveDays:  activeSeconds[m.UserID] / 86400,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return loads, nil
+}
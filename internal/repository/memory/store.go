@@ -0,0 +1,229 @@
+// Package memory implements the repositories on top of in-process maps. It is
+// meant for tests and demo instances: data lives only as long as the Store.
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pr-review-service/internal/domain"
+	"pr-review-service/internal/repository"
+	"sync"
+	"time"
+)
+
+// errForeignKey mirrors the database rejecting a reference to a missing row.
+var errForeignKey = errors.New("foreign key violation")
+
+// Store holds the data shared by all repositories created from it. A single
+// lock guards it: plain repository calls hold it for one call, a unit of work
+// for the whole transaction, which also gives transactions the isolation the
+// Postgres repositories get from row locks.
+type Store struct {
+	mu    sync.RWMutex
+	state *state
+}
+
+func NewStore() *Store {
+	return &Store{state: newState()}
+}
+
+type teamRow struct {
+	version   int
+	createdAt time.Time
+}
+
+type reviewerRow struct {
+	userID     string
+	assignedAt time.Time
+}
+
+func (r reviewerRow) before(other reviewerRow) bool {
+	if !r.assignedAt.Equal(other.assignedAt) {
+		return r.assignedAt.Before(other.assignedAt)
+	}
+	return r.userID < other.userID
+}
+
+type historyRow struct {
+	id int64
+	domain.AssignmentRecord
+}
+
+type activationRow struct {
+	userID    string
+	isActive  bool
+	changedAt time.Time
+}
+
+// state is one consistent version of the data. PRs are stored without their
+// reviewers, which live in reviewers in assignment order.
+type state struct {
+	teams       map[string]teamRow
+	users       map[string]domain.User
+	prs         map[string]domain.PullRequest
+	reviewers   map[string][]reviewerRow
+	history     []historyRow
+	activations []activationRow
+	audit       []domain.AuditEvent
+
+	nextHistoryID int64
+	nextAuditID   int64
+}
+
+func newState() *state {
+	return &state{
+		teams:     make(map[string]teamRow),
+		users:     make(map[string]domain.User),
+		prs:       make(map[string]domain.PullRequest),
+		reviewers: make(map[string][]reviewerRow),
+	}
+}
+
+// clone copies everything a transaction may modify. Pointers and slices
+// inside stored values are never written through, so they can be shared.
+func (s *state) clone() *state {
+	c := &state{
+		teams:         make(map[string]teamRow, len(s.teams)),
+		users:         make(map[string]domain.User, len(s.users)),
+		prs:           make(map[string]domain.PullRequest, len(s.prs)),
+		reviewers:     make(map[string][]reviewerRow, len(s.reviewers)),
+		history:       append([]historyRow(nil), s.history...),
+		activations:   append([]activationRow(nil), s.activations...),
+		audit:         append([]domain.AuditEvent(nil), s.audit...),
+		nextHistoryID: s.nextHistoryID,
+		nextAuditID:   s.nextAuditID,
+	}
+	for k, v := range s.teams {
+		c.teams[k] = v
+	}
+	for k, v := range s.users {
+		c.users[k] = v
+	}
+	for k, v := range s.prs {
+		c.prs[k] = v
+	}
+	for k, v := range s.reviewers {
+		c.reviewers[k] = append([]reviewerRow(nil), v...)
+	}
+	return c
+}
+
+// putUser stores user and, like the activation history trigger, records new
+// users and every change of is_active.
+func (s *state) putUser(user domain.User, now time.Time) {
+	old, exists := s.users[user.UserID]
+	s.users[user.UserID] = user
+	if !exists || old.IsActive != user.IsActive {
+		s.activations = append(s.activations, activationRow{userID: user.UserID, isActive: user.IsActive, changedAt: now})
+	}
+}
+
+func (s *state) addReviewer(prID, userID string, assignedAt time.Time) error {
+	if _, ok := s.users[userID]; !ok {
+		return fmt.Errorf("%w: reviewer %q does not exist", errForeignKey, userID)
+	}
+	for _, r := range s.reviewers[prID] {
+		if r.userID == userID {
+			return nil
+		}
+	}
+
+	// Keep reviewers in assignment order, ties by user ID; imports may add
+	// past assignments.
+	rows := append(s.reviewers[prID], reviewerRow{userID: userID, assignedAt: assignedAt})
+	for i := len(rows) - 1; i > 0 && rows[i].before(rows[i-1]); i-- {
+		rows[i], rows[i-1] = rows[i-1], rows[i]
+	}
+	s.reviewers[prID] = rows
+	return nil
+}
+
+func (s *state) removeReviewer(prID, userID string) {
+	rows := s.reviewers[prID]
+	for i, r := range rows {
+		if r.userID == userID {
+			s.reviewers[prID] = append(rows[:i:i], rows[i+1:]...)
+			return
+		}
+	}
+}
+
+func (s *state) reviewerIDs(prID string) []string {
+	ids := []string{}
+	for _, r := range s.reviewers[prID] {
+		ids = append(ids, r.userID)
+	}
+	return ids
+}
+
+// pullRequest returns a copy of the stored PR with its reviewers.
+func (s *state) pullRequest(prID string) (domain.PullRequest, bool) {
+	pr, ok := s.prs[prID]
+	if !ok {
+		return domain.PullRequest{}, false
+	}
+	pr.AssignedReviewers = s.reviewerIDs(prID)
+	pr.Labels = append([]string{}, pr.Labels...)
+	return pr, true
+}
+
+// db gives repositories access to the state. The Store locks around every
+// call; a transaction already holds the lock and passes its own state.
+type db interface {
+	read(fn func(s *state) error) error
+	write(fn func(s *state) error) error
+}
+
+func (st *Store) read(fn func(s *state) error) error {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	return fn(st.state)
+}
+
+func (st *Store) write(fn func(s *state) error) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return fn(st.state)
+}
+
+type txDB struct {
+	state *state
+}
+
+func (tx txDB) read(fn func(s *state) error) error  { return fn(tx.state) }
+func (tx txDB) write(fn func(s *state) error) error { return fn(tx.state) }
+
+type UnitOfWork struct {
+	store *Store
+}
+
+func NewUnitOfWork(store *Store) *UnitOfWork {
+	return &UnitOfWork{store: store}
+}
+
+// WithinTx runs fn against a copy of the data, which replaces the stored data
+// only if fn succeeds. Transactions run one at a time.
+func (u *UnitOfWork) WithinTx(ctx context.Context, fn func(ctx context.Context, repos repository.Repository) error) error {
+	u.store.mu.Lock()
+	defer u.store.mu.Unlock()
+
+	tx := txDB{state: u.store.state.clone()}
+	repos := repository.Repository{
+		Team:        &TeamRepo{db: tx},
+		User:        &UserRepo{db: tx},
+		PullRequest: &PullRequestRepo{db: tx},
+		Audit:       &AuditRepo{db: tx},
+		History:     &AssignmentHistoryRepo{db: tx},
+		Import:      &ImportRepo{db: tx},
+	}
+	if err := fn(ctx, repos); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	u.store.state = tx.state
+	return nil
+}
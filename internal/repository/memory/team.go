@@ -0,0 +1,135 @@
+package memory
+
+import (
+	"context"
+	"pr-review-service/internal/domain"
+	"sort"
+	"time"
+)
+
+type TeamRepo struct {
+	db db
+}
+
+func NewTeamRepo(store *Store) *TeamRepo {
+	return &TeamRepo{db: store}
+}
+
+func (r *TeamRepo) Create(ctx context.Context, team *domain.Team) error {
+	return r.db.write(func(s *state) error {
+		if _, exists := s.teams[team.TeamName]; exists {
+			return domain.ErrTeamExists
+		}
+		s.teams[team.TeamName] = teamRow{version: 1, createdAt: time.Now().UTC()}
+		return nil
+	})
+}
+
+func (r *TeamRepo) Get(ctx context.Context, teamName string) (*domain.Team, error) {
+	var team *domain.Team
+	err := r.db.read(func(s *state) error {
+		row, ok := s.teams[teamName]
+		if !ok {
+			return domain.ErrTeamNotFound
+		}
+
+		team = &domain.Team{
+			TeamName: teamName,
+			Members:  []domain.TeamMember{},
+			Version:  row.version,
+		}
+		for _, user := range teamMembers(s, teamName, false) {
+			team.Members = append(team.Members, domain.TeamMember{
+				UserID:   user.UserID,
+				Username: user.Username,
+				IsActive: user.IsActive,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return team, nil
+}
+
+func (r *TeamRepo) Exists(ctx context.Context, teamName string) (bool, error) {
+	var exists bool
+	err := r.db.read(func(s *state) error {
+		_, exists = s.teams[teamName]
+		return nil
+	})
+	return exists, err
+}
+
+func (r *TeamRepo) DeactivateAll(ctx context.Context, teamName string) error {
+	return r.db.write(func(s *state) error {
+		now := time.Now().UTC()
+		for _, user := range s.users {
+			if user.TeamName == teamName {
+				user.IsActive = false
+				s.putUser(user, now)
+			}
+		}
+		return nil
+	})
+}
+
+func (r *TeamRepo) ActivateAll(ctx context.Context, teamName string, userIDs []string) error {
+	listed := make(map[string]bool, len(userIDs))
+	for _, id := range userIDs {
+		listed[id] = true
+	}
+
+	return r.db.write(func(s *state) error {
+		now := time.Now().UTC()
+		for _, user := range s.users {
+			if user.TeamName == teamName && user.DeletedAt == nil && (len(listed) == 0 || listed[user.UserID]) {
+				user.IsActive = true
+				s.putUser(user, now)
+			}
+		}
+		return nil
+	})
+}
+
+func (r *TeamRepo) BumpVersion(ctx context.Context, teamName string, expectedVersion int) (int, error) {
+	var version int
+	err := r.db.write(func(s *state) error {
+		row, ok := s.teams[teamName]
+		if !ok {
+			return domain.ErrTeamNotFound
+		}
+		if expectedVersion != 0 && row.version != expectedVersion {
+			return domain.ErrVersionMismatch
+		}
+
+		row.version++
+		s.teams[teamName] = row
+		version = row.version
+		return nil
+	})
+	return version, err
+}
+
+// teamMembers returns the members of teamName that are not deleted, ordered
+// by username and ID.
+func teamMembers(s *state, teamName string, activeOnly bool) []domain.User {
+	users := []domain.User{}
+	for _, user := range s.users {
+		if user.TeamName == teamName && user.DeletedAt == nil && (user.IsActive || !activeOnly) {
+			users = append(users, user)
+		}
+	}
+	sortUsers(users)
+	return users
+}
+
+func sortUsers(users []domain.User) {
+	sort.Slice(users, func(i, j int) bool {
+		if users[i].Username != users[j].Username {
+			return users[i].Username < users[j].Username
+		}
+		return users[i].UserID < users[j].UserID
+	})
+}
@@ -0,0 +1,258 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"pr-review-service/internal/domain"
+	"sort"
+	"strings"
+	"time"
+)
+
+type UserRepo struct {
+	db db
+}
+
+func NewUserRepo(store *Store) *UserRepo {
+	return &UserRepo{db: store}
+}
+
+// Create inserts the user or, like the Postgres upsert, overwrites the name,
+// team and active flag of an existing one.
+func (r *UserRepo) Create(ctx context.Context, user *domain.User) error {
+	return r.db.write(func(s *state) error {
+		if _, ok := s.teams[user.TeamName]; !ok {
+			return fmt.Errorf("%w: team %q does not exist", errForeignKey, user.TeamName)
+		}
+
+		stored, exists := s.users[user.UserID]
+		if !exists {
+			stored = domain.User{UserID: user.UserID}
+		}
+		stored.Username = user.Username
+		stored.TeamName = user.TeamName
+		stored.IsActive = user.IsActive
+		s.putUser(stored, time.Now().UTC())
+		return nil
+	})
+}
+
+func (r *UserRepo) Update(ctx context.Context, user *domain.User) error {
+	return r.db.write(func(s *state) error {
+		if _, exists := s.users[user.UserID]; !exists {
+			return nil
+		}
+		s.putUser(*user, time.Now().UTC())
+		return nil
+	})
+}
+
+func (r *UserRepo) Get(ctx context.Context, userID string) (*domain.User, error) {
+	var user domain.User
+	err := r.db.read(func(s *state) error {
+		var ok bool
+		if user, ok = s.users[userID]; !ok {
+			return domain.ErrUserNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *UserRepo) GetByTeam(ctx context.Context, teamName string) ([]domain.User, error) {
+	var users []domain.User
+	err := r.db.read(func(s *state) error {
+		users = teamMembers(s, teamName, false)
+		return nil
+	})
+	return users, err
+}
+
+func (r *UserRepo) GetActiveByTeam(ctx context.Context, teamName string) ([]domain.User, error) {
+	var users []domain.User
+	err := r.db.read(func(s *state) error {
+		users = teamMembers(s, teamName, true)
+		return nil
+	})
+	return users, err
+}
+
+func (r *UserRepo) GetByIDs(ctx context.Context, userIDs []string) (map[string]domain.User, error) {
+	users := make(map[string]domain.User, len(userIDs))
+	err := r.db.read(func(s *state) error {
+		for _, id := range userIDs {
+			if user, ok := s.users[id]; ok {
+				users[id] = user
+			}
+		}
+		return nil
+	})
+	return users, err
+}
+
+func (r *UserRepo) GetActiveByTeams(ctx context.Context, teamNames []string) (map[string][]domain.User, error) {
+	users := make(map[string][]domain.User, len(teamNames))
+	err := r.db.read(func(s *state) error {
+		for _, teamName := range teamNames {
+			if members := teamMembers(s, teamName, true); len(members) > 0 {
+				users[teamName] = members
+			}
+		}
+		return nil
+	})
+	return users, err
+}
+
+func (r *UserRepo) SetIsActive(ctx context.Context, userID string, isActive bool) (*domain.User, error) {
+	var user domain.User
+	err := r.db.write(func(s *state) error {
+		var ok bool
+		if user, ok = s.users[userID]; !ok {
+			return domain.ErrUserNotFound
+		}
+		user.IsActive = isActive
+		s.putUser(user, time.Now().UTC())
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *UserRepo) GetStats(ctx context.Context, filter domain.UserStatsFilter) ([]domain.UserStats, error) {
+	stats := []domain.UserStats{}
+	err := r.StreamStats(ctx, filter, func(stat *domain.UserStats) error {
+		stats = append(stats, *stat)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// StreamStats computes the stats under the lock but calls fn after releasing
+// it, so a slow consumer does not hold up writers.
+func (r *UserRepo) StreamStats(ctx context.Context, filter domain.UserStatsFilter, fn func(*domain.UserStats) error) error {
+	var stats []domain.UserStats
+	err := r.db.read(func(s *state) error {
+		counts := make(map[string]int)
+		for _, rows := range s.reviewers {
+			for _, row := range rows {
+				if inWindow(row.assignedAt, filter.From, filter.To) {
+					counts[row.userID]++
+				}
+			}
+		}
+
+		for _, user := range s.users {
+			if user.DeletedAt == nil {
+				stats = append(stats, domain.UserStats{UserID: user.UserID, Username: user.Username, ReviewCount: counts[user.UserID]})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].ReviewCount != stats[j].ReviewCount {
+			return stats[i].ReviewCount > stats[j].ReviewCount
+		}
+		if stats[i].Username != stats[j].Username {
+			return stats[i].Username < stats[j].Username
+		}
+		return stats[i].UserID < stats[j].UserID
+	})
+	if filter.Limit > 0 && len(stats) > filter.Limit {
+		stats = stats[:filter.Limit]
+	}
+
+	for i := range stats {
+		if err := fn(&stats[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *UserRepo) List(ctx context.Context, filter domain.UserFilter) ([]domain.User, error) {
+	users := []domain.User{}
+	err := r.db.read(func(s *state) error {
+		for _, user := range s.users {
+			switch {
+			case user.DeletedAt != nil:
+			case filter.TeamName != "" && user.TeamName != filter.TeamName:
+			case filter.IsActive != nil && user.IsActive != *filter.IsActive:
+			case !strings.HasPrefix(user.Username, filter.NamePrefix):
+			case filter.After != nil && (user.Username < filter.After.Username ||
+				user.Username == filter.After.Username && user.UserID <= filter.After.UserID):
+			default:
+				users = append(users, user)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sortUsers(users)
+	if len(users) > filter.Limit {
+		users = users[:filter.Limit]
+	}
+	return users, nil
+}
+
+func (r *UserRepo) GetActivity(ctx context.Context, userIDs []string) (map[string]domain.UserActivity, error) {
+	activity := make(map[string]domain.UserActivity, len(userIDs))
+	err := r.db.read(func(s *state) error {
+		for _, id := range userIDs {
+			activity[id] = domain.UserActivity{}
+		}
+
+		for prID, rows := range s.reviewers {
+			if s.prs[prID].Status != domain.PRStatusOpen {
+				continue
+			}
+			for _, row := range rows {
+				if a, ok := activity[row.userID]; ok {
+					a.OpenReviewCount++
+					activity[row.userID] = a
+				}
+			}
+		}
+
+		for _, pr := range s.prs {
+			if a, ok := activity[pr.AuthorID]; ok && pr.Status == domain.PRStatusOpen {
+				a.AuthoredOpenPRCount++
+				activity[pr.AuthorID] = a
+			}
+		}
+
+		for _, h := range s.history {
+			a, ok := activity[h.UserID]
+			if !ok || (a.LastAssignedAt != nil && !h.AssignedAt.After(*a.LastAssignedAt)) {
+				continue
+			}
+			assignedAt := h.AssignedAt
+			a.LastAssignedAt = &assignedAt
+			activity[h.UserID] = a
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return activity, nil
+}
+
+// inWindow reports whether t lies in [from, to), where a nil bound is open.
+func inWindow(t time.Time, from, to *time.Time) bool {
+	return (from == nil || !t.Before(*from)) && (to == nil || t.Before(*to))
+}
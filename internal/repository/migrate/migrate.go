@@ -0,0 +1,316 @@
+// Package migrate applies versioned SQL migrations. It knows how migrations
+// are laid out and tracked; each database backend supplies a Driver that runs
+// them.
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Migrations are NN_name.up.sql / NN_name.down.sql pairs. Schema migrations
+// sit at the top of the migrations directory, demo data lives under seed/ and
+// is only applied on request. Every applied migration is recorded in
+// schema_migrations with a checksum of its up script, so a script edited
+// after it ran is reported instead of silently diverging from the database.
+
+type Kind string
+
+const (
+	KindSchema Kind = "schema"
+	KindSeed   Kind = "seed"
+
+	seedDir = "seed"
+)
+
+var (
+	ErrMigrationModified = errors.New("migration changed after it was applied")
+	ErrNoDownMigration   = errors.New("migration has no down script")
+
+	migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+)
+
+type Migration struct {
+	Kind     Kind
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+func (m *Migration) String() string {
+	return fmt.Sprintf("%s %02d_%s", m.Kind, m.Version, m.Name)
+}
+
+type Status struct {
+	Kind      Kind
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+	// Modified is set when the up script changed after it was applied.
+	Modified bool
+	// Missing is set for applied migrations the source no longer has, e.g.
+	// when an older binary runs against a newer database.
+	Missing bool
+}
+
+// Applied is a row of schema_migrations.
+type Applied struct {
+	Kind      Kind
+	Version   int
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// Driver runs migrations against one database.
+type Driver interface {
+	// WithLock runs fn while no other migrator works on the same database.
+	WithLock(ctx context.Context, fn func() error) error
+	// Applied returns the applied migrations in the order they were applied,
+	// creating the tracking table on first use.
+	Applied(ctx context.Context) ([]Applied, error)
+	// Apply runs the up script and records it in the same transaction.
+	Apply(ctx context.Context, m *Migration) error
+	// Revert runs the down script and forgets the migration in the same
+	// transaction.
+	Revert(ctx context.Context, m *Migration) error
+}
+
+type key struct {
+	kind    Kind
+	version int
+}
+
+type Migrator struct {
+	driver     Driver
+	migrations []*Migration
+}
+
+// New loads the migrations found in fsys.
+func New(driver Driver, fsys fs.FS) (*Migrator, error) {
+	schema, err := load(fsys, ".", KindSchema)
+	if err != nil {
+		return nil, err
+	}
+	seeds, err := load(fsys, seedDir, KindSeed)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{driver: driver, migrations: append(schema, seeds...)}, nil
+}
+
+func load(fsys fs.FS, dir string, kind Kind) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if kind == KindSeed && errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		filename := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(filename, ".sql") {
+			continue
+		}
+
+		match := migrationFileName.FindStringSubmatch(filename)
+		if match == nil {
+			return nil, fmt.Errorf("migration file %s must be named NN_name.up.sql or NN_name.down.sql", filename)
+		}
+		version, err := strconv.Atoi(match[1])
+		if err != nil {
+			return nil, fmt.Errorf("migration file %s: %w", filename, err)
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, filename))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration file %s: %w", filename, err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Kind: kind, Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("%s migrations %s and %s share version %d", kind, m.Name, match[2], version)
+		}
+
+		if match[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %s has no up script", m)
+		}
+		sum := sha256.Sum256([]byte(m.Up))
+		m.Checksum = hex.EncodeToString(sum[:])
+		migrations = append(migrations, m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Up applies every pending schema migration and, with seeds set, every
+// pending seed migration. It refuses to run if an applied migration has been
+// edited since. The applied migrations are returned even on error. Migrators
+// that start together wait for the one holding the migration lock and then
+// find nothing left to apply.
+func (m *Migrator) Up(ctx context.Context, seeds bool) (ran []*Migration, err error) {
+	err = m.driver.WithLock(ctx, func() error {
+		ran, err = m.up(ctx, seeds)
+		return err
+	})
+	return ran, err
+}
+
+func (m *Migrator) up(ctx context.Context, seeds bool) ([]*Migration, error) {
+	applied, err := m.driver.Applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	done := make(map[key]bool, len(applied))
+	for _, a := range applied {
+		done[key{a.Kind, a.Version}] = true
+		if mig := m.find(a.Kind, a.Version); mig != nil && mig.Checksum != a.Checksum {
+			return nil, fmt.Errorf("%s: %w", mig, ErrMigrationModified)
+		}
+	}
+
+	var ran []*Migration
+	for _, mig := range m.migrations {
+		if done[key{mig.Kind, mig.Version}] || (mig.Kind == KindSeed && !seeds) {
+			continue
+		}
+		if err := m.driver.Apply(ctx, mig); err != nil {
+			return ran, err
+		}
+		ran = append(ran, mig)
+	}
+
+	return ran, nil
+}
+
+// Down rolls back the last steps applied migrations, newest first.
+func (m *Migrator) Down(ctx context.Context, steps int) (rolledBack []*Migration, err error) {
+	err = m.driver.WithLock(ctx, func() error {
+		rolledBack, err = m.down(ctx, steps)
+		return err
+	})
+	return rolledBack, err
+}
+
+func (m *Migrator) down(ctx context.Context, steps int) ([]*Migration, error) {
+	applied, err := m.driver.Applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var rolledBack []*Migration
+	for i := len(applied) - 1; i >= 0 && len(rolledBack) < steps; i-- {
+		mig := m.find(applied[i].Kind, applied[i].Version)
+		if mig == nil {
+			return rolledBack, fmt.Errorf("cannot roll back %s %02d_%s: migration not found",
+				applied[i].Kind, applied[i].Version, applied[i].Name)
+		}
+		if mig.Down == "" {
+			return rolledBack, fmt.Errorf("%s: %w", mig, ErrNoDownMigration)
+		}
+		if err := m.driver.Revert(ctx, mig); err != nil {
+			return rolledBack, err
+		}
+		rolledBack = append(rolledBack, mig)
+	}
+
+	return rolledBack, nil
+}
+
+// Redo rolls back the last applied migration and applies it again from its
+// current script.
+func (m *Migrator) Redo(ctx context.Context) (mig *Migration, err error) {
+	err = m.driver.WithLock(ctx, func() error {
+		rolledBack, err := m.down(ctx, 1)
+		if err != nil || len(rolledBack) == 0 {
+			return err
+		}
+
+		mig = rolledBack[0]
+		return m.driver.Apply(ctx, mig)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mig, nil
+}
+
+// Status lists known migrations in apply order followed by applied ones the
+// source no longer has.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.driver.Applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byKey := make(map[key]Applied, len(applied))
+	for _, a := range applied {
+		byKey[key{a.Kind, a.Version}] = a
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		status := Status{Kind: mig.Kind, Version: mig.Version, Name: mig.Name}
+		if a, ok := byKey[key{mig.Kind, mig.Version}]; ok {
+			appliedAt := a.AppliedAt
+			status.AppliedAt = &appliedAt
+			status.Modified = a.Checksum != mig.Checksum
+		}
+		statuses = append(statuses, status)
+	}
+
+	for _, a := range applied {
+		if m.find(a.Kind, a.Version) == nil {
+			appliedAt := a.AppliedAt
+			statuses = append(statuses, Status{
+				Kind:      a.Kind,
+				Version:   a.Version,
+				Name:      a.Name,
+				AppliedAt: &appliedAt,
+				Missing:   true,
+			})
+		}
+	}
+
+	return statuses, nil
+}
+
+func (m *Migrator) find(kind Kind, version int) *Migration {
+	for _, mig := range m.migrations {
+		if mig.Kind == kind && mig.Version == version {
+			return mig
+		}
+	}
+	return nil
+}
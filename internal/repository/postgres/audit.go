@@ -0,0 +1,116 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"pr-review-service/internal/domain"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AuditRepo struct {
+	db DBTX
+}
+
+func NewAuditRepo(db *pgxpool.Pool) *AuditRepo {
+	return &AuditRepo{db: db}
+}
+
+func (r *AuditRepo) Append(ctx context.Context, event *domain.AuditEvent) error {
+	return r.db.QueryRow(ctx, `
+		INSERT INTO audit_events (actor, action, entity_type, entity_id, before, after)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at`,
+		event.Actor, event.Action, event.EntityType, event.EntityID, []byte(event.Before), []byte(event.After)).
+		Scan(&event.ID, &event.CreatedAt)
+}
+
+func (r *AuditRepo) AppendBatch(ctx context.Context, events []domain.AuditEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	batch, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(ctx, `
+		INSERT INTO audit_events (actor, action, entity_type, entity_id, before, after)
+		SELECT e->>'actor', e->>'action', e->>'entity_type', e->>'entity_id', e->'before', e->'after'
+		FROM jsonb_array_elements($1::jsonb) WITH ORDINALITY AS batch (e, n)
+		ORDER BY n`, string(batch))
+	return err
+}
+
+// RedactUsername must run inside a transaction: the redaction switch it flips
+// is transaction-local.
+func (r *AuditRepo) RedactUsername(ctx context.Context, userID, replacement string) error {
+	if _, err := r.db.Exec(ctx, `SELECT set_config('app.audit_redaction', 'on', true)`); err != nil {
+		return err
+	}
+
+	_, err := r.db.Exec(ctx, `
+		UPDATE audit_events
+		SET before = redact_username(before, $1, $2),
+		    after = redact_username(after, $1, $2)
+		WHERE jsonb_path_exists(before, '$.** ? (@.user_id == $uid)', jsonb_build_object('uid', $1::text))
+		   OR jsonb_path_exists(after, '$.** ? (@.user_id == $uid)', jsonb_build_object('uid', $1::text))`,
+		userID, replacement)
+	return err
+}
+
+func (r *AuditRepo) List(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEvent, error) {
+	var conditions []string
+	var args []any
+	addCondition := func(format string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(format, len(args)))
+	}
+
+	if filter.EntityType != "" {
+		addCondition("entity_type = $%d", filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		addCondition("entity_id = $%d", filter.EntityID)
+	}
+	if filter.Actor != "" {
+		addCondition("actor = $%d", filter.Actor)
+	}
+	if filter.From != nil {
+		addCondition("created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		addCondition("created_at < $%d", *filter.To)
+	}
+	if filter.BeforeID > 0 {
+		addCondition("id < $%d", filter.BeforeID)
+	}
+
+	query := `
+		SELECT id, actor, action, entity_type, entity_id, before, after, created_at
+		FROM audit_events`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(" ORDER BY id DESC LIMIT $%d", len(args))
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []domain.AuditEvent{}
+	for rows.Next() {
+		var event domain.AuditEvent
+		if err := rows.Scan(&event.ID, &event.Actor, &event.Action, &event.EntityType, &event.EntityID,
+			&event.Before, &event.After, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
@@ -0,0 +1,123 @@
+package postgres
+
+import (
+	"context"
+	"pr-review-service/internal/domain"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AssignmentHistoryRepo struct {
+	db DBTX
+}
+
+func NewAssignmentHistoryRepo(db *pgxpool.Pool) *AssignmentHistoryRepo {
+	return &AssignmentHistoryRepo{db: db}
+}
+
+func (r *AssignmentHistoryRepo) RecordAssigned(ctx context.Context, prID, userID string, reason domain.AssignmentReason, actor string) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO pr_assignment_history (pull_request_id, user_id, assigned_by, assign_reason)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (pull_request_id, user_id) WHERE unassigned_at IS NULL DO NOTHING`,
+		prID, userID, actor, reason)
+	return err
+}
+
+func (r *AssignmentHistoryRepo) RecordUnassigned(ctx context.Context, prID, userID string, reason domain.AssignmentReason, actor string) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE pr_assignment_history
+		SET unassigned_at = NOW(), unassigned_by = $3, unassign_reason = $4
+		WHERE pull_request_id = $1 AND user_id = $2 AND unassigned_at IS NULL`,
+		prID, userID, actor, reason)
+	return err
+}
+
+func (r *AssignmentHistoryRepo) RecordReassignments(ctx context.Context, reassignments []domain.ReviewerReassignment, reason domain.AssignmentReason, actor string) error {
+	var prIDs, oldUserIDs, replacedPRIDs, newUserIDs []string
+	for _, ra := range reassignments {
+		prIDs = append(prIDs, ra.PullRequestID)
+		oldUserIDs = append(oldUserIDs, ra.OldReviewerID)
+		if ra.NewReviewerID != "" {
+			replacedPRIDs = append(replacedPRIDs, ra.PullRequestID)
+			newUserIDs = append(newUserIDs, ra.NewReviewerID)
+		}
+	}
+
+	_, err := r.db.Exec(ctx, `
+		UPDATE pr_assignment_history h
+		SET unassigned_at = NOW(), unassigned_by = $3, unassign_reason = $4
+		FROM unnest($1::varchar[], $2::varchar[]) AS removed (pull_request_id, user_id)
+		WHERE h.pull_request_id = removed.pull_request_id AND h.user_id = removed.user_id AND h.unassigned_at IS NULL`,
+		prIDs, oldUserIDs, actor, reason)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(ctx, `
+		INSERT INTO pr_assignment_history (pull_request_id, user_id, assigned_by, assign_reason)
+		SELECT pull_request_id, user_id, $3, $4
+		FROM unnest($1::varchar[], $2::varchar[]) AS added (pull_request_id, user_id)
+		ON CONFLICT (pull_request_id, user_id) WHERE unassigned_at IS NULL DO NOTHING`,
+		replacedPRIDs, newUserIDs, actor, reason)
+	return err
+}
+
+func (r *AssignmentHistoryRepo) ListByPR(ctx context.Context, prID string) ([]domain.AssignmentRecord, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT pull_request_id, user_id, assigned_at, assigned_by, assign_reason,
+		       unassigned_at, COALESCE(unassigned_by, ''), COALESCE(unassign_reason, '')
+		FROM pr_assignment_history
+		WHERE pull_request_id = $1
+		ORDER BY assigned_at, id`, prID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := []domain.AssignmentRecord{}
+	for rows.Next() {
+		var rec domain.AssignmentRecord
+		if err := rows.Scan(&rec.PullRequestID, &rec.UserID, &rec.AssignedAt, &rec.AssignedBy, &rec.AssignReason,
+			&rec.UnassignedAt, &rec.UnassignedBy, &rec.UnassignReason); err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+	}
+	return records, rows.Err()
+}
+
+// Stream calls fn for every history entry of the PRs matching filter's row
+// filters, grouped by PR in assignment order.
+func (r *AssignmentHistoryRepo) Stream(ctx context.Context, filter domain.PRFilter, fn func(*domain.AssignmentRecord) error) error {
+	conditions, args := prFilterConditions(filter)
+
+	query := `
+		SELECT h.pull_request_id, h.user_id, h.assigned_at, h.assigned_by, h.assign_reason,
+		       h.unassigned_at, COALESCE(h.unassigned_by, ''), COALESCE(h.unassign_reason, '')
+		FROM pr_assignment_history h
+		INNER JOIN pull_requests pr ON pr.pull_request_id = h.pull_request_id`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY h.pull_request_id, h.assigned_at, h.id"
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rec domain.AssignmentRecord
+		if err := rows.Scan(&rec.PullRequestID, &rec.UserID, &rec.AssignedAt, &rec.AssignedBy, &rec.AssignReason,
+			&rec.UnassignedAt, &rec.UnassignedBy, &rec.UnassignReason); err != nil {
+			return err
+		}
+		if err := fn(&rec); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,109 @@
+package postgres
+
+import (
+	"context"
+	"pr-review-service/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ImportRepo only works inside a unit of work: a bundle is loaded with
+// several COPY statements that must succeed or fail together.
+type ImportRepo struct {
+	db DBTX
+}
+
+func (r *ImportRepo) ExistingTeams(ctx context.Context, teamNames []string) (map[string]bool, error) {
+	return r.existing(ctx, `SELECT team_name FROM teams WHERE team_name = ANY($1)`, teamNames)
+}
+
+func (r *ImportRepo) ExistingUsers(ctx context.Context, userIDs []string) (map[string]bool, error) {
+	return r.existing(ctx, `SELECT user_id FROM users WHERE user_id = ANY($1)`, userIDs)
+}
+
+func (r *ImportRepo) ExistingPullRequests(ctx context.Context, prIDs []string) (map[string]bool, error) {
+	return r.existing(ctx, `SELECT pull_request_id FROM pull_requests WHERE pull_request_id = ANY($1)`, prIDs)
+}
+
+func (r *ImportRepo) existing(ctx context.Context, query string, keys []string) (map[string]bool, error) {
+	found := make(map[string]bool)
+	if len(keys) == 0 {
+		return found, nil
+	}
+
+	rows, err := r.db.Query(ctx, query, keys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		found[key] = true
+	}
+	return found, rows.Err()
+}
+
+func (r *ImportRepo) Load(ctx context.Context, bundle *domain.ImportBundle, actor string) error {
+	_, err := r.db.CopyFrom(ctx, pgx.Identifier{"teams"}, []string{"team_name"},
+		pgx.CopyFromSlice(len(bundle.Teams), func(i int) ([]any, error) {
+			return []any{bundle.Teams[i].TeamName}, nil
+		}))
+	if err != nil {
+		return importConflict(err)
+	}
+
+	_, err = r.db.CopyFrom(ctx, pgx.Identifier{"users"}, []string{"user_id", "username", "team_name", "is_active"},
+		pgx.CopyFromSlice(len(bundle.Users), func(i int) ([]any, error) {
+			u := bundle.Users[i]
+			return []any{u.UserID, u.Username, u.TeamName, *u.IsActive}, nil
+		}))
+	if err != nil {
+		return importConflict(err)
+	}
+
+	_, err = r.db.CopyFrom(ctx, pgx.Identifier{"pull_requests"},
+		[]string{"pull_request_id", "pull_request_name", "author_id", "status", "created_at", "merged_at"},
+		pgx.CopyFromSlice(len(bundle.PullRequests), func(i int) ([]any, error) {
+			pr := bundle.PullRequests[i]
+			return []any{pr.PullRequestID, pr.PullRequestName, pr.AuthorID, string(pr.Status), *pr.CreatedAt, pr.MergedAt}, nil
+		}))
+	if err != nil {
+		return importConflict(err)
+	}
+
+	_, err = r.db.CopyFrom(ctx, pgx.Identifier{"pr_reviewers"}, []string{"pull_request_id", "user_id", "assigned_at"},
+		pgx.CopyFromSlice(len(bundle.Assignments), func(i int) ([]any, error) {
+			a := bundle.Assignments[i]
+			return []any{a.PullRequestID, a.UserID, *a.AssignedAt}, nil
+		}))
+	if err != nil {
+		return importConflict(err)
+	}
+
+	_, err = r.db.CopyFrom(ctx, pgx.Identifier{"pr_assignment_history"},
+		[]string{"pull_request_id", "user_id", "assigned_at", "assigned_by", "assign_reason"},
+		pgx.CopyFromSlice(len(bundle.Assignments), func(i int) ([]any, error) {
+			a := bundle.Assignments[i]
+			return []any{a.PullRequestID, a.UserID, *a.AssignedAt, actor, string(domain.AssignmentReasonInitial)}, nil
+		}))
+	return importConflict(err)
+}
+
+// importConflict maps a unique violation to the matching domain error. The
+// bundle was validated against the stored rows, so a violation means another
+// transaction inserted the same key in the meantime.
+func importConflict(err error) error {
+	switch {
+	case isUniqueViolation(err, "teams_pkey"):
+		return domain.ErrTeamExists
+	case isUniqueViolation(err, "users_pkey"):
+		return domain.ErrUserExists
+	case isUniqueViolation(err, "pull_requests_pkey"):
+		return domain.ErrPRExists
+	}
+	return err
+}
@@ -0,0 +1,129 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"pr-review-service/internal/repository/migrate"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// migrationLockID is the advisory lock key that serializes migrations across
+// replicas sharing a database.
+const migrationLockID int64 = 7_108_330_457_210_938_112
+
+type migrationDriver struct {
+	pool *pgxpool.Pool
+}
+
+// NewMigrator loads the migrations found in fsys.
+func NewMigrator(pool *pgxpool.Pool, fsys fs.FS) (*migrate.Migrator, error) {
+	return migrate.New(&migrationDriver{pool: pool}, fsys)
+}
+
+// RunMigrations applies pending schema migrations from migrationsPath.
+func RunMigrations(ctx context.Context, pool *pgxpool.Pool, migrationsPath string) error {
+	migrator, err := NewMigrator(pool, os.DirFS(migrationsPath))
+	if err != nil {
+		return err
+	}
+
+	applied, err := migrator.Up(ctx, false)
+	for _, m := range applied {
+		fmt.Printf("✓ Executed migration: %s\n", m)
+	}
+	return err
+}
+
+// WithLock holds a session-level advisory lock while fn runs, so replicas
+// that start together wait for each other. The lock lives on a dedicated
+// connection, so it is released even if the process dies mid-migration.
+func (d *migrationDriver) WithLock(ctx context.Context, fn func() error) error {
+	conn, err := d.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, migrationLockID); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer func() {
+		// A fresh context so a cancelled caller still releases the lock.
+		if _, err := conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockID); err != nil {
+			// Drop the connection, which releases the lock with it.
+			conn.Conn().Close(context.Background())
+		}
+	}()
+
+	return fn()
+}
+
+func (d *migrationDriver) Applied(ctx context.Context) ([]migrate.Applied, error) {
+	_, err := d.pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			id BIGSERIAL UNIQUE,
+			kind VARCHAR(10) NOT NULL,
+			version INTEGER NOT NULL,
+			name VARCHAR(255) NOT NULL,
+			checksum CHAR(64) NOT NULL,
+			applied_at TIMESTAMP NOT NULL DEFAULT NOW(),
+			PRIMARY KEY (kind, version)
+		)
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	rows, err := d.pool.Query(ctx, `
+		SELECT kind, version, name, checksum, applied_at
+		FROM schema_migrations
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var applied []migrate.Applied
+	for rows.Next() {
+		var a migrate.Applied
+		if err := rows.Scan(&a.Kind, &a.Version, &a.Name, &a.Checksum, &a.AppliedAt); err != nil {
+			return nil, err
+		}
+		applied = append(applied, a)
+	}
+
+	return applied, rows.Err()
+}
+
+func (d *migrationDriver) Apply(ctx context.Context, mig *migrate.Migration) error {
+	return pgx.BeginFunc(ctx, d.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, mig.Up); err != nil {
+			return fmt.Errorf("failed to execute migration %s: %w", mig, err)
+		}
+
+		_, err := tx.Exec(ctx, `
+			INSERT INTO schema_migrations (kind, version, name, checksum)
+			VALUES ($1, $2, $3, $4)
+		`, mig.Kind, mig.Version, mig.Name, mig.Checksum)
+		return err
+	})
+}
+
+func (d *migrationDriver) Revert(ctx context.Context, mig *migrate.Migration) error {
+	return pgx.BeginFunc(ctx, d.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, mig.Down); err != nil {
+			return fmt.Errorf("failed to roll back migration %s: %w", mig, err)
+		}
+
+		_, err := tx.Exec(ctx, `
+			DELETE FROM schema_migrations WHERE kind = $1 AND version = $2
+		`, mig.Kind, mig.Version)
+		return err
+	})
+}
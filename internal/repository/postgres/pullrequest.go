@@ -3,7 +3,9 @@ package postgres
 import (
 	"context"
 	"errors"
+	"fmt"
 	"pr-review-service/internal/domain"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -11,13 +13,35 @@ import (
 )
 
 type PullRequestRepo struct {
-	db *pgxpool.Pool
+	db DBTX
 }
 
 func NewPullRequestRepo(db *pgxpool.Pool) *PullRequestRepo {
 	return &PullRequestRepo{db: db}
 }
 
+// prColumns selects a PR aliased as pr together with its reviewers in
+// assignment order, so every PR read is a single query however many PRs it
+// returns.
+const prColumns = `pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status, pr.created_at, pr.merged_at, pr.version,
+	pr.description, pr.url, pr.repository, pr.labels, pr.size,
+	ARRAY(SELECT prr.user_id FROM pr_reviewers prr
+	      WHERE prr.pull_request_id = pr.pull_request_id
+	      ORDER BY prr.assigned_at, prr.user_id)`
+
+func scanPR(row pgx.Row, pr *domain.PullRequest) error {
+	return row.Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &pr.MergedAt,
+		&pr.Version, &pr.Description, &pr.URL, &pr.Repository, &pr.Labels, &pr.Size, &pr.AssignedReviewers)
+}
+
+// labelsOrEmpty keeps a nil label list from being stored as NULL.
+func labelsOrEmpty(labels []string) []string {
+	if labels == nil {
+		return []string{}
+	}
+	return labels
+}
+
 func (r *PullRequestRepo) Create(ctx context.Context, pr *domain.PullRequest) error {
 	if pr.CreatedAt == nil {
 		now := time.Now()
@@ -31,10 +55,15 @@ func (r *PullRequestRepo) Create(ctx context.Context, pr *domain.PullRequest) er
 	defer tx.Rollback(ctx)
 
 	_, err = tx.Exec(ctx, `
-		INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status, created_at)
-		VALUES ($1, $2, $3, $4, $5)`,
-		pr.PullRequestID, pr.PullRequestName, pr.AuthorID, pr.Status, pr.CreatedAt)
+		INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status, created_at,
+			description, url, repository, labels, size)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		pr.PullRequestID, pr.PullRequestName, pr.AuthorID, pr.Status, pr.CreatedAt,
+		pr.Description, pr.URL, pr.Repository, labelsOrEmpty(pr.Labels), pr.Size)
 	if err != nil {
+		if isUniqueViolation(err, "pull_requests_pkey") {
+			return domain.ErrPRExists
+		}
 		return err
 	}
 
@@ -51,35 +80,51 @@ func (r *PullRequestRepo) Create(ctx context.Context, pr *domain.PullRequest) er
 }
 
 func (r *PullRequestRepo) Get(ctx context.Context, prID string) (*domain.PullRequest, error) {
-	pr := &domain.PullRequest{}
-	err := r.db.QueryRow(ctx, `
-		SELECT pull_request_id, pull_request_name, author_id, status, created_at, merged_at
-		FROM pull_requests WHERE pull_request_id = $1`, prID).
-		Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &pr.MergedAt)
+	return r.get(ctx, prID, "")
+}
+
+func (r *PullRequestRepo) GetForUpdate(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	return r.get(ctx, prID, "FOR UPDATE OF pr")
+}
 
+func (r *PullRequestRepo) get(ctx context.Context, prID, lockClause string) (*domain.PullRequest, error) {
+	pr := &domain.PullRequest{}
+	err := scanPR(r.db.QueryRow(ctx, `
+		SELECT `+prColumns+`
+		FROM pull_requests pr WHERE pr.pull_request_id = $1 `+lockClause, prID), pr)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, domain.ErrPRNotFound
 		}
 		return nil, err
 	}
-
-	reviewers, err := r.GetReviewers(ctx, prID)
-	if err != nil {
-		return nil, err
-	}
-	pr.AssignedReviewers = reviewers
-
 	return pr, nil
 }
 
 func (r *PullRequestRepo) Update(ctx context.Context, pr *domain.PullRequest) error {
-	_, err := r.db.Exec(ctx, `
+	tag, err := r.db.Exec(ctx, `
 		UPDATE pull_requests 
-		SET pull_request_name = $1, author_id = $2, status = $3, merged_at = $4
-		WHERE pull_request_id = $5`,
-		pr.PullRequestName, pr.AuthorID, pr.Status, pr.MergedAt, pr.PullRequestID)
-	return err
+		SET pull_request_name = $1, author_id = $2, status = $3, merged_at = $4,
+			description = $5, url = $6, repository = $7, labels = $8, size = $9, version = version + 1
+		WHERE pull_request_id = $10 AND version = $11`,
+		pr.PullRequestName, pr.AuthorID, pr.Status, pr.MergedAt,
+		pr.Description, pr.URL, pr.Repository, labelsOrEmpty(pr.Labels), pr.Size, pr.PullRequestID, pr.Version)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		exists, err := r.Exists(ctx, pr.PullRequestID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return domain.ErrPRNotFound
+		}
+		return domain.ErrVersionMismatch
+	}
+
+	pr.Version++
+	return nil
 }
 
 func (r *PullRequestRepo) Exists(ctx context.Context, prID string) (bool, error) {
@@ -95,13 +140,13 @@ func (r *PullRequestRepo) GetByReviewer(ctx context.Context, userID string) ([]d
 		FROM pull_requests pr
 		INNER JOIN pr_reviewers prr ON pr.pull_request_id = prr.pull_request_id
 		WHERE prr.user_id = $1
-		ORDER BY pr.created_at DESC`, userID)
+		ORDER BY pr.created_at DESC, pr.pull_request_id`, userID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var prs []domain.PullRequestShort
+	prs := []domain.PullRequestShort{}
 	for rows.Next() {
 		var pr domain.PullRequestShort
 		if err := rows.Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.Status); err != nil {
@@ -112,6 +157,112 @@ func (r *PullRequestRepo) GetByReviewer(ctx context.Context, userID string) ([]d
 	return prs, rows.Err()
 }
 
+// prSortColumns maps sort fields to SQL expressions and the type their text
+// page keys are cast to. Open PRs have no merged_at and sort as 'infinity'.
+var prSortColumns = map[domain.PRSortField]struct{ expr, cast string }{
+	domain.PRSortCreatedAt: {"pr.created_at", "timestamp"},
+	domain.PRSortMergedAt:  {"COALESCE(pr.merged_at, 'infinity'::timestamp)", "timestamp"},
+	domain.PRSortName:      {"pr.pull_request_name", "text"},
+}
+
+func (r *PullRequestRepo) List(ctx context.Context, filter domain.PRFilter) ([]domain.PullRequest, error) {
+	prs := []domain.PullRequest{}
+	err := r.Stream(ctx, filter, func(pr *domain.PullRequest) error {
+		prs = append(prs, *pr)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return prs, nil
+}
+
+func (r *PullRequestRepo) Stream(ctx context.Context, filter domain.PRFilter, fn func(*domain.PullRequest) error) error {
+	sortColumn, ok := prSortColumns[filter.SortBy]
+	if !ok {
+		return domain.ErrInvalidSort
+	}
+
+	conditions, args := prFilterConditions(filter)
+
+	direction, comparison := "ASC", ">"
+	if filter.Descending {
+		direction, comparison = "DESC", "<"
+	}
+	if filter.After != nil {
+		args = append(args, filter.After.SortValue, filter.After.PullRequestID)
+		conditions = append(conditions, fmt.Sprintf("(%s, pr.pull_request_id) %s ($%d::%s, $%d)",
+			sortColumn.expr, comparison, len(args)-1, sortColumn.cast, len(args)))
+	}
+
+	query := `SELECT ` + prColumns + ` FROM pull_requests pr`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY %s %s, pr.pull_request_id %s", sortColumn.expr, direction, direction)
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pr domain.PullRequest
+		if err := scanPR(rows, &pr); err != nil {
+			return err
+		}
+		if err := fn(&pr); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// prFilterConditions turns the row filters of filter into SQL conditions on
+// pull_requests aliased as pr. Sorting and paging are left to the caller.
+func prFilterConditions(filter domain.PRFilter) ([]string, []any) {
+	var conditions []string
+	var args []any
+	addCondition := func(format string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(format, len(args)))
+	}
+
+	if filter.Status != "" {
+		addCondition("pr.status = $%d", filter.Status)
+	}
+	if filter.AuthorID != "" {
+		addCondition("pr.author_id = $%d", filter.AuthorID)
+	}
+	if filter.ReviewerID != "" {
+		addCondition(`EXISTS (SELECT 1 FROM pr_reviewers prr
+			WHERE prr.pull_request_id = pr.pull_request_id AND prr.user_id = $%d)`, filter.ReviewerID)
+	}
+	if filter.TeamName != "" {
+		addCondition(`EXISTS (SELECT 1 FROM users au
+			WHERE au.user_id = pr.author_id AND au.team_name = $%d)`, filter.TeamName)
+	}
+	if filter.CreatedFrom != nil {
+		addCondition("pr.created_at >= $%d", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		addCondition("pr.created_at < $%d", *filter.CreatedTo)
+	}
+	if filter.MergedFrom != nil {
+		addCondition("pr.merged_at >= $%d", *filter.MergedFrom)
+	}
+	if filter.MergedTo != nil {
+		addCondition("pr.merged_at < $%d", *filter.MergedTo)
+	}
+
+	return conditions, args
+}
+
 func (r *PullRequestRepo) AssignReviewer(ctx context.Context, prID, userID string) error {
 	_, err := r.db.Exec(ctx, `
 		INSERT INTO pr_reviewers (pull_request_id, user_id)
@@ -133,13 +284,13 @@ func (r *PullRequestRepo) GetReviewers(ctx context.Context, prID string) ([]stri
 	rows, err := r.db.Query(ctx, `
 		SELECT user_id FROM pr_reviewers 
 		WHERE pull_request_id = $1
-		ORDER BY assigned_at`, prID)
+		ORDER BY assigned_at, user_id`, prID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var reviewers []string
+	reviewers := []string{}
 	for rows.Next() {
 		var userID string
 		if err := rows.Scan(&userID); err != nil {
@@ -159,59 +310,124 @@ func (r *PullRequestRepo) IsReviewer(ctx context.Context, prID, userID string) (
 }
 
 func (r *PullRequestRepo) GetOpenPRsByReviewers(ctx context.Context, userIDs []string) ([]domain.PullRequest, error) {
+	return r.queryPRs(ctx, `
+		SELECT `+prColumns+`
+		FROM pull_requests pr
+		WHERE pr.status = 'OPEN' AND EXISTS (
+			SELECT 1 FROM pr_reviewers prr
+			WHERE prr.pull_request_id = pr.pull_request_id AND prr.user_id = ANY($1))
+		ORDER BY pr.pull_request_id`,
+		userIDs)
+}
+
+// GetOpenPRsByReviewersForUpdate locks first and reads afterwards: the second
+// statement runs on a fresh snapshot, so it sees the reviewers as they are
+// once the locks are held.
+func (r *PullRequestRepo) GetOpenPRsByReviewersForUpdate(ctx context.Context, userIDs []string) ([]domain.PullRequest, error) {
 	rows, err := r.db.Query(ctx, `
-		SELECT DISTINCT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status, pr.created_at, pr.merged_at
+		SELECT pr.pull_request_id
 		FROM pull_requests pr
-		INNER JOIN pr_reviewers prr ON pr.pull_request_id = prr.pull_request_id
-		WHERE pr.status = 'OPEN' AND prr.user_id = ANY($1)`,
+		WHERE pr.status = 'OPEN' AND EXISTS (
+			SELECT 1 FROM pr_reviewers prr
+			WHERE prr.pull_request_id = pr.pull_request_id AND prr.user_id = ANY($1))
+		ORDER BY pr.pull_request_id
+		FOR UPDATE`,
 		userIDs)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var prs []domain.PullRequest
+	var prIDs []string
 	for rows.Next() {
-		var pr domain.PullRequest
-		if err := rows.Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &pr.MergedAt); err != nil {
+		var prID string
+		if err := rows.Scan(&prID); err != nil {
 			return nil, err
 		}
+		prIDs = append(prIDs, prID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
 
-		reviewers, err := r.GetReviewers(ctx, pr.PullRequestID)
-		if err != nil {
+	return r.queryPRs(ctx, `
+		SELECT `+prColumns+`
+		FROM pull_requests pr
+		WHERE pr.pull_request_id = ANY($1) AND pr.status = 'OPEN'
+		ORDER BY pr.pull_request_id`,
+		prIDs)
+}
+
+func (r *PullRequestRepo) queryPRs(ctx context.Context, query string, args ...any) ([]domain.PullRequest, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prs := []domain.PullRequest{}
+	for rows.Next() {
+		var pr domain.PullRequest
+		if err := scanPR(rows, &pr); err != nil {
 			return nil, err
 		}
-		pr.AssignedReviewers = reviewers
-
 		prs = append(prs, pr)
 	}
 	return prs, rows.Err()
 }
 
 func (r *PullRequestRepo) ReassignReviewersInBatch(ctx context.Context, oldUserID string, newAssignments map[string]string) error {
+	var prIDs, replacedPRIDs, newUserIDs []string
+	for prID, newUserID := range newAssignments {
+		prIDs = append(prIDs, prID)
+		if newUserID != "" {
+			replacedPRIDs = append(replacedPRIDs, prID)
+			newUserIDs = append(newUserIDs, newUserID)
+		}
+	}
+
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback(ctx)
 
-	for prID, newUserID := range newAssignments {
-		_, err = tx.Exec(ctx, `DELETE FROM pr_reviewers WHERE pull_request_id = $1 AND user_id = $2`, prID, oldUserID)
-		if err != nil {
-			return err
-		}
+	_, err = tx.Exec(ctx, `DELETE FROM pr_reviewers WHERE user_id = $1 AND pull_request_id = ANY($2)`, oldUserID, prIDs)
+	if err != nil {
+		return err
+	}
 
-		if newUserID != "" {
-			_, err = tx.Exec(ctx, `
-				INSERT INTO pr_reviewers (pull_request_id, user_id)
-				VALUES ($1, $2)
-				ON CONFLICT (pull_request_id, user_id) DO NOTHING`,
-				prID, newUserID)
-			if err != nil {
-				return err
-			}
-		}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO pr_reviewers (pull_request_id, user_id)
+		SELECT * FROM unnest($1::varchar[], $2::varchar[])
+		ON CONFLICT (pull_request_id, user_id) DO NOTHING`,
+		replacedPRIDs, newUserIDs)
+	if err != nil {
+		return err
 	}
 
 	return tx.Commit(ctx)
 }
+
+func (r *PullRequestRepo) BumpVersions(ctx context.Context, prIDs []string) (map[string]int, error) {
+	rows, err := r.db.Query(ctx, `
+		UPDATE pull_requests SET version = version + 1
+		WHERE pull_request_id = ANY($1)
+		RETURNING pull_request_id, version`, prIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := make(map[string]int, len(prIDs))
+	for rows.Next() {
+		var prID string
+		var version int
+		if err := rows.Scan(&prID, &version); err != nil {
+			return nil, err
+		}
+		versions[prID] = version
+	}
+	return versions, rows.Err()
+}
@@ -0,0 +1,170 @@
+package postgres
+
+import (
+	"context"
+	"pr-review-service/internal/domain"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type StatsRepo struct {
+	db DBTX
+}
+
+func NewStatsRepo(db *pgxpool.Pool) *StatsRepo {
+	return &StatsRepo{db: db}
+}
+
+// Every metric query takes the window as $1 and $2 and the optional team and
+// user scope as $3 and $4, where an empty string means "any".
+
+func (r *StatsRepo) GetReviewMetrics(ctx context.Context, filter domain.MetricsFilter) (*domain.ReviewMetrics, error) {
+	metrics := &domain.ReviewMetrics{
+		TeamName:   filter.TeamName,
+		UserID:     filter.UserID,
+		From:       filter.From,
+		To:         filter.To,
+		Throughput: []domain.WeeklyThroughput{},
+	}
+	args := []any{filter.From, filter.To, filter.TeamName, filter.UserID}
+
+	err := r.db.QueryRow(ctx, `
+		SELECT COUNT(*),
+		       percentile_cont(0.5) WITHIN GROUP (ORDER BY seconds),
+		       percentile_cont(0.9) WITHIN GROUP (ORDER BY seconds)
+		FROM (
+			SELECT EXTRACT(EPOCH FROM pr.merged_at - pr.created_at)::float8 AS seconds
+			FROM pull_requests pr
+			INNER JOIN users u ON u.user_id = pr.author_id
+			WHERE pr.merged_at >= $1 AND pr.merged_at < $2
+			  AND ($3::text = '' OR u.team_name = $3)
+			  AND ($4::text = '' OR pr.author_id = $4)
+		) merged`, args...).
+		Scan(&metrics.TimeToMerge.Count, &metrics.TimeToMerge.MedianSeconds, &metrics.TimeToMerge.P90Seconds)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.db.QueryRow(ctx, `
+		SELECT COUNT(*),
+		       percentile_cont(0.5) WITHIN GROUP (ORDER BY seconds),
+		       percentile_cont(0.9) WITHIN GROUP (ORDER BY seconds)
+		FROM (
+			SELECT EXTRACT(EPOCH FROM MIN(h.assigned_at) - pr.created_at)::float8 AS seconds
+			FROM pull_requests pr
+			INNER JOIN users u ON u.user_id = pr.author_id
+			INNER JOIN pr_assignment_history h ON h.pull_request_id = pr.pull_request_id
+			WHERE pr.created_at >= $1 AND pr.created_at < $2
+			  AND ($3::text = '' OR u.team_name = $3)
+			  AND ($4::text = '' OR pr.author_id = $4)
+			GROUP BY pr.pull_request_id, pr.created_at
+		) first_review`, args...).
+		Scan(&metrics.TimeToFirstReview.Count, &metrics.TimeToFirstReview.MedianSeconds, &metrics.TimeToFirstReview.P90Seconds)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, `
+		WITH scoped AS (
+			SELECT pr.created_at, pr.merged_at
+			FROM pull_requests pr
+			INNER JOIN users u ON u.user_id = pr.author_id
+			WHERE ($3::text = '' OR u.team_name = $3)
+			  AND ($4::text = '' OR pr.author_id = $4)
+		),
+		events AS (
+			SELECT date_trunc('week', created_at) AS week_start, 1 AS opened, 0 AS merged
+			FROM scoped WHERE created_at >= $1 AND created_at < $2
+			UNION ALL
+			SELECT date_trunc('week', merged_at), 0, 1
+			FROM scoped WHERE merged_at >= $1 AND merged_at < $2
+		)
+		SELECT w.week_start, COALESCE(SUM(e.opened), 0), COALESCE(SUM(e.merged), 0)
+		FROM generate_series(date_trunc('week', $1::timestamp), $2::timestamp, interval '1 week') AS w(week_start)
+		LEFT JOIN events e ON e.week_start = w.week_start
+		WHERE w.week_start < $2
+		GROUP BY w.week_start
+		ORDER BY w.week_start`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var week domain.WeeklyThroughput
+		if err := rows.Scan(&week.WeekStart, &week.Opened, &week.Merged); err != nil {
+			return nil, err
+		}
+		metrics.Throughput = append(metrics.Throughput, week)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	err = r.db.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM pr_assignment_history h
+		INNER JOIN pull_requests pr ON pr.pull_request_id = h.pull_request_id
+		INNER JOIN users u ON u.user_id = h.user_id
+		WHERE h.unassigned_at IS NULL
+		  AND pr.merged_at >= $1 AND pr.merged_at < $2
+		  AND ($3::text = '' OR u.team_name = $3)
+		  AND ($4::text = '' OR h.user_id = $4)`, args...).
+		Scan(&metrics.ReviewsCompleted)
+	if err != nil {
+		return nil, err
+	}
+
+	return metrics, nil
+}
+
+// GetMemberLoad returns the members in team and username order. Active days
+// are the time each member spent active within the window.
+func (r *StatsRepo) GetMemberLoad(ctx context.Context, filter domain.FairnessFilter) ([]domain.MemberLoad, error) {
+	rows, err := r.db.Query(ctx, `
+		WITH members AS (
+			SELECT user_id, username, team_name
+			FROM users
+			WHERE deleted_at IS NULL AND ($3::text = '' OR team_name = $3)
+		),
+		intervals AS (
+			SELECT h.user_id, h.is_active,
+			       GREATEST(h.changed_at, $1) AS starts,
+			       LEAST(COALESCE(LEAD(h.changed_at) OVER (PARTITION BY h.user_id ORDER BY h.changed_at, h.id), $2), $2) AS ends
+			FROM user_activation_history h
+			WHERE h.user_id IN (SELECT user_id FROM members)
+		),
+		active AS (
+			SELECT user_id, SUM(EXTRACT(EPOCH FROM ends - starts)) / 86400 AS days
+			FROM intervals
+			WHERE is_active AND ends > starts
+			GROUP BY user_id
+		),
+		assigned AS (
+			SELECT r.user_id, COUNT(*) AS cnt
+			FROM pr_reviewers r
+			WHERE r.assigned_at >= $1 AND r.assigned_at < $2
+			  AND r.user_id IN (SELECT user_id FROM members)
+			GROUP BY r.user_id
+		)
+		SELECT m.team_name, m.user_id, m.username, COALESCE(assigned.cnt, 0), COALESCE(active.days, 0)::float8
+		FROM members m
+		LEFT JOIN active USING (user_id)
+		LEFT JOIN assigned USING (user_id)
+		ORDER BY m.team_name, m.username, m.user_id`,
+		filter.From, filter.To, filter.TeamName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	loads := []domain.MemberLoad{}
+	for rows.Next() {
+		var load domain.MemberLoad
+		if err := rows.Scan(&load.TeamName, &load.UserID, &load.Username, &load.Assignments, &load.ActiveDays); err != nil {
+			return nil, err
+		}
+		loads = append(loads, load)
+	}
+	return loads, rows.Err()
+}
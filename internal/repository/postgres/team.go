@@ -2,13 +2,15 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"pr-review-service/internal/domain"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type TeamRepo struct {
-	db *pgxpool.Pool
+	db DBTX
 }
 
 func NewTeamRepo(db *pgxpool.Pool) *TeamRepo {
@@ -18,6 +20,9 @@ func NewTeamRepo(db *pgxpool.Pool) *TeamRepo {
 func (r *TeamRepo) Create(ctx context.Context, team *domain.Team) error {
 	_, err := r.db.Exec(ctx, `INSERT INTO teams (team_name) VALUES ($1)`, team.TeamName)
 	if err != nil {
+		if isUniqueViolation(err, "teams_pkey") {
+			return domain.ErrTeamExists
+		}
 		return err
 	}
 	return nil
@@ -29,21 +34,20 @@ func (r *TeamRepo) Get(ctx context.Context, teamName string) (*domain.Team, erro
 		Members:  []domain.TeamMember{},
 	}
 
-	var exists bool
-	err := r.db.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM teams WHERE team_name = $1)`, teamName).
-		Scan(&exists)
+	err := r.db.QueryRow(ctx, `SELECT version FROM teams WHERE team_name = $1`, teamName).
+		Scan(&team.Version)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrTeamNotFound
+		}
 		return nil, err
 	}
-	if !exists {
-		return nil, domain.ErrTeamNotFound
-	}
 
 	rows, err := r.db.Query(ctx, `
 		SELECT user_id, username, is_active 
 		FROM users 
-		WHERE team_name = $1
-		ORDER BY username`,
+		WHERE team_name = $1 AND deleted_at IS NULL
+		ORDER BY username, user_id`,
 		teamName)
 	if err != nil {
 		return nil, err
@@ -72,3 +76,35 @@ func (r *TeamRepo) DeactivateAll(ctx context.Context, teamName string) error {
 	_, err := r.db.Exec(ctx, `UPDATE users SET is_active = false WHERE team_name = $1`, teamName)
 	return err
 }
+
+func (r *TeamRepo) ActivateAll(ctx context.Context, teamName string, userIDs []string) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE users SET is_active = true
+		WHERE team_name = $1 AND deleted_at IS NULL AND (COALESCE(cardinality($2::varchar[]), 0) = 0 OR user_id = ANY($2))`,
+		teamName, userIDs)
+	return err
+}
+
+func (r *TeamRepo) BumpVersion(ctx context.Context, teamName string, expectedVersion int) (int, error) {
+	var version int
+	err := r.db.QueryRow(ctx, `
+		UPDATE teams SET version = version + 1
+		WHERE team_name = $1 AND ($2::int = 0 OR version = $2)
+		RETURNING version`,
+		teamName, expectedVersion).Scan(&version)
+	if err == nil {
+		return version, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return 0, err
+	}
+
+	exists, err := r.Exists(ctx, teamName)
+	if err != nil {
+		return 0, err
+	}
+	if !exists {
+		return 0, domain.ErrTeamNotFound
+	}
+	return 0, domain.ErrVersionMismatch
+}
@@ -0,0 +1,50 @@
+package postgres
+
+import (
+	"context"
+	"pr-review-service/internal/repository"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DBTX is implemented by both *pgxpool.Pool and pgx.Tx, so the same repository
+// code runs either directly on the pool or inside a transaction.
+type DBTX interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Begin(ctx context.Context) (pgx.Tx, error)
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
+}
+
+type UnitOfWork struct {
+	db *pgxpool.Pool
+}
+
+func NewUnitOfWork(db *pgxpool.Pool) *UnitOfWork {
+	return &UnitOfWork{db: db}
+}
+
+func (u *UnitOfWork) WithinTx(ctx context.Context, fn func(ctx context.Context, repos repository.Repository) error) error {
+	tx, err := u.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	repos := repository.Repository{
+		Team:        &TeamRepo{db: tx},
+		User:        &UserRepo{db: tx},
+		PullRequest: &PullRequestRepo{db: tx},
+		Audit:       &AuditRepo{db: tx},
+		History:     &AssignmentHistoryRepo{db: tx},
+		Import:      &ImportRepo{db: tx},
+	}
+	if err := fn(ctx, repos); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
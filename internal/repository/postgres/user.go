@@ -3,14 +3,16 @@ package postgres
 import (
 	"context"
 	"errors"
+	"fmt"
 	"pr-review-service/internal/domain"
+	"strings"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type UserRepo struct {
-	db *pgxpool.Pool
+	db DBTX
 }
 
 func NewUserRepo(db *pgxpool.Pool) *UserRepo {
@@ -32,18 +34,18 @@ func (r *UserRepo) Create(ctx context.Context, user *domain.User) error {
 func (r *UserRepo) Update(ctx context.Context, user *domain.User) error {
 	_, err := r.db.Exec(ctx, `
 		UPDATE users 
-		SET username = $1, team_name = $2, is_active = $3
-		WHERE user_id = $4`,
-		user.Username, user.TeamName, user.IsActive, user.UserID)
+		SET username = $1, team_name = $2, is_active = $3, deleted_at = $4
+		WHERE user_id = $5`,
+		user.Username, user.TeamName, user.IsActive, user.DeletedAt, user.UserID)
 	return err
 }
 
 func (r *UserRepo) Get(ctx context.Context, userID string) (*domain.User, error) {
 	user := &domain.User{}
 	err := r.db.QueryRow(ctx, `
-		SELECT user_id, username, team_name, is_active
+		SELECT user_id, username, team_name, is_active, deleted_at
 		FROM users WHERE user_id = $1`, userID).
-		Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive)
+		Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive, &user.DeletedAt)
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -56,18 +58,18 @@ func (r *UserRepo) Get(ctx context.Context, userID string) (*domain.User, error)
 
 func (r *UserRepo) GetByTeam(ctx context.Context, teamName string) ([]domain.User, error) {
 	rows, err := r.db.Query(ctx, `
-		SELECT user_id, username, team_name, is_active
-		FROM users WHERE team_name = $1
-		ORDER BY username`, teamName)
+		SELECT user_id, username, team_name, is_active, deleted_at
+		FROM users WHERE team_name = $1 AND deleted_at IS NULL
+		ORDER BY username, user_id`, teamName)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var users []domain.User
+	users := []domain.User{}
 	for rows.Next() {
 		var user domain.User
-		if err := rows.Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive); err != nil {
+		if err := rows.Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive, &user.DeletedAt); err != nil {
 			return nil, err
 		}
 		users = append(users, user)
@@ -77,18 +79,18 @@ func (r *UserRepo) GetByTeam(ctx context.Context, teamName string) ([]domain.Use
 
 func (r *UserRepo) GetActiveByTeam(ctx context.Context, teamName string) ([]domain.User, error) {
 	rows, err := r.db.Query(ctx, `
-		SELECT user_id, username, team_name, is_active
-		FROM users WHERE team_name = $1 AND is_active = true
-		ORDER BY username`, teamName)
+		SELECT user_id, username, team_name, is_active, deleted_at
+		FROM users WHERE team_name = $1 AND is_active = true AND deleted_at IS NULL
+		ORDER BY username, user_id`, teamName)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var users []domain.User
+	users := []domain.User{}
 	for rows.Next() {
 		var user domain.User
-		if err := rows.Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive); err != nil {
+		if err := rows.Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive, &user.DeletedAt); err != nil {
 			return nil, err
 		}
 		users = append(users, user)
@@ -96,6 +98,47 @@ func (r *UserRepo) GetActiveByTeam(ctx context.Context, teamName string) ([]doma
 	return users, rows.Err()
 }
 
+func (r *UserRepo) GetByIDs(ctx context.Context, userIDs []string) (map[string]domain.User, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT user_id, username, team_name, is_active, deleted_at
+		FROM users WHERE user_id = ANY($1)`, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make(map[string]domain.User, len(userIDs))
+	for rows.Next() {
+		var user domain.User
+		if err := rows.Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive, &user.DeletedAt); err != nil {
+			return nil, err
+		}
+		users[user.UserID] = user
+	}
+	return users, rows.Err()
+}
+
+func (r *UserRepo) GetActiveByTeams(ctx context.Context, teamNames []string) (map[string][]domain.User, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT user_id, username, team_name, is_active, deleted_at
+		FROM users WHERE team_name = ANY($1) AND is_active = true AND deleted_at IS NULL
+		ORDER BY team_name, username, user_id`, teamNames)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make(map[string][]domain.User, len(teamNames))
+	for rows.Next() {
+		var user domain.User
+		if err := rows.Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive, &user.DeletedAt); err != nil {
+			return nil, err
+		}
+		users[user.TeamName] = append(users[user.TeamName], user)
+	}
+	return users, rows.Err()
+}
+
 func (r *UserRepo) SetIsActive(ctx context.Context, userID string, isActive bool) (*domain.User, error) {
 	user, err := r.Get(ctx, userID)
 	if err != nil {
@@ -113,26 +156,138 @@ func (r *UserRepo) SetIsActive(ctx context.Context, userID string, isActive bool
 	return user, nil
 }
 
-func (r *UserRepo) GetStats(ctx context.Context, limit int) ([]domain.UserStats, error) {
+func (r *UserRepo) GetStats(ctx context.Context, filter domain.UserStatsFilter) ([]domain.UserStats, error) {
+	stats := []domain.UserStats{}
+	err := r.StreamStats(ctx, filter, func(stat *domain.UserStats) error {
+		stats = append(stats, *stat)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (r *UserRepo) StreamStats(ctx context.Context, filter domain.UserStatsFilter, fn func(*domain.UserStats) error) error {
 	rows, err := r.db.Query(ctx, `
 		SELECT u.user_id, u.username, COUNT(pr.pull_request_id) as review_count
 		FROM users u
 		LEFT JOIN pr_reviewers pr ON u.user_id = pr.user_id
+			AND ($2::timestamp IS NULL OR pr.assigned_at >= $2)
+			AND ($3::timestamp IS NULL OR pr.assigned_at < $3)
+		WHERE u.deleted_at IS NULL
 		GROUP BY u.user_id, u.username
-		ORDER BY review_count DESC, u.username
-		LIMIT $1`, limit)
+		ORDER BY review_count DESC, u.username, u.user_id
+		LIMIT NULLIF($1::int, 0)`, filter.Limit, filter.From, filter.To)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
 
-	var stats []domain.UserStats
 	for rows.Next() {
 		var stat domain.UserStats
 		if err := rows.Scan(&stat.UserID, &stat.Username, &stat.ReviewCount); err != nil {
+			return err
+		}
+		if err := fn(&stat); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (r *UserRepo) List(ctx context.Context, filter domain.UserFilter) ([]domain.User, error) {
+	var conditions []string
+	var args []any
+	addCondition := func(format string, values ...any) {
+		placeholders := make([]any, len(values))
+		for i, v := range values {
+			args = append(args, v)
+			placeholders[i] = len(args)
+		}
+		conditions = append(conditions, fmt.Sprintf(format, placeholders...))
+	}
+
+	conditions = append(conditions, "deleted_at IS NULL")
+	if filter.TeamName != "" {
+		addCondition("team_name = $%d", filter.TeamName)
+	}
+	if filter.IsActive != nil {
+		addCondition("is_active = $%d", *filter.IsActive)
+	}
+	if filter.NamePrefix != "" {
+		addCondition(`username LIKE $%d || '%%'`, escapeLike(filter.NamePrefix))
+	}
+	if filter.After != nil {
+		addCondition("(username, user_id) > ($%d, $%d)", filter.After.Username, filter.After.UserID)
+	}
+
+	query := `
+		SELECT user_id, username, team_name, is_active, deleted_at
+		FROM users
+		WHERE ` + strings.Join(conditions, " AND ")
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(" ORDER BY username, user_id LIMIT $%d", len(args))
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []domain.User{}
+	for rows.Next() {
+		var user domain.User
+		if err := rows.Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive, &user.DeletedAt); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+func (r *UserRepo) GetActivity(ctx context.Context, userIDs []string) (map[string]domain.UserActivity, error) {
+	rows, err := r.db.Query(ctx, `
+		WITH ids AS (
+			SELECT DISTINCT unnest($1::varchar[]) AS user_id
+		),
+		reviews AS (
+			SELECT prr.user_id, COUNT(*) AS cnt
+			FROM pr_reviewers prr
+			INNER JOIN pull_requests pr ON pr.pull_request_id = prr.pull_request_id
+			WHERE pr.status = 'OPEN' AND prr.user_id = ANY($1)
+			GROUP BY prr.user_id
+		),
+		authored AS (
+			SELECT author_id AS user_id, COUNT(*) AS cnt
+			FROM pull_requests
+			WHERE status = 'OPEN' AND author_id = ANY($1)
+			GROUP BY author_id
+		),
+		assigned AS (
+			SELECT user_id, MAX(assigned_at) AS last_assigned_at
+			FROM pr_assignment_history
+			WHERE user_id = ANY($1)
+			GROUP BY user_id
+		)
+		SELECT ids.user_id, COALESCE(reviews.cnt, 0), COALESCE(authored.cnt, 0), assigned.last_assigned_at
+		FROM ids
+		LEFT JOIN reviews USING (user_id)
+		LEFT JOIN authored USING (user_id)
+		LEFT JOIN assigned USING (user_id)`, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	activity := make(map[string]domain.UserActivity, len(userIDs))
+	for rows.Next() {
+		var userID string
+		var a domain.UserActivity
+		if err := rows.Scan(&userID, &a.OpenReviewCount, &a.AuthoredOpenPRCount, &a.LastAssignedAt); err != nil {
 			return nil, err
 		}
-		stats = append(stats, stat)
+		activity[userID] = a
 	}
-	return stats, rows.Err()
+	return activity, rows.Err()
 }
@@ -0,0 +1,472 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"pr-review-service/internal/domain"
+	"pr-review-service/internal/repository"
+)
+
+// seedReviewTeam creates the team most PR tests work with: author a1 and
+// reviewers r1 to r3.
+func seedReviewTeam(t *testing.T, repos repository.Repository) {
+	t.Helper()
+
+	seedTeam(t, repos, "review",
+		user("a1", "Author", "review", true),
+		user("r1", "Reviewer One", "review", true),
+		user("r2", "Reviewer Two", "review", true),
+		user("r3", "Reviewer Three", "review", true),
+	)
+}
+
+func testPullRequests(t *testing.T, newRepos Factory) {
+	ctx := context.Background()
+
+	t.Run("Create and Get", func(t *testing.T) {
+		repos := newRepos(t)
+		seedReviewTeam(t, repos)
+		seedPR(t, repos, "pr-1", "First", "a1", 0, "r2", "r1")
+
+		pr, err := repos.PullRequest.Get(ctx, "pr-1")
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if pr.PullRequestName != "First" || pr.AuthorID != "a1" || pr.Status != domain.PRStatusOpen ||
+			pr.Version != 1 || pr.MergedAt != nil || pr.CreatedAt == nil || !pr.CreatedAt.Equal(epoch) {
+			t.Errorf("Get returned %+v", pr)
+		}
+		// Reviewers created together tie on assignment time.
+		assertIDs(t, pr.AssignedReviewers, []string{"r1", "r2"})
+
+		_, err = repos.PullRequest.Get(ctx, "missing")
+		assertErr(t, err, domain.ErrPRNotFound)
+
+		err = repos.PullRequest.Create(ctx, &domain.PullRequest{
+			PullRequestID: "pr-1", PullRequestName: "Again", AuthorID: "a1", Status: domain.PRStatusOpen,
+		})
+		assertErr(t, err, domain.ErrPRExists)
+	})
+
+	t.Run("GetForUpdate", func(t *testing.T) {
+		repos := newRepos(t)
+		seedReviewTeam(t, repos)
+		seedPR(t, repos, "pr-1", "First", "a1", 0, "r2", "r1")
+
+		// Fill every field, so a column missing from either read shows up.
+		pr, err := repos.PullRequest.Get(ctx, "pr-1")
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		pr.Status = domain.PRStatusMerged
+		pr.MergedAt = timeAt(2)
+		pr.Description = "Described"
+		pr.URL = "https://git.example.com/svc/pull/1"
+		pr.Repository = "svc"
+		pr.Labels = []string{"backend"}
+		pr.Size = domain.PRSizeS
+		if err := repos.PullRequest.Update(ctx, pr); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+
+		want, err := repos.PullRequest.Get(ctx, "pr-1")
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		got, err := repos.PullRequest.GetForUpdate(ctx, "pr-1")
+		if err != nil {
+			t.Fatalf("GetForUpdate failed: %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Expected GetForUpdate to return what Get does:\n%+v\n%+v", got, want)
+		}
+
+		_, err = repos.PullRequest.GetForUpdate(ctx, "missing")
+		assertErr(t, err, domain.ErrPRNotFound)
+	})
+
+	t.Run("Get without reviewers", func(t *testing.T) {
+		repos := newRepos(t)
+		seedReviewTeam(t, repos)
+		seedPR(t, repos, "pr-1", "Lonely", "a1", 0)
+
+		pr, err := repos.PullRequest.Get(ctx, "pr-1")
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		assertIDs(t, pr.AssignedReviewers, []string{})
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		repos := newRepos(t)
+		seedReviewTeam(t, repos)
+		seedPR(t, repos, "pr-1", "First", "a1", 0)
+
+		pr, err := repos.PullRequest.Get(ctx, "pr-1")
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		stale := *pr
+
+		mergedAt := timeAt(1)
+		pr.PullRequestName = "Renamed"
+		pr.Status = domain.PRStatusMerged
+		pr.MergedAt = mergedAt
+		if err := repos.PullRequest.Update(ctx, pr); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+		if pr.Version != 2 {
+			t.Errorf("Expected Update to advance the version to 2, got %d", pr.Version)
+		}
+
+		stored, err := repos.PullRequest.Get(ctx, "pr-1")
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if stored.PullRequestName != "Renamed" || stored.Status != domain.PRStatusMerged || stored.Version != 2 ||
+			stored.MergedAt == nil || !stored.MergedAt.Equal(*mergedAt) {
+			t.Errorf("Expected the update to be stored, got %+v", stored)
+		}
+
+		assertErr(t, repos.PullRequest.Update(ctx, &stale), domain.ErrVersionMismatch)
+		if stale.Version != 1 {
+			t.Errorf("Expected a failed Update to keep the version, got %d", stale.Version)
+		}
+
+		missing := stale
+		missing.PullRequestID = "missing"
+		assertErr(t, repos.PullRequest.Update(ctx, &missing), domain.ErrPRNotFound)
+	})
+
+	t.Run("Metadata", func(t *testing.T) {
+		repos := newRepos(t)
+		seedReviewTeam(t, repos)
+		seedPR(t, repos, "pr-1", "Plain", "a1", 0)
+
+		pr, err := repos.PullRequest.Get(ctx, "pr-1")
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if pr.Description != "" || pr.URL != "" || pr.Repository != "" || pr.Size != "" {
+			t.Errorf("Expected no metadata, got %+v", pr)
+		}
+		assertIDs(t, pr.Labels, []string{})
+
+		pr.Description = "Adds metadata"
+		pr.URL = "https://git.example.com/svc/pull/1"
+		pr.Repository = "svc"
+		pr.Labels = []string{"backend", "api"}
+		pr.Size = domain.PRSizeM
+		if err := repos.PullRequest.Update(ctx, pr); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+		if err := repos.PullRequest.Create(ctx, &domain.PullRequest{
+			PullRequestID: "pr-2", PullRequestName: "Described", AuthorID: "a1", Status: domain.PRStatusOpen,
+			Description: "Adds metadata", URL: "https://git.example.com/svc/pull/1", Repository: "svc",
+			Labels: []string{"backend", "api"}, Size: domain.PRSizeM,
+		}); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+
+		for _, prID := range []string{"pr-1", "pr-2"} {
+			stored, err := repos.PullRequest.Get(ctx, prID)
+			if err != nil {
+				t.Fatalf("Get failed: %v", err)
+			}
+			if stored.Description != "Adds metadata" || stored.URL != "https://git.example.com/svc/pull/1" ||
+				stored.Repository != "svc" || stored.Size != domain.PRSizeM {
+				t.Errorf("Expected the metadata of %s to be stored, got %+v", prID, stored)
+			}
+			assertIDs(t, stored.Labels, []string{"backend", "api"})
+		}
+
+		pr.Labels = nil
+		if err := repos.PullRequest.Update(ctx, pr); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+		stored, err := repos.PullRequest.Get(ctx, "pr-1")
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		assertIDs(t, stored.Labels, []string{})
+	})
+
+	t.Run("Exists", func(t *testing.T) {
+		repos := newRepos(t)
+		seedReviewTeam(t, repos)
+		seedPR(t, repos, "pr-1", "First", "a1", 0)
+
+		for prID, want := range map[string]bool{"pr-1": true, "missing": false} {
+			exists, err := repos.PullRequest.Exists(ctx, prID)
+			if err != nil {
+				t.Fatalf("Exists failed: %v", err)
+			}
+			if exists != want {
+				t.Errorf("Expected Exists(%s) = %v, got %v", prID, want, exists)
+			}
+		}
+	})
+
+	t.Run("Reviewers", func(t *testing.T) {
+		repos := newRepos(t)
+		seedReviewTeam(t, repos)
+		seedPR(t, repos, "pr-1", "First", "a1", 0)
+
+		reviewers, err := repos.PullRequest.GetReviewers(ctx, "pr-1")
+		if err != nil {
+			t.Fatalf("GetReviewers failed: %v", err)
+		}
+		assertIDs(t, reviewers, []string{})
+
+		for _, id := range []string{"r3", "r1", "r3"} {
+			if err := repos.PullRequest.AssignReviewer(ctx, "pr-1", id); err != nil {
+				t.Fatalf("AssignReviewer(%s) failed: %v", id, err)
+			}
+		}
+		reviewers, err = repos.PullRequest.GetReviewers(ctx, "pr-1")
+		if err != nil {
+			t.Fatalf("GetReviewers failed: %v", err)
+		}
+		assertIDs(t, reviewers, []string{"r3", "r1"})
+
+		if err := repos.PullRequest.AssignReviewer(ctx, "missing", "r1"); err == nil {
+			t.Error("Expected assigning to a missing PR to fail")
+		}
+		if err := repos.PullRequest.AssignReviewer(ctx, "pr-1", "missing"); err == nil {
+			t.Error("Expected assigning a missing user to fail")
+		}
+
+		for id, want := range map[string]bool{"r1": true, "r2": false} {
+			isReviewer, err := repos.PullRequest.IsReviewer(ctx, "pr-1", id)
+			if err != nil {
+				t.Fatalf("IsReviewer failed: %v", err)
+			}
+			if isReviewer != want {
+				t.Errorf("Expected IsReviewer(%s) = %v, got %v", id, want, isReviewer)
+			}
+		}
+
+		if err := repos.PullRequest.RemoveReviewer(ctx, "pr-1", "r3"); err != nil {
+			t.Fatalf("RemoveReviewer failed: %v", err)
+		}
+		if err := repos.PullRequest.RemoveReviewer(ctx, "pr-1", "r2"); err != nil {
+			t.Errorf("Expected removing a non-reviewer to be a no-op, got %v", err)
+		}
+		reviewers, err = repos.PullRequest.GetReviewers(ctx, "pr-1")
+		if err != nil {
+			t.Fatalf("GetReviewers failed: %v", err)
+		}
+		assertIDs(t, reviewers, []string{"r1"})
+
+		reviewers, err = repos.PullRequest.GetReviewers(ctx, "missing")
+		if err != nil {
+			t.Fatalf("GetReviewers failed: %v", err)
+		}
+		assertIDs(t, reviewers, []string{})
+	})
+
+	t.Run("GetByReviewer", func(t *testing.T) {
+		repos := newRepos(t)
+		seedReviewTeam(t, repos)
+		seedPR(t, repos, "pr-old", "Old", "a1", 0, "r1")
+		seedPR(t, repos, "pr-new", "New", "a1", 2, "r1", "r2")
+		seedPR(t, repos, "pr-b", "Tie", "a1", 1, "r1")
+		seedPR(t, repos, "pr-a", "Tie", "a1", 1, "r1")
+		merge(t, repos, "pr-old", 3)
+
+		prs, err := repos.PullRequest.GetByReviewer(ctx, "r1")
+		if err != nil {
+			t.Fatalf("GetByReviewer failed: %v", err)
+		}
+		var ids []string
+		for _, pr := range prs {
+			ids = append(ids, pr.PullRequestID)
+		}
+		assertIDs(t, ids, []string{"pr-new", "pr-a", "pr-b", "pr-old"})
+		if len(prs) == 4 && (prs[3].Status != domain.PRStatusMerged || prs[3].PullRequestName != "Old" || prs[3].AuthorID != "a1") {
+			t.Errorf("Expected the merged PR last, got %+v", prs[3])
+		}
+
+		prs, err = repos.PullRequest.GetByReviewer(ctx, "r3")
+		if err != nil {
+			t.Fatalf("GetByReviewer failed: %v", err)
+		}
+		if prs == nil || len(prs) != 0 {
+			t.Errorf("Expected no reviews as an empty slice, got %#v", prs)
+		}
+	})
+
+	t.Run("List and Stream", func(t *testing.T) {
+		repos := newRepos(t)
+		seedReviewTeam(t, repos)
+		seedTeam(t, repos, "other", user("o1", "Outsider", "other", true))
+		seedPR(t, repos, "pr-1", "charlie", "a1", 0, "r1")
+		seedPR(t, repos, "pr-2", "alpha", "a1", 1, "r2")
+		seedPR(t, repos, "pr-3", "bravo", "o1", 2)
+		seedPR(t, repos, "pr-4", "alpha", "a1", 3, "r1")
+		merge(t, repos, "pr-1", 4)
+
+		list := func(filter domain.PRFilter) []string {
+			t.Helper()
+			prs, err := repos.PullRequest.List(ctx, filter)
+			if err != nil {
+				t.Fatalf("List failed: %v", err)
+			}
+			return prIDs(prs)
+		}
+
+		byName := domain.PRFilter{SortBy: domain.PRSortName}
+		assertIDs(t, list(byName), []string{"pr-2", "pr-4", "pr-3", "pr-1"})
+
+		desc := byName
+		desc.Descending = true
+		assertIDs(t, list(desc), []string{"pr-1", "pr-3", "pr-4", "pr-2"})
+
+		limited := byName
+		limited.Limit = 2
+		assertIDs(t, list(limited), []string{"pr-2", "pr-4"})
+
+		page := byName
+		page.After = &domain.PRPageKey{SortValue: "alpha", PullRequestID: "pr-4"}
+		assertIDs(t, list(page), []string{"pr-3", "pr-1"})
+
+		// Open PRs have no merge time and sort after every merged one.
+		assertIDs(t, list(domain.PRFilter{SortBy: domain.PRSortMergedAt}), []string{"pr-1", "pr-2", "pr-3", "pr-4"})
+		assertIDs(t, list(domain.PRFilter{SortBy: domain.PRSortCreatedAt, Descending: true}), []string{"pr-4", "pr-3", "pr-2", "pr-1"})
+
+		filtered := []struct {
+			filter domain.PRFilter
+			want   []string
+		}{
+			{domain.PRFilter{Status: domain.PRStatusOpen}, []string{"pr-2", "pr-3", "pr-4"}},
+			{domain.PRFilter{AuthorID: "o1"}, []string{"pr-3"}},
+			{domain.PRFilter{ReviewerID: "r1"}, []string{"pr-1", "pr-4"}},
+			{domain.PRFilter{TeamName: "other"}, []string{"pr-3"}},
+			{domain.PRFilter{CreatedFrom: timeAt(1), CreatedTo: timeAt(3)}, []string{"pr-2", "pr-3"}},
+			{domain.PRFilter{MergedFrom: timeAt(0)}, []string{"pr-1"}},
+			{domain.PRFilter{AuthorID: "missing"}, []string{}},
+		}
+		for _, tc := range filtered {
+			tc.filter.SortBy = domain.PRSortCreatedAt
+			assertIDs(t, list(tc.filter), tc.want)
+		}
+
+		prs, err := repos.PullRequest.List(ctx, domain.PRFilter{SortBy: domain.PRSortCreatedAt, AuthorID: "o1"})
+		if err != nil {
+			t.Fatalf("List failed: %v", err)
+		}
+		if len(prs) == 1 {
+			assertIDs(t, prs[0].AssignedReviewers, []string{})
+		}
+
+		_, err = repos.PullRequest.List(ctx, domain.PRFilter{SortBy: "author_id"})
+		assertErr(t, err, domain.ErrInvalidSort)
+
+		var streamed []string
+		err = repos.PullRequest.Stream(ctx, byName, func(pr *domain.PullRequest) error {
+			streamed = append(streamed, pr.PullRequestID)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Stream failed: %v", err)
+		}
+		assertIDs(t, streamed, []string{"pr-2", "pr-4", "pr-3", "pr-1"})
+
+		errStop := errors.New("stop")
+		err = repos.PullRequest.Stream(ctx, byName, func(*domain.PullRequest) error { return errStop })
+		assertErr(t, err, errStop)
+	})
+
+	t.Run("GetOpenPRsByReviewers", func(t *testing.T) {
+		repos := newRepos(t)
+		seedReviewTeam(t, repos)
+		seedPR(t, repos, "pr-2", "Both", "a1", 0, "r1", "r2")
+		seedPR(t, repos, "pr-1", "One", "a1", 1, "r1")
+		seedPR(t, repos, "pr-3", "Merged", "a1", 2, "r2")
+		seedPR(t, repos, "pr-4", "Other", "a1", 3, "r3")
+		merge(t, repos, "pr-3", 4)
+
+		prs, err := repos.PullRequest.GetOpenPRsByReviewers(ctx, []string{"r1", "r2"})
+		if err != nil {
+			t.Fatalf("GetOpenPRsByReviewers failed: %v", err)
+		}
+		assertIDs(t, prIDs(prs), []string{"pr-1", "pr-2"})
+		if len(prs) == 2 {
+			assertIDs(t, prs[1].AssignedReviewers, []string{"r1", "r2"})
+		}
+
+		prs, err = repos.PullRequest.GetOpenPRsByReviewers(ctx, []string{"a1"})
+		if err != nil {
+			t.Fatalf("GetOpenPRsByReviewers failed: %v", err)
+		}
+		assertIDs(t, prIDs(prs), []string{})
+
+		prs, err = repos.PullRequest.GetOpenPRsByReviewersForUpdate(ctx, []string{"r2", "r3"})
+		if err != nil {
+			t.Fatalf("GetOpenPRsByReviewersForUpdate failed: %v", err)
+		}
+		assertIDs(t, prIDs(prs), []string{"pr-2", "pr-4"})
+		if len(prs) == 2 {
+			assertIDs(t, prs[0].AssignedReviewers, []string{"r1", "r2"})
+		}
+	})
+
+	t.Run("ReassignReviewersInBatch", func(t *testing.T) {
+		repos := newRepos(t)
+		seedReviewTeam(t, repos)
+		seedPR(t, repos, "pr-1", "Replaced", "a1", 0, "r1")
+		seedPR(t, repos, "pr-2", "Dropped", "a1", 1, "r1", "r2")
+		seedPR(t, repos, "pr-3", "Untouched", "a1", 2, "r1")
+
+		err := repos.PullRequest.ReassignReviewersInBatch(ctx, "r1", map[string]string{
+			"pr-1": "r3",
+			"pr-2": "",
+		})
+		if err != nil {
+			t.Fatalf("ReassignReviewersInBatch failed: %v", err)
+		}
+
+		for prID, want := range map[string][]string{
+			"pr-1": {"r3"},
+			"pr-2": {"r2"},
+			"pr-3": {"r1"},
+		} {
+			reviewers, err := repos.PullRequest.GetReviewers(ctx, prID)
+			if err != nil {
+				t.Fatalf("GetReviewers failed: %v", err)
+			}
+			assertIDs(t, reviewers, want)
+		}
+	})
+
+	t.Run("BumpVersions", func(t *testing.T) {
+		repos := newRepos(t)
+		seedReviewTeam(t, repos)
+		seedPR(t, repos, "pr-1", "Bumped", "a1", 0, "r1")
+		seedPR(t, repos, "pr-2", "Bumped twice", "a1", 1)
+		seedPR(t, repos, "pr-3", "Untouched", "a1", 2)
+		merge(t, repos, "pr-2", 3)
+
+		versions, err := repos.PullRequest.BumpVersions(ctx, []string{"pr-1", "pr-2", "missing"})
+		if err != nil {
+			t.Fatalf("BumpVersions failed: %v", err)
+		}
+		want := map[string]int{"pr-1": 2, "pr-2": 3}
+		if !reflect.DeepEqual(versions, want) {
+			t.Errorf("Expected versions %v, got %v", want, versions)
+		}
+
+		for prID, version := range map[string]int{"pr-1": 2, "pr-2": 3, "pr-3": 1} {
+			pr, err := repos.PullRequest.Get(ctx, prID)
+			if err != nil {
+				t.Fatalf("Get failed: %v", err)
+			}
+			if pr.Version != version {
+				t.Errorf("Expected %s at version %d, got %d", prID, version, pr.Version)
+			}
+		}
+	})
+}
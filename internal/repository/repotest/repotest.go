@@ -0,0 +1,133 @@
+// Package repotest is a conformance suite for implementations of the team,
+// user and pull request repositories. Every backend runs it from its tests,
+// so the services see the same behavior whichever storage is configured.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"pr-review-service/internal/domain"
+	"pr-review-service/internal/repository"
+)
+
+// Factory returns repositories over a new, empty store. Only Team, User and
+// PullRequest are exercised.
+type Factory func(t *testing.T) repository.Repository
+
+// Run exercises every method of the team, user and pull request
+// repositories, each subtest on a store of its own.
+func Run(t *testing.T, newRepos Factory) {
+	t.Run("Team", func(t *testing.T) { testTeams(t, newRepos) })
+	t.Run("User", func(t *testing.T) { testUsers(t, newRepos) })
+	t.Run("PullRequest", func(t *testing.T) { testPullRequests(t, newRepos) })
+}
+
+// epoch is the creation time of fixture PRs. It has microsecond precision,
+// like the timestamps Postgres stores.
+var epoch = time.Date(2024, time.March, 4, 10, 0, 0, 0, time.UTC)
+
+func timeAt(hoursAfter int) *time.Time {
+	t := epoch.Add(time.Duration(hoursAfter) * time.Hour)
+	return &t
+}
+
+func user(id, username, teamName string, isActive bool) domain.User {
+	return domain.User{UserID: id, Username: username, TeamName: teamName, IsActive: isActive}
+}
+
+// seedTeam creates teamName with the given members.
+func seedTeam(t *testing.T, repos repository.Repository, teamName string, members ...domain.User) {
+	t.Helper()
+
+	ctx := context.Background()
+	if err := repos.Team.Create(ctx, &domain.Team{TeamName: teamName}); err != nil {
+		t.Fatalf("Failed to create team %s: %v", teamName, err)
+	}
+	for i := range members {
+		if err := repos.User.Create(ctx, &members[i]); err != nil {
+			t.Fatalf("Failed to create user %s: %v", members[i].UserID, err)
+		}
+	}
+}
+
+// seedPR creates an open PR created hoursAfter epoch.
+func seedPR(t *testing.T, repos repository.Repository, prID, name, authorID string, hoursAfter int, reviewers ...string) *domain.PullRequest {
+	t.Helper()
+
+	pr := &domain.PullRequest{
+		PullRequestID:     prID,
+		PullRequestName:   name,
+		AuthorID:          authorID,
+		Status:            domain.PRStatusOpen,
+		AssignedReviewers: reviewers,
+		CreatedAt:         timeAt(hoursAfter),
+	}
+	if err := repos.PullRequest.Create(context.Background(), pr); err != nil {
+		t.Fatalf("Failed to create PR %s: %v", prID, err)
+	}
+	return pr
+}
+
+// merge marks prID merged hoursAfter epoch.
+func merge(t *testing.T, repos repository.Repository, prID string, hoursAfter int) {
+	t.Helper()
+
+	ctx := context.Background()
+	pr, err := repos.PullRequest.Get(ctx, prID)
+	if err != nil {
+		t.Fatalf("Failed to get PR %s: %v", prID, err)
+	}
+	pr.Status = domain.PRStatusMerged
+	pr.MergedAt = timeAt(hoursAfter)
+	if err := repos.PullRequest.Update(ctx, pr); err != nil {
+		t.Fatalf("Failed to merge PR %s: %v", prID, err)
+	}
+}
+
+func assertErr(t *testing.T, err, want error) {
+	t.Helper()
+
+	if !errors.Is(err, want) {
+		t.Errorf("Expected %v, got %v", want, err)
+	}
+}
+
+// assertIDs checks got against want, including that an empty result is an
+// empty slice rather than nil.
+func assertIDs(t *testing.T, got, want []string) {
+	t.Helper()
+
+	if got == nil {
+		t.Errorf("Expected %v, got nil", want)
+		return
+	}
+	if len(got) != len(want) || len(want) > 0 && !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func userIDs(users []domain.User) []string {
+	if users == nil {
+		return nil
+	}
+	ids := []string{}
+	for _, u := range users {
+		ids = append(ids, u.UserID)
+	}
+	return ids
+}
+
+func prIDs(prs []domain.PullRequest) []string {
+	if prs == nil {
+		return nil
+	}
+	ids := []string{}
+	for _, pr := range prs {
+		ids = append(ids, pr.PullRequestID)
+	}
+	return ids
+}
@@ -0,0 +1,175 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"pr-review-service/internal/domain"
+)
+
+func testTeams(t *testing.T, newRepos Factory) {
+	ctx := context.Background()
+
+	t.Run("Create and Get", func(t *testing.T) {
+		repos := newRepos(t)
+		seedTeam(t, repos, "empty")
+
+		team, err := repos.Team.Get(ctx, "empty")
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if team.TeamName != "empty" || team.Version != 1 {
+			t.Errorf("Expected team empty at version 1, got %+v", team)
+		}
+		if team.Members == nil || len(team.Members) != 0 {
+			t.Errorf("Expected an empty member list, got %#v", team.Members)
+		}
+
+		assertErr(t, repos.Team.Create(ctx, &domain.Team{TeamName: "empty"}), domain.ErrTeamExists)
+
+		_, err = repos.Team.Get(ctx, "missing")
+		assertErr(t, err, domain.ErrTeamNotFound)
+	})
+
+	t.Run("Exists", func(t *testing.T) {
+		repos := newRepos(t)
+		seedTeam(t, repos, "present")
+
+		for name, want := range map[string]bool{"present": true, "missing": false} {
+			exists, err := repos.Team.Exists(ctx, name)
+			if err != nil {
+				t.Fatalf("Exists failed: %v", err)
+			}
+			if exists != want {
+				t.Errorf("Expected Exists(%s) = %v, got %v", name, want, exists)
+			}
+		}
+	})
+
+	t.Run("Members are ordered by username and ID without deleted users", func(t *testing.T) {
+		repos := newRepos(t)
+		seedTeam(t, repos, "ordered",
+			user("t3", "Zed", "ordered", true),
+			user("t2", "Amy", "ordered", false),
+			user("t1", "Amy", "ordered", true),
+			user("t4", "Bob", "ordered", true),
+		)
+		deletedAt := time.Now()
+		deleted := user("t4", "Bob", "ordered", true)
+		deleted.DeletedAt = &deletedAt
+		if err := repos.User.Update(ctx, &deleted); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+
+		team, err := repos.Team.Get(ctx, "ordered")
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		var ids []string
+		for _, m := range team.Members {
+			ids = append(ids, m.UserID)
+		}
+		assertIDs(t, ids, []string{"t1", "t2", "t3"})
+		if len(team.Members) == 3 && team.Members[1].IsActive {
+			t.Error("Expected t2 to be inactive")
+		}
+	})
+
+	t.Run("DeactivateAll", func(t *testing.T) {
+		repos := newRepos(t)
+		seedTeam(t, repos, "off", user("d1", "One", "off", true), user("d2", "Two", "off", true))
+		seedTeam(t, repos, "on", user("d3", "Three", "on", true))
+
+		if err := repos.Team.DeactivateAll(ctx, "off"); err != nil {
+			t.Fatalf("DeactivateAll failed: %v", err)
+		}
+
+		active, err := repos.User.GetActiveByTeam(ctx, "off")
+		if err != nil {
+			t.Fatalf("GetActiveByTeam failed: %v", err)
+		}
+		assertIDs(t, userIDs(active), []string{})
+
+		active, err = repos.User.GetActiveByTeam(ctx, "on")
+		if err != nil {
+			t.Fatalf("GetActiveByTeam failed: %v", err)
+		}
+		assertIDs(t, userIDs(active), []string{"d3"})
+	})
+
+	t.Run("ActivateAll", func(t *testing.T) {
+		repos := newRepos(t)
+		seedTeam(t, repos, "back",
+			user("b1", "One", "back", false),
+			user("b2", "Two", "back", false),
+			user("b3", "Three", "back", false),
+		)
+		seedTeam(t, repos, "away", user("b4", "Four", "away", false))
+
+		deleted, err := repos.User.Get(ctx, "b3")
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		deletedAt := epoch
+		deleted.DeletedAt = &deletedAt
+		if err := repos.User.Update(ctx, deleted); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+
+		if err := repos.Team.ActivateAll(ctx, "back", []string{"b2", "b4"}); err != nil {
+			t.Fatalf("ActivateAll failed: %v", err)
+		}
+		active, err := repos.User.GetActiveByTeam(ctx, "back")
+		if err != nil {
+			t.Fatalf("GetActiveByTeam failed: %v", err)
+		}
+		assertIDs(t, userIDs(active), []string{"b2"})
+
+		if err := repos.Team.ActivateAll(ctx, "back", nil); err != nil {
+			t.Fatalf("ActivateAll failed: %v", err)
+		}
+		active, err = repos.User.GetActiveByTeam(ctx, "back")
+		if err != nil {
+			t.Fatalf("GetActiveByTeam failed: %v", err)
+		}
+		assertIDs(t, userIDs(active), []string{"b1", "b2"})
+
+		for _, id := range []string{"b3", "b4"} {
+			u, err := repos.User.Get(ctx, id)
+			if err != nil {
+				t.Fatalf("Get failed: %v", err)
+			}
+			if u.IsActive {
+				t.Errorf("Expected %s to stay inactive", id)
+			}
+		}
+	})
+
+	t.Run("BumpVersion", func(t *testing.T) {
+		repos := newRepos(t)
+		seedTeam(t, repos, "versioned")
+
+		version, err := repos.Team.BumpVersion(ctx, "versioned", 0)
+		if err != nil || version != 2 {
+			t.Fatalf("Expected version 2, got %d (%v)", version, err)
+		}
+		version, err = repos.Team.BumpVersion(ctx, "versioned", 2)
+		if err != nil || version != 3 {
+			t.Fatalf("Expected version 3, got %d (%v)", version, err)
+		}
+
+		_, err = repos.Team.BumpVersion(ctx, "versioned", 2)
+		assertErr(t, err, domain.ErrVersionMismatch)
+		_, err = repos.Team.BumpVersion(ctx, "missing", 0)
+		assertErr(t, err, domain.ErrTeamNotFound)
+
+		team, err := repos.Team.Get(ctx, "versioned")
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if team.Version != 3 {
+			t.Errorf("Expected a failed bump to keep version 3, got %d", team.Version)
+		}
+	})
+}
@@ -0,0 +1,266 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"pr-review-service/internal/domain"
+)
+
+func testUsers(t *testing.T, newRepos Factory) {
+	ctx := context.Background()
+
+	t.Run("Create upserts and Get", func(t *testing.T) {
+		repos := newRepos(t)
+		seedTeam(t, repos, "first", user("u1", "Old Name", "first", true))
+		seedTeam(t, repos, "second")
+
+		moved := user("u1", "New Name", "second", false)
+		if err := repos.User.Create(ctx, &moved); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+
+		got, err := repos.User.Get(ctx, "u1")
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if got.Username != "New Name" || got.TeamName != "second" || got.IsActive || got.DeletedAt != nil {
+			t.Errorf("Expected the second Create to overwrite the user, got %+v", got)
+		}
+
+		_, err = repos.User.Get(ctx, "missing")
+		assertErr(t, err, domain.ErrUserNotFound)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		repos := newRepos(t)
+		seedTeam(t, repos, "team", user("u1", "Name", "team", true))
+
+		deletedAt := time.Now().UTC().Truncate(time.Microsecond)
+		updated := user("u1", "Renamed", "team", false)
+		updated.DeletedAt = &deletedAt
+		if err := repos.User.Update(ctx, &updated); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+
+		got, err := repos.User.Get(ctx, "u1")
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if got.Username != "Renamed" || got.IsActive || got.DeletedAt == nil || !got.DeletedAt.Equal(deletedAt) {
+			t.Errorf("Expected the update to be stored, got %+v", got)
+		}
+
+		missing := user("missing", "Nobody", "team", true)
+		if err := repos.User.Update(ctx, &missing); err != nil {
+			t.Errorf("Expected updating a missing user to be a no-op, got %v", err)
+		}
+		_, err = repos.User.Get(ctx, "missing")
+		assertErr(t, err, domain.ErrUserNotFound)
+	})
+
+	t.Run("GetByTeam and GetActiveByTeam", func(t *testing.T) {
+		repos := newRepos(t)
+		seedTeam(t, repos, "team",
+			user("u3", "Carol", "team", true),
+			user("u2", "Bob", "team", false),
+			user("u1", "Alice", "team", true),
+		)
+		seedTeam(t, repos, "other", user("u4", "Dave", "other", true))
+
+		all, err := repos.User.GetByTeam(ctx, "team")
+		if err != nil {
+			t.Fatalf("GetByTeam failed: %v", err)
+		}
+		assertIDs(t, userIDs(all), []string{"u1", "u2", "u3"})
+
+		active, err := repos.User.GetActiveByTeam(ctx, "team")
+		if err != nil {
+			t.Fatalf("GetActiveByTeam failed: %v", err)
+		}
+		assertIDs(t, userIDs(active), []string{"u1", "u3"})
+
+		none, err := repos.User.GetByTeam(ctx, "missing")
+		if err != nil {
+			t.Fatalf("GetByTeam failed: %v", err)
+		}
+		assertIDs(t, userIDs(none), []string{})
+	})
+
+	t.Run("GetByIDs and GetActiveByTeams", func(t *testing.T) {
+		repos := newRepos(t)
+		seedTeam(t, repos, "team",
+			user("u3", "Carol", "team", true),
+			user("u2", "Bob", "team", false),
+			user("u1", "Alice", "team", true),
+		)
+		seedTeam(t, repos, "other", user("u4", "Dave", "other", true))
+		seedTeam(t, repos, "idle", user("u5", "Eve", "idle", false))
+
+		users, err := repos.User.GetByIDs(ctx, []string{"u2", "u4", "missing"})
+		if err != nil {
+			t.Fatalf("GetByIDs failed: %v", err)
+		}
+		if len(users) != 2 || users["u2"].Username != "Bob" || users["u4"].TeamName != "other" {
+			t.Errorf("Expected u2 and u4, got %+v", users)
+		}
+
+		byTeam, err := repos.User.GetActiveByTeams(ctx, []string{"team", "other", "idle", "missing"})
+		if err != nil {
+			t.Fatalf("GetActiveByTeams failed: %v", err)
+		}
+		if len(byTeam) != 2 {
+			t.Errorf("Expected only teams with active members, got %+v", byTeam)
+		}
+		assertIDs(t, userIDs(byTeam["team"]), []string{"u1", "u3"})
+		assertIDs(t, userIDs(byTeam["other"]), []string{"u4"})
+	})
+
+	t.Run("SetIsActive", func(t *testing.T) {
+		repos := newRepos(t)
+		seedTeam(t, repos, "team", user("u1", "Name", "team", true))
+
+		got, err := repos.User.SetIsActive(ctx, "u1", false)
+		if err != nil {
+			t.Fatalf("SetIsActive failed: %v", err)
+		}
+		if got.UserID != "u1" || got.IsActive {
+			t.Errorf("Expected an inactive u1, got %+v", got)
+		}
+		stored, err := repos.User.Get(ctx, "u1")
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if stored.IsActive {
+			t.Error("Expected the flag to be stored")
+		}
+
+		_, err = repos.User.SetIsActive(ctx, "missing", true)
+		assertErr(t, err, domain.ErrUserNotFound)
+	})
+
+	t.Run("GetStats and StreamStats", func(t *testing.T) {
+		repos := newRepos(t)
+		seedTeam(t, repos, "team",
+			user("u1", "Author", "team", true),
+			user("u2", "Busy", "team", true),
+			user("u3", "Idle", "team", true),
+			user("u4", "Also Busy", "team", true),
+		)
+		seedPR(t, repos, "pr-1", "One", "u1", 0, "u2", "u4")
+		seedPR(t, repos, "pr-2", "Two", "u1", 1, "u2")
+
+		stats, err := repos.User.GetStats(ctx, domain.UserStatsFilter{Limit: 3})
+		if err != nil {
+			t.Fatalf("GetStats failed: %v", err)
+		}
+		var ids []string
+		for _, s := range stats {
+			ids = append(ids, s.UserID)
+		}
+		assertIDs(t, ids, []string{"u2", "u4", "u1"})
+		if len(stats) == 3 && (stats[0].ReviewCount != 2 || stats[1].ReviewCount != 1 || stats[2].ReviewCount != 0) {
+			t.Errorf("Expected review counts 2, 1, 0, got %+v", stats)
+		}
+
+		future := time.Now().UTC().Add(time.Hour)
+		stats, err = repos.User.GetStats(ctx, domain.UserStatsFilter{From: &future, Limit: 10})
+		if err != nil {
+			t.Fatalf("GetStats failed: %v", err)
+		}
+		if len(stats) != 4 {
+			t.Errorf("Expected every user outside the window, got %+v", stats)
+		}
+		for _, s := range stats {
+			if s.ReviewCount != 0 {
+				t.Errorf("Expected no reviews after %v, got %+v", future, s)
+			}
+		}
+
+		var streamed int
+		err = repos.User.StreamStats(ctx, domain.UserStatsFilter{}, func(*domain.UserStats) error {
+			streamed++
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("StreamStats failed: %v", err)
+		}
+		if streamed != 4 {
+			t.Errorf("Expected a zero limit to stream all 4 users, got %d", streamed)
+		}
+
+		errStop := errors.New("stop")
+		err = repos.User.StreamStats(ctx, domain.UserStatsFilter{}, func(*domain.UserStats) error { return errStop })
+		assertErr(t, err, errStop)
+	})
+
+	t.Run("List", func(t *testing.T) {
+		repos := newRepos(t)
+		seedTeam(t, repos, "team",
+			user("u1", "alpha", "team", true),
+			user("u2", "alpha", "team", false),
+			user("u3", "beta", "team", true),
+			user("u4", "aardvark", "team", true),
+		)
+		seedTeam(t, repos, "other", user("u5", "alpine", "other", true))
+
+		list := func(filter domain.UserFilter) []string {
+			t.Helper()
+			users, err := repos.User.List(ctx, filter)
+			if err != nil {
+				t.Fatalf("List failed: %v", err)
+			}
+			return userIDs(users)
+		}
+
+		assertIDs(t, list(domain.UserFilter{Limit: 10}), []string{"u4", "u1", "u2", "u5", "u3"})
+		assertIDs(t, list(domain.UserFilter{TeamName: "team", Limit: 10}), []string{"u4", "u1", "u2", "u3"})
+		active := true
+		assertIDs(t, list(domain.UserFilter{TeamName: "team", IsActive: &active, Limit: 10}), []string{"u4", "u1", "u3"})
+		assertIDs(t, list(domain.UserFilter{NamePrefix: "alp", Limit: 10}), []string{"u1", "u2", "u5"})
+		assertIDs(t, list(domain.UserFilter{Limit: 2}), []string{"u4", "u1"})
+		assertIDs(t, list(domain.UserFilter{After: &domain.UserPageKey{Username: "alpha", UserID: "u1"}, Limit: 2}), []string{"u2", "u5"})
+		assertIDs(t, list(domain.UserFilter{TeamName: "missing", Limit: 10}), []string{})
+	})
+
+	t.Run("List matches the name prefix literally", func(t *testing.T) {
+		repos := newRepos(t)
+		seedTeam(t, repos, "team", user("u1", "a_b", "team", true), user("u2", "axb", "team", true))
+
+		users, err := repos.User.List(ctx, domain.UserFilter{NamePrefix: "a_", Limit: 10})
+		if err != nil {
+			t.Fatalf("List failed: %v", err)
+		}
+		assertIDs(t, userIDs(users), []string{"u1"})
+	})
+
+	t.Run("GetActivity", func(t *testing.T) {
+		repos := newRepos(t)
+		seedTeam(t, repos, "team",
+			user("u1", "Author", "team", true),
+			user("u2", "Reviewer", "team", true),
+		)
+		seedPR(t, repos, "pr-open", "Open", "u1", 0, "u2")
+		seedPR(t, repos, "pr-merged", "Merged", "u1", 1, "u2")
+		merge(t, repos, "pr-merged", 2)
+
+		activity, err := repos.User.GetActivity(ctx, []string{"u1", "u2", "missing"})
+		if err != nil {
+			t.Fatalf("GetActivity failed: %v", err)
+		}
+		if len(activity) != 3 {
+			t.Fatalf("Expected activity for all 3 IDs, got %+v", activity)
+		}
+		if a := activity["u1"]; a.AuthoredOpenPRCount != 1 || a.OpenReviewCount != 0 {
+			t.Errorf("Expected u1 to author 1 open PR, got %+v", a)
+		}
+		if a := activity["u2"]; a.OpenReviewCount != 1 || a.AuthoredOpenPRCount != 0 {
+			t.Errorf("Expected u2 to review 1 open PR, got %+v", a)
+		}
+		if a := activity["missing"]; a != (domain.UserActivity{}) {
+			t.Errorf("Expected zero activity for an unknown user, got %+v", a)
+		}
+	})
+}
@@ -0,0 +1,155 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"pr-review-service/internal/domain"
+	"strings"
+)
+
+type AuditRepo struct {
+	db DBTX
+}
+
+func NewAuditRepo(db *sql.DB) *AuditRepo {
+	return &AuditRepo{db: db}
+}
+
+func (r *AuditRepo) Append(ctx context.Context, event *domain.AuditEvent) error {
+	return r.db.QueryRowContext(ctx, `
+		INSERT INTO audit_events (actor, action, entity_type, entity_id, before, after, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		RETURNING id, created_at`,
+		event.Actor, event.Action, event.EntityType, event.EntityID, nullableJSON(event.Before), nullableJSON(event.After), now()).
+		Scan(&event.ID, timeColumn{&event.CreatedAt})
+}
+
+func (r *AuditRepo) AppendBatch(ctx context.Context, events []domain.AuditEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	batch, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO audit_events (actor, action, entity_type, entity_id, before, after, created_at)
+		SELECT value ->> '$.actor', value ->> '$.action', value ->> '$.entity_type', value ->> '$.entity_id',
+		       value -> '$.before', value -> '$.after', ?
+		FROM json_each(?)
+		ORDER BY key`, now(), string(batch))
+	return err
+}
+
+// RedactUsername rewrites the affected snapshots in Go, since SQLite has no
+// recursive JSON update. The redaction switch it flips for the append-only
+// triggers is a row that lives only as long as the change.
+func (r *AuditRepo) RedactUsername(ctx context.Context, userID, replacement string) error {
+	type snapshots struct {
+		id            int64
+		before, after json.RawMessage
+	}
+
+	return atomic(ctx, r.db, func(db DBTX) error {
+		rows, err := db.QueryContext(ctx, `
+			SELECT e.id, e.before, e.after
+			FROM audit_events e
+			WHERE EXISTS (SELECT 1 FROM json_tree(e.before) WHERE key = 'user_id' AND atom = ?1)
+			   OR EXISTS (SELECT 1 FROM json_tree(e.after) WHERE key = 'user_id' AND atom = ?1)`,
+			userID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		var affected []snapshots
+		for rows.Next() {
+			var s snapshots
+			if err := rows.Scan(&s.id, jsonColumn{&s.before}, jsonColumn{&s.after}); err != nil {
+				return err
+			}
+			affected = append(affected, s)
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		rows.Close()
+
+		if _, err := db.ExecContext(ctx, `INSERT INTO audit_redaction (enabled) VALUES (1)`); err != nil {
+			return err
+		}
+		for _, s := range affected {
+			before, err := domain.RedactUsername(s.before, userID, replacement)
+			if err != nil {
+				return err
+			}
+			after, err := domain.RedactUsername(s.after, userID, replacement)
+			if err != nil {
+				return err
+			}
+			_, err = db.ExecContext(ctx, `UPDATE audit_events SET before = ?, after = ? WHERE id = ?`,
+				nullableJSON(before), nullableJSON(after), s.id)
+			if err != nil {
+				return err
+			}
+		}
+		_, err = db.ExecContext(ctx, `DELETE FROM audit_redaction`)
+		return err
+	})
+}
+
+func (r *AuditRepo) List(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEvent, error) {
+	var conditions []string
+	var args []any
+	addCondition := func(condition string, value any) {
+		conditions = append(conditions, condition)
+		args = append(args, value)
+	}
+
+	if filter.EntityType != "" {
+		addCondition("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		addCondition("entity_id = ?", filter.EntityID)
+	}
+	if filter.Actor != "" {
+		addCondition("actor = ?", filter.Actor)
+	}
+	if filter.From != nil {
+		addCondition("created_at >= ?", formatTime(*filter.From))
+	}
+	if filter.To != nil {
+		addCondition("created_at < ?", formatTime(*filter.To))
+	}
+	if filter.BeforeID > 0 {
+		addCondition("id < ?", filter.BeforeID)
+	}
+
+	query := `
+		SELECT id, actor, action, entity_type, entity_id, before, after, created_at
+		FROM audit_events`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, filter.Limit)
+	query += " ORDER BY id DESC LIMIT ?"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []domain.AuditEvent{}
+	for rows.Next() {
+		var event domain.AuditEvent
+		if err := rows.Scan(&event.ID, &event.Actor, &event.Action, &event.EntityType, &event.EntityID,
+			jsonColumn{&event.Before}, jsonColumn{&event.After}, timeColumn{&event.CreatedAt}); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
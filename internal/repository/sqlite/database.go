@@ -0,0 +1,185 @@
+// Package sqlite implements the repositories on a SQLite database file, for
+// small deployments that do not want to operate Postgres.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	sqlitedriver "modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// timeLayout is how timestamps are stored: fixed-width UTC with microsecond
+// precision like Postgres timestamps, so they compare as strings. Column
+// defaults pad SQLite's millisecond clock to the same width.
+const timeLayout = "2006-01-02 15:04:05.000000"
+
+// connParams make every connection enforce foreign keys, wait for the write
+// lock instead of failing, and take it when a transaction begins, so
+// read-then-write transactions cannot deadlock on the upgrade.
+var connParams = []string{
+	"_pragma=foreign_keys(1)",
+	"_pragma=busy_timeout(10000)",
+	"_pragma=journal_mode(WAL)",
+	"_txlock=immediate",
+}
+
+// Connect opens the database file named by dsn, which is a sqlite: URL such
+// as sqlite:///var/lib/app.db or sqlite:app.db, or a SQLite file: URI.
+func Connect(ctx context.Context, dsn string) (*sql.DB, error) {
+	name := dsn
+	for _, scheme := range []string{"sqlite://", "sqlite3://", "sqlite:", "sqlite3:"} {
+		if strings.HasPrefix(name, scheme) {
+			name = strings.TrimPrefix(name, scheme)
+			break
+		}
+	}
+	if !strings.HasPrefix(name, "file:") {
+		name = "file:" + name
+	}
+	if strings.Contains(name, "?") {
+		name += "&"
+	} else {
+		name += "?"
+	}
+	name += strings.Join(connParams, "&")
+
+	db, err := sql.Open("sqlite", name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	return db, nil
+}
+
+// isUniqueViolation reports whether err is a primary key or unique violation
+// on the given table.column, which lets callers map insert races to domain
+// errors.
+func isUniqueViolation(err error, column string) bool {
+	var sqliteErr *sqlitedriver.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	code := sqliteErr.Code()
+	return (code == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY || code == sqlite3.SQLITE_CONSTRAINT_UNIQUE) &&
+		strings.Contains(sqliteErr.Error(), column)
+}
+
+// formatTime renders t as stored.
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+// now renders the current time as stored. Statements that record when
+// something happened pass it instead of relying on column defaults, whose
+// milliseconds cannot order events that follow each other closely.
+func now() string {
+	return formatTime(time.Now())
+}
+
+// nullableTime renders t as stored, or NULL.
+func nullableTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return formatTime(*t)
+}
+
+// jsonArray binds a list of keys for `IN (SELECT value FROM json_each(?))`,
+// which stands in for Postgres' `= ANY($1)`.
+func jsonArray(values []string) string {
+	if values == nil {
+		values = []string{}
+	}
+	encoded, _ := json.Marshal(values)
+	return string(encoded)
+}
+
+// timeColumn scans a stored timestamp into a time.Time.
+type timeColumn struct {
+	dst *time.Time
+}
+
+func (c timeColumn) Scan(src any) error {
+	t, err := parseTime(src)
+	if err != nil {
+		return err
+	}
+	if t == nil {
+		return errors.New("unexpected NULL timestamp")
+	}
+	*c.dst = *t
+	return nil
+}
+
+// nullTimeColumn scans a nullable stored timestamp into a *time.Time.
+type nullTimeColumn struct {
+	dst **time.Time
+}
+
+func (c nullTimeColumn) Scan(src any) error {
+	t, err := parseTime(src)
+	if err != nil {
+		return err
+	}
+	*c.dst = t
+	return nil
+}
+
+func parseTime(src any) (*time.Time, error) {
+	var s string
+	switch v := src.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return nil, fmt.Errorf("cannot scan %T into a timestamp", src)
+	}
+
+	t, err := time.ParseInLocation(timeLayout, s, time.UTC)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timestamp %q: %w", s, err)
+	}
+	return &t, nil
+}
+
+// jsonColumn scans a nullable JSON text column into a json.RawMessage.
+type jsonColumn struct {
+	dst *json.RawMessage
+}
+
+func (c jsonColumn) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*c.dst = nil
+	case string:
+		*c.dst = json.RawMessage(v)
+	case []byte:
+		*c.dst = append(json.RawMessage(nil), v...)
+	default:
+		return fmt.Errorf("cannot scan %T into JSON", src)
+	}
+	return nil
+}
+
+// nullableJSON binds a snapshot as JSON text, or NULL.
+func nullableJSON(raw json.RawMessage) any {
+	if len(raw) == 0 {
+		return nil
+	}
+	return string(raw)
+}
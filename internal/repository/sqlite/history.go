@@ -0,0 +1,127 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"pr-review-service/internal/domain"
+	"strings"
+)
+
+type AssignmentHistoryRepo struct {
+	db DBTX
+}
+
+func NewAssignmentHistoryRepo(db *sql.DB) *AssignmentHistoryRepo {
+	return &AssignmentHistoryRepo{db: db}
+}
+
+const historyColumns = `h.pull_request_id, h.user_id, h.assigned_at, h.assigned_by, h.assign_reason,
+	h.unassigned_at, COALESCE(h.unassigned_by, ''), COALESCE(h.unassign_reason, '')`
+
+func scanRecord(row interface{ Scan(...any) error }, rec *domain.AssignmentRecord) error {
+	return row.Scan(&rec.PullRequestID, &rec.UserID, timeColumn{&rec.AssignedAt}, &rec.AssignedBy, &rec.AssignReason,
+		nullTimeColumn{&rec.UnassignedAt}, &rec.UnassignedBy, &rec.UnassignReason)
+}
+
+func (r *AssignmentHistoryRepo) RecordAssigned(ctx context.Context, prID, userID string, reason domain.AssignmentReason, actor string) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO pr_assignment_history (pull_request_id, user_id, assigned_by, assign_reason, assigned_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (pull_request_id, user_id) WHERE unassigned_at IS NULL DO NOTHING`,
+		prID, userID, actor, reason, now())
+	return err
+}
+
+func (r *AssignmentHistoryRepo) RecordUnassigned(ctx context.Context, prID, userID string, reason domain.AssignmentReason, actor string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE pr_assignment_history
+		SET unassigned_at = ?, unassigned_by = ?, unassign_reason = ?
+		WHERE pull_request_id = ? AND user_id = ? AND unassigned_at IS NULL`,
+		now(), actor, reason, prID, userID)
+	return err
+}
+
+func (r *AssignmentHistoryRepo) RecordReassignments(ctx context.Context, reassignments []domain.ReviewerReassignment, reason domain.AssignmentReason, actor string) error {
+	changes, err := json.Marshal(reassignments)
+	if err != nil {
+		return err
+	}
+
+	at := now()
+	return atomic(ctx, r.db, func(db DBTX) error {
+		_, err := db.ExecContext(ctx, `
+			UPDATE pr_assignment_history
+			SET unassigned_at = ?, unassigned_by = ?, unassign_reason = ?
+			WHERE unassigned_at IS NULL AND (pull_request_id, user_id) IN (
+				SELECT json_extract(value, '$.pull_request_id'), json_extract(value, '$.old_reviewer_id')
+				FROM json_each(?))`,
+			at, actor, reason, string(changes))
+		if err != nil {
+			return err
+		}
+
+		_, err = db.ExecContext(ctx, `
+			INSERT INTO pr_assignment_history (pull_request_id, user_id, assigned_by, assign_reason, assigned_at)
+			SELECT json_extract(value, '$.pull_request_id'), json_extract(value, '$.new_reviewer_id'), ?, ?, ?
+			FROM json_each(?)
+			WHERE json_extract(value, '$.new_reviewer_id') IS NOT NULL
+			ON CONFLICT (pull_request_id, user_id) WHERE unassigned_at IS NULL DO NOTHING`,
+			actor, reason, at, string(changes))
+		return err
+	})
+}
+
+func (r *AssignmentHistoryRepo) ListByPR(ctx context.Context, prID string) ([]domain.AssignmentRecord, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+historyColumns+`
+		FROM pr_assignment_history h
+		WHERE h.pull_request_id = ?
+		ORDER BY h.assigned_at, h.id`, prID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := []domain.AssignmentRecord{}
+	for rows.Next() {
+		var rec domain.AssignmentRecord
+		if err := scanRecord(rows, &rec); err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+	}
+	return records, rows.Err()
+}
+
+// Stream calls fn for every history entry of the PRs matching filter's row
+// filters, grouped by PR in assignment order.
+func (r *AssignmentHistoryRepo) Stream(ctx context.Context, filter domain.PRFilter, fn func(*domain.AssignmentRecord) error) error {
+	conditions, args := prFilterConditions(filter)
+
+	query := `
+		SELECT ` + historyColumns + `
+		FROM pr_assignment_history h
+		INNER JOIN pull_requests pr ON pr.pull_request_id = h.pull_request_id`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY h.pull_request_id, h.assigned_at, h.id"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rec domain.AssignmentRecord
+		if err := scanRecord(rows, &rec); err != nil {
+			return err
+		}
+		if err := fn(&rec); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,96 @@
+package sqlite
+
+import (
+	"context"
+	"pr-review-service/internal/domain"
+)
+
+// ImportRepo only works inside a unit of work: a bundle is loaded with many
+// inserts that must succeed or fail together.
+type ImportRepo struct {
+	db DBTX
+}
+
+func (r *ImportRepo) ExistingTeams(ctx context.Context, teamNames []string) (map[string]bool, error) {
+	return r.existing(ctx, `SELECT team_name FROM teams WHERE team_name IN (SELECT value FROM json_each(?))`, teamNames)
+}
+
+func (r *ImportRepo) ExistingUsers(ctx context.Context, userIDs []string) (map[string]bool, error) {
+	return r.existing(ctx, `SELECT user_id FROM users WHERE user_id IN (SELECT value FROM json_each(?))`, userIDs)
+}
+
+func (r *ImportRepo) ExistingPullRequests(ctx context.Context, prIDs []string) (map[string]bool, error) {
+	return r.existing(ctx, `
+		SELECT pull_request_id FROM pull_requests
+		WHERE pull_request_id IN (SELECT value FROM json_each(?))`, prIDs)
+}
+
+func (r *ImportRepo) existing(ctx context.Context, query string, keys []string) (map[string]bool, error) {
+	found := make(map[string]bool)
+	if len(keys) == 0 {
+		return found, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, jsonArray(keys))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		found[key] = true
+	}
+	return found, rows.Err()
+}
+
+// Load inserts row by row; SQLite has no COPY, and inserts within one
+// transaction are cheap.
+func (r *ImportRepo) Load(ctx context.Context, bundle *domain.ImportBundle, actor string) error {
+	for _, t := range bundle.Teams {
+		if _, err := r.db.ExecContext(ctx, `INSERT INTO teams (team_name) VALUES (?)`, t.TeamName); err != nil {
+			return err
+		}
+	}
+
+	for _, u := range bundle.Users {
+		_, err := r.db.ExecContext(ctx, `
+			INSERT INTO users (user_id, username, team_name, is_active) VALUES (?, ?, ?, ?)`,
+			u.UserID, u.Username, u.TeamName, *u.IsActive)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, pr := range bundle.PullRequests {
+		_, err := r.db.ExecContext(ctx, `
+			INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status, created_at, merged_at)
+			VALUES (?, ?, ?, ?, ?, ?)`,
+			pr.PullRequestID, pr.PullRequestName, pr.AuthorID, string(pr.Status), formatTime(*pr.CreatedAt), nullableTime(pr.MergedAt))
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, a := range bundle.Assignments {
+		_, err := r.db.ExecContext(ctx, `
+			INSERT INTO pr_reviewers (pull_request_id, user_id, assigned_at) VALUES (?, ?, ?)`,
+			a.PullRequestID, a.UserID, formatTime(*a.AssignedAt))
+		if err != nil {
+			return err
+		}
+
+		_, err = r.db.ExecContext(ctx, `
+			INSERT INTO pr_assignment_history (pull_request_id, user_id, assigned_at, assigned_by, assign_reason)
+			VALUES (?, ?, ?, ?, ?)`,
+			a.PullRequestID, a.UserID, formatTime(*a.AssignedAt), actor, string(domain.AssignmentReasonInitial))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
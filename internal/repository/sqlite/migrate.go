@@ -0,0 +1,96 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+
+	"pr-review-service/internal/repository/migrate"
+)
+
+type migrationDriver struct {
+	db *sql.DB
+}
+
+// NewMigrator loads the migrations found in fsys.
+func NewMigrator(db *sql.DB, fsys fs.FS) (*migrate.Migrator, error) {
+	return migrate.New(&migrationDriver{db: db}, fsys)
+}
+
+// WithLock needs no lock of its own: a SQLite file has a single writer, and
+// Apply and Revert run in immediate transactions that wait for it.
+func (d *migrationDriver) WithLock(ctx context.Context, fn func() error) error {
+	return fn()
+}
+
+func (d *migrationDriver) Applied(ctx context.Context) ([]migrate.Applied, error) {
+	_, err := d.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			kind TEXT NOT NULL,
+			version INTEGER NOT NULL,
+			name TEXT NOT NULL,
+			checksum TEXT NOT NULL,
+			applied_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000'),
+			UNIQUE (kind, version)
+		)
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT kind, version, name, checksum, applied_at
+		FROM schema_migrations
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var applied []migrate.Applied
+	for rows.Next() {
+		var a migrate.Applied
+		if err := rows.Scan(&a.Kind, &a.Version, &a.Name, &a.Checksum, timeColumn{&a.AppliedAt}); err != nil {
+			return nil, err
+		}
+		applied = append(applied, a)
+	}
+
+	return applied, rows.Err()
+}
+
+// Apply records the migration first, so that a concurrent migrator that read
+// the same pending list fails on the unique key instead of running the
+// script twice.
+func (d *migrationDriver) Apply(ctx context.Context, mig *migrate.Migration) error {
+	return atomic(ctx, d.db, func(tx DBTX) error {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO schema_migrations (kind, version, name, checksum)
+			VALUES (?, ?, ?, ?)
+		`, mig.Kind, mig.Version, mig.Name, mig.Checksum)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, mig.Up); err != nil {
+			return fmt.Errorf("failed to execute migration %s: %w", mig, err)
+		}
+		return nil
+	})
+}
+
+func (d *migrationDriver) Revert(ctx context.Context, mig *migrate.Migration) error {
+	return atomic(ctx, d.db, func(tx DBTX) error {
+		if _, err := tx.ExecContext(ctx, mig.Down); err != nil {
+			return fmt.Errorf("failed to roll back migration %s: %w", mig, err)
+		}
+
+		_, err := tx.ExecContext(ctx, `
+			DELETE FROM schema_migrations WHERE kind = ? AND version = ?
+		`, mig.Kind, mig.Version)
+		return err
+	})
+}
@@ -0,0 +1,426 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"pr-review-service/internal/domain"
+	"strings"
+	"time"
+)
+
+type PullRequestRepo struct {
+	db DBTX
+}
+
+func NewPullRequestRepo(db *sql.DB) *PullRequestRepo {
+	return &PullRequestRepo{db: db}
+}
+
+// reviewersColumn aggregates the reviewers of the PR aliased as pr into a
+// JSON array in assignment order.
+const reviewersColumn = `
+	(SELECT json_group_array(user_id) FROM (
+		SELECT prr.user_id FROM pr_reviewers prr
+		WHERE prr.pull_request_id = pr.pull_request_id
+		ORDER BY prr.assigned_at, prr.user_id))`
+
+const prColumns = `pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status, pr.created_at, pr.merged_at, pr.version, ` +
+	`pr.description, pr.url, pr.repository, pr.labels, pr.size, ` +
+	reviewersColumn
+
+func scanPR(row interface{ Scan(...any) error }, pr *domain.PullRequest) error {
+	return row.Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.Status,
+		nullTimeColumn{&pr.CreatedAt}, nullTimeColumn{&pr.MergedAt}, &pr.Version,
+		&pr.Description, &pr.URL, &pr.Repository, stringsColumn{&pr.Labels}, &pr.Size, stringsColumn{&pr.AssignedReviewers})
+}
+
+// stringsColumn scans a JSON array of strings.
+type stringsColumn struct {
+	dst *[]string
+}
+
+func (c stringsColumn) Scan(src any) error {
+	var raw []byte
+	switch v := src.(type) {
+	case string:
+		raw = []byte(v)
+	case []byte:
+		raw = v
+	default:
+		return fmt.Errorf("cannot scan %T into a string list", src)
+	}
+
+	values := []string{}
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return err
+	}
+	*c.dst = values
+	return nil
+}
+
+func (r *PullRequestRepo) Create(ctx context.Context, pr *domain.PullRequest) error {
+	if pr.CreatedAt == nil {
+		createdAt := time.Now()
+		pr.CreatedAt = &createdAt
+	}
+
+	// Reviewers assigned together share a timestamp, like NOW() within a
+	// Postgres transaction, and are ordered by ID.
+	assignedAt := now()
+	return atomic(ctx, r.db, func(db DBTX) error {
+		_, err := db.ExecContext(ctx, `
+			INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status, created_at,
+				description, url, repository, labels, size)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			pr.PullRequestID, pr.PullRequestName, pr.AuthorID, pr.Status, formatTime(*pr.CreatedAt),
+			pr.Description, pr.URL, pr.Repository, jsonArray(pr.Labels), pr.Size)
+		if err != nil {
+			if isUniqueViolation(err, "pull_requests.pull_request_id") {
+				return domain.ErrPRExists
+			}
+			return err
+		}
+
+		for _, reviewerID := range pr.AssignedReviewers {
+			_, err = db.ExecContext(ctx, `
+				INSERT INTO pr_reviewers (pull_request_id, user_id, assigned_at)
+				VALUES (?, ?, ?)`, pr.PullRequestID, reviewerID, assignedAt)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *PullRequestRepo) Get(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	pr := &domain.PullRequest{}
+	err := scanPR(r.db.QueryRowContext(ctx, `
+		SELECT `+prColumns+`
+		FROM pull_requests pr WHERE pr.pull_request_id = ?`, prID), pr)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrPRNotFound
+		}
+		return nil, err
+	}
+	return pr, nil
+}
+
+// GetForUpdate needs no row lock: transactions begin immediate, so the
+// surrounding one already holds the database write lock.
+func (r *PullRequestRepo) GetForUpdate(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	return r.Get(ctx, prID)
+}
+
+func (r *PullRequestRepo) Update(ctx context.Context, pr *domain.PullRequest) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE pull_requests
+		SET pull_request_name = ?, author_id = ?, status = ?, merged_at = ?,
+			description = ?, url = ?, repository = ?, labels = ?, size = ?, version = version + 1
+		WHERE pull_request_id = ? AND version = ?`,
+		pr.PullRequestName, pr.AuthorID, pr.Status, nullableTime(pr.MergedAt),
+		pr.Description, pr.URL, pr.Repository, jsonArray(pr.Labels), pr.Size, pr.PullRequestID, pr.Version)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		exists, err := r.Exists(ctx, pr.PullRequestID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return domain.ErrPRNotFound
+		}
+		return domain.ErrVersionMismatch
+	}
+
+	pr.Version++
+	return nil
+}
+
+func (r *PullRequestRepo) Exists(ctx context.Context, prID string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM pull_requests WHERE pull_request_id = ?)`, prID).
+		Scan(&exists)
+	return exists, err
+}
+
+func (r *PullRequestRepo) GetByReviewer(ctx context.Context, userID string) ([]domain.PullRequestShort, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status
+		FROM pull_requests pr
+		INNER JOIN pr_reviewers prr ON pr.pull_request_id = prr.pull_request_id
+		WHERE prr.user_id = ?
+		ORDER BY pr.created_at DESC, pr.pull_request_id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prs := []domain.PullRequestShort{}
+	for rows.Next() {
+		var pr domain.PullRequestShort
+		if err := rows.Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.Status); err != nil {
+			return nil, err
+		}
+		prs = append(prs, pr)
+	}
+	return prs, rows.Err()
+}
+
+// prSortColumns maps sort fields to SQL expressions and the parser of their
+// text page keys. Open PRs have no merged_at and sort as 'infinity', which
+// compares above every stored timestamp.
+var prSortColumns = map[domain.PRSortField]struct {
+	expr  string
+	parse func(s string) (any, error)
+}{
+	domain.PRSortCreatedAt: {"pr.created_at", parseTimeSortValue},
+	domain.PRSortMergedAt:  {"COALESCE(pr.merged_at, 'infinity')", parseTimeSortValue},
+	domain.PRSortName:      {"pr.pull_request_name", func(s string) (any, error) { return s, nil }},
+}
+
+func parseTimeSortValue(s string) (any, error) {
+	if s == "infinity" {
+		return s, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return nil, domain.ErrInvalidCursor
+	}
+	return formatTime(t), nil
+}
+
+func (r *PullRequestRepo) List(ctx context.Context, filter domain.PRFilter) ([]domain.PullRequest, error) {
+	prs := []domain.PullRequest{}
+	err := r.Stream(ctx, filter, func(pr *domain.PullRequest) error {
+		prs = append(prs, *pr)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return prs, nil
+}
+
+func (r *PullRequestRepo) Stream(ctx context.Context, filter domain.PRFilter, fn func(*domain.PullRequest) error) error {
+	sortColumn, ok := prSortColumns[filter.SortBy]
+	if !ok {
+		return domain.ErrInvalidSort
+	}
+
+	conditions, args := prFilterConditions(filter)
+
+	direction, comparison := "ASC", ">"
+	if filter.Descending {
+		direction, comparison = "DESC", "<"
+	}
+	if filter.After != nil {
+		after, err := sortColumn.parse(filter.After.SortValue)
+		if err != nil {
+			return err
+		}
+		args = append(args, after, filter.After.PullRequestID)
+		conditions = append(conditions, fmt.Sprintf("(%s, pr.pull_request_id) %s (?, ?)", sortColumn.expr, comparison))
+	}
+
+	query := `SELECT ` + prColumns + ` FROM pull_requests pr`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY %s %s, pr.pull_request_id %s", sortColumn.expr, direction, direction)
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += " LIMIT ?"
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pr domain.PullRequest
+		if err := scanPR(rows, &pr); err != nil {
+			return err
+		}
+		if err := fn(&pr); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// prFilterConditions turns the row filters of filter into SQL conditions on
+// pull_requests aliased as pr. Sorting and paging are left to the caller.
+func prFilterConditions(filter domain.PRFilter) ([]string, []any) {
+	var conditions []string
+	var args []any
+	addCondition := func(condition string, value any) {
+		conditions = append(conditions, condition)
+		args = append(args, value)
+	}
+
+	if filter.Status != "" {
+		addCondition("pr.status = ?", filter.Status)
+	}
+	if filter.AuthorID != "" {
+		addCondition("pr.author_id = ?", filter.AuthorID)
+	}
+	if filter.ReviewerID != "" {
+		addCondition(`EXISTS (SELECT 1 FROM pr_reviewers prr
+			WHERE prr.pull_request_id = pr.pull_request_id AND prr.user_id = ?)`, filter.ReviewerID)
+	}
+	if filter.TeamName != "" {
+		addCondition(`EXISTS (SELECT 1 FROM users au
+			WHERE au.user_id = pr.author_id AND au.team_name = ?)`, filter.TeamName)
+	}
+	if filter.CreatedFrom != nil {
+		addCondition("pr.created_at >= ?", formatTime(*filter.CreatedFrom))
+	}
+	if filter.CreatedTo != nil {
+		addCondition("pr.created_at < ?", formatTime(*filter.CreatedTo))
+	}
+	if filter.MergedFrom != nil {
+		addCondition("pr.merged_at >= ?", formatTime(*filter.MergedFrom))
+	}
+	if filter.MergedTo != nil {
+		addCondition("pr.merged_at < ?", formatTime(*filter.MergedTo))
+	}
+
+	return conditions, args
+}
+
+func (r *PullRequestRepo) AssignReviewer(ctx context.Context, prID, userID string) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO pr_reviewers (pull_request_id, user_id, assigned_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (pull_request_id, user_id) DO NOTHING`,
+		prID, userID, now())
+	return err
+}
+
+func (r *PullRequestRepo) RemoveReviewer(ctx context.Context, prID, userID string) error {
+	_, err := r.db.ExecContext(ctx, `
+		DELETE FROM pr_reviewers
+		WHERE pull_request_id = ? AND user_id = ?`,
+		prID, userID)
+	return err
+}
+
+func (r *PullRequestRepo) GetReviewers(ctx context.Context, prID string) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT user_id FROM pr_reviewers
+		WHERE pull_request_id = ?
+		ORDER BY assigned_at, user_id`, prID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reviewers := []string{}
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		reviewers = append(reviewers, userID)
+	}
+	return reviewers, rows.Err()
+}
+
+func (r *PullRequestRepo) IsReviewer(ctx context.Context, prID, userID string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS(SELECT 1 FROM pr_reviewers WHERE pull_request_id = ? AND user_id = ?)`,
+		prID, userID).Scan(&exists)
+	return exists, err
+}
+
+func (r *PullRequestRepo) GetOpenPRsByReviewers(ctx context.Context, userIDs []string) ([]domain.PullRequest, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+prColumns+`
+		FROM pull_requests pr
+		WHERE pr.status = 'OPEN' AND EXISTS (
+			SELECT 1 FROM pr_reviewers prr
+			WHERE prr.pull_request_id = pr.pull_request_id
+			  AND prr.user_id IN (SELECT value FROM json_each(?)))
+		ORDER BY pr.pull_request_id`,
+		jsonArray(userIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prs := []domain.PullRequest{}
+	for rows.Next() {
+		var pr domain.PullRequest
+		if err := scanPR(rows, &pr); err != nil {
+			return nil, err
+		}
+		prs = append(prs, pr)
+	}
+	return prs, rows.Err()
+}
+
+// GetOpenPRsByReviewersForUpdate needs no row locks, for the same reason as
+// GetForUpdate.
+func (r *PullRequestRepo) GetOpenPRsByReviewersForUpdate(ctx context.Context, userIDs []string) ([]domain.PullRequest, error) {
+	return r.GetOpenPRsByReviewers(ctx, userIDs)
+}
+
+func (r *PullRequestRepo) ReassignReviewersInBatch(ctx context.Context, oldUserID string, newAssignments map[string]string) error {
+	assignments, err := json.Marshal(newAssignments)
+	if err != nil {
+		return err
+	}
+
+	assignedAt := now()
+	return atomic(ctx, r.db, func(db DBTX) error {
+		_, err := db.ExecContext(ctx, `
+			DELETE FROM pr_reviewers
+			WHERE user_id = ? AND pull_request_id IN (SELECT key FROM json_each(?))`,
+			oldUserID, string(assignments))
+		if err != nil {
+			return err
+		}
+
+		_, err = db.ExecContext(ctx, `
+			INSERT INTO pr_reviewers (pull_request_id, user_id, assigned_at)
+			SELECT key, value, ? FROM json_each(?) WHERE value <> ''
+			ON CONFLICT (pull_request_id, user_id) DO NOTHING`,
+			assignedAt, string(assignments))
+		return err
+	})
+}
+
+func (r *PullRequestRepo) BumpVersions(ctx context.Context, prIDs []string) (map[string]int, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		UPDATE pull_requests SET version = version + 1
+		WHERE pull_request_id IN (SELECT value FROM json_each(?))
+		RETURNING pull_request_id, version`, jsonArray(prIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := make(map[string]int, len(prIDs))
+	for rows.Next() {
+		var prID string
+		var version int
+		if err := rows.Scan(&prID, &version); err != nil {
+			return nil, err
+		}
+		versions[prID] = version
+	}
+	return versions, rows.Err()
+}
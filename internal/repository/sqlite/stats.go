@@ -0,0 +1,203 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"pr-review-service/internal/domain"
+	"time"
+)
+
+type StatsRepo struct {
+	db DBTX
+}
+
+func NewStatsRepo(db *sql.DB) *StatsRepo {
+	return &StatsRepo{db: db}
+}
+
+// SQLite has neither percentile_cont nor date_trunc, so the queries select
+// the timestamps in scope and durations and weeks are computed here. Every
+// query takes the window as ?1 and ?2 and the optional team and user scope as
+// ?3 and ?4, where an empty string means "any".
+
+func (r *StatsRepo) GetReviewMetrics(ctx context.Context, filter domain.MetricsFilter) (*domain.ReviewMetrics, error) {
+	metrics := &domain.ReviewMetrics{
+		TeamName:   filter.TeamName,
+		UserID:     filter.UserID,
+		From:       filter.From,
+		To:         filter.To,
+		Throughput: []domain.WeeklyThroughput{},
+	}
+	args := []any{formatTime(filter.From), formatTime(filter.To), filter.TeamName, filter.UserID}
+
+	weeks := make(map[time.Time]*domain.WeeklyThroughput)
+	for week := startOfWeek(filter.From); week.Before(filter.To); week = week.AddDate(0, 0, 7) {
+		metrics.Throughput = append(metrics.Throughput, domain.WeeklyThroughput{WeekStart: week})
+	}
+	for i := range metrics.Throughput {
+		weeks[metrics.Throughput[i].WeekStart] = &metrics.Throughput[i]
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT pr.created_at, pr.merged_at,
+		       (SELECT MIN(h.assigned_at) FROM pr_assignment_history h
+		        WHERE h.pull_request_id = pr.pull_request_id)
+		FROM pull_requests pr
+		INNER JOIN users u ON u.user_id = pr.author_id
+		WHERE ((pr.created_at >= ?1 AND pr.created_at < ?2) OR (pr.merged_at >= ?1 AND pr.merged_at < ?2))
+		  AND (?3 = '' OR u.team_name = ?3)
+		  AND (?4 = '' OR pr.author_id = ?4)`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	inWindow := func(t time.Time) bool {
+		return !t.Before(filter.From) && t.Before(filter.To)
+	}
+	var toMerge, toFirstReview []float64
+	for rows.Next() {
+		var createdAt time.Time
+		var mergedAt, firstAssignedAt *time.Time
+		if err := rows.Scan(timeColumn{&createdAt}, nullTimeColumn{&mergedAt}, nullTimeColumn{&firstAssignedAt}); err != nil {
+			return nil, err
+		}
+
+		if inWindow(createdAt) {
+			if week, ok := weeks[startOfWeek(createdAt)]; ok {
+				week.Opened++
+			}
+			if firstAssignedAt != nil {
+				toFirstReview = append(toFirstReview, firstAssignedAt.Sub(createdAt).Seconds())
+			}
+		}
+		if mergedAt != nil && inWindow(*mergedAt) {
+			if week, ok := weeks[startOfWeek(*mergedAt)]; ok {
+				week.Merged++
+			}
+			toMerge = append(toMerge, mergedAt.Sub(createdAt).Seconds())
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	metrics.TimeToMerge = domain.NewDurationStats(toMerge)
+	metrics.TimeToFirstReview = domain.NewDurationStats(toFirstReview)
+
+	err = r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM pr_assignment_history h
+		INNER JOIN pull_requests pr ON pr.pull_request_id = h.pull_request_id
+		INNER JOIN users u ON u.user_id = h.user_id
+		WHERE h.unassigned_at IS NULL
+		  AND pr.merged_at >= ?1 AND pr.merged_at < ?2
+		  AND (?3 = '' OR u.team_name = ?3)
+		  AND (?4 = '' OR h.user_id = ?4)`, args...).
+		Scan(&metrics.ReviewsCompleted)
+	if err != nil {
+		return nil, err
+	}
+
+	return metrics, nil
+}
+
+// startOfWeek truncates t to Monday midnight UTC, like date_trunc('week', t)
+// does in Postgres.
+func startOfWeek(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+// GetMemberLoad returns the members in team and username order. Active days
+// are the time each member spent active within the window.
+func (r *StatsRepo) GetMemberLoad(ctx context.Context, filter domain.FairnessFilter) ([]domain.MemberLoad, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT u.team_name, u.user_id, u.username,
+		       (SELECT COUNT(*) FROM pr_reviewers r
+		        WHERE r.user_id = u.user_id AND r.assigned_at >= ?1 AND r.assigned_at < ?2)
+		FROM users u
+		WHERE u.deleted_at IS NULL AND (?3 = '' OR u.team_name = ?3)
+		ORDER BY u.team_name, u.username, u.user_id`,
+		formatTime(filter.From), formatTime(filter.To), filter.TeamName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	loads := []domain.MemberLoad{}
+	for rows.Next() {
+		var load domain.MemberLoad
+		if err := rows.Scan(&load.TeamName, &load.UserID, &load.Username, &load.Assignments); err != nil {
+			return nil, err
+		}
+		loads = append(loads, load)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	activeSeconds, err := r.activeSeconds(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	for i := range loads {
+		loads[i].ActiveDays = activeSeconds[loads[i].UserID] / 86400
+	}
+	return loads, nil
+}
+
+// activeSeconds sums the time each user in scope spent active within the
+// window. Each activation change lasts until the user's next one.
+func (r *StatsRepo) activeSeconds(ctx context.Context, filter domain.FairnessFilter) (map[string]float64, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT h.user_id, h.is_active, h.changed_at
+		FROM user_activation_history h
+		INNER JOIN users u ON u.user_id = h.user_id
+		WHERE u.deleted_at IS NULL AND (?1 = '' OR u.team_name = ?1)
+		ORDER BY h.user_id, h.changed_at, h.id`, filter.TeamName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type change struct {
+		userID    string
+		isActive  bool
+		changedAt time.Time
+	}
+	seconds := make(map[string]float64)
+	closeInterval := func(c change, ends time.Time) {
+		starts := c.changedAt
+		if starts.Before(filter.From) {
+			starts = filter.From
+		}
+		if ends.After(filter.To) {
+			ends = filter.To
+		}
+		if c.isActive && ends.After(starts) {
+			seconds[c.userID] += ends.Sub(starts).Seconds()
+		}
+	}
+
+	var prev *change
+	for rows.Next() {
+		var c change
+		if err := rows.Scan(&c.userID, &c.isActive, timeColumn{&c.changedAt}); err != nil {
+			return nil, err
+		}
+		if prev != nil {
+			if prev.userID == c.userID {
+				closeInterval(*prev, c.changedAt)
+			} else {
+				closeInterval(*prev, filter.To)
+			}
+		}
+		prev = &c
+	}
+	if prev != nil {
+		closeInterval(*prev, filter.To)
+	}
+	return seconds, rows.Err()
+}
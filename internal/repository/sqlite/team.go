@@ -0,0 +1,109 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"pr-review-service/internal/domain"
+)
+
+type TeamRepo struct {
+	db DBTX
+}
+
+func NewTeamRepo(db *sql.DB) *TeamRepo {
+	return &TeamRepo{db: db}
+}
+
+func (r *TeamRepo) Create(ctx context.Context, team *domain.Team) error {
+	_, err := r.db.ExecContext(ctx, `INSERT INTO teams (team_name) VALUES (?)`, team.TeamName)
+	if err != nil {
+		if isUniqueViolation(err, "teams.team_name") {
+			return domain.ErrTeamExists
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *TeamRepo) Get(ctx context.Context, teamName string) (*domain.Team, error) {
+	team := &domain.Team{
+		TeamName: teamName,
+		Members:  []domain.TeamMember{},
+	}
+
+	err := r.db.QueryRowContext(ctx, `SELECT version FROM teams WHERE team_name = ?`, teamName).
+		Scan(&team.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrTeamNotFound
+		}
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT user_id, username, is_active
+		FROM users
+		WHERE team_name = ? AND deleted_at IS NULL
+		ORDER BY username, user_id`,
+		teamName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var member domain.TeamMember
+		if err := rows.Scan(&member.UserID, &member.Username, &member.IsActive); err != nil {
+			return nil, err
+		}
+		team.Members = append(team.Members, member)
+	}
+
+	return team, rows.Err()
+}
+
+func (r *TeamRepo) Exists(ctx context.Context, teamName string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM teams WHERE team_name = ?)`, teamName).
+		Scan(&exists)
+	return exists, err
+}
+
+func (r *TeamRepo) DeactivateAll(ctx context.Context, teamName string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE users SET is_active = 0 WHERE team_name = ?`, teamName)
+	return err
+}
+
+func (r *TeamRepo) ActivateAll(ctx context.Context, teamName string, userIDs []string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE users SET is_active = 1
+		WHERE team_name = ?1 AND deleted_at IS NULL
+		  AND (json_array_length(?2) = 0 OR user_id IN (SELECT value FROM json_each(?2)))`,
+		teamName, jsonArray(userIDs))
+	return err
+}
+
+func (r *TeamRepo) BumpVersion(ctx context.Context, teamName string, expectedVersion int) (int, error) {
+	var version int
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE teams SET version = version + 1
+		WHERE team_name = ?1 AND (?2 = 0 OR version = ?2)
+		RETURNING version`,
+		teamName, expectedVersion).Scan(&version)
+	if err == nil {
+		return version, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, err
+	}
+
+	exists, err := r.Exists(ctx, teamName)
+	if err != nil {
+		return 0, err
+	}
+	if !exists {
+		return 0, domain.ErrTeamNotFound
+	}
+	return 0, domain.ErrVersionMismatch
+}
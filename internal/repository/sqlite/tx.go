@@ -0,0 +1,75 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"pr-review-service/internal/repository"
+)
+
+// DBTX is implemented by both *sql.DB and *sql.Tx, so the same repository
+// code runs either directly on the database or inside a transaction.
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type UnitOfWork struct {
+	db *sql.DB
+}
+
+func NewUnitOfWork(db *sql.DB) *UnitOfWork {
+	return &UnitOfWork{db: db}
+}
+
+func (u *UnitOfWork) WithinTx(ctx context.Context, fn func(ctx context.Context, repos repository.Repository) error) error {
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	repos := repository.Repository{
+		Team:        &TeamRepo{db: tx},
+		User:        &UserRepo{db: tx},
+		PullRequest: &PullRequestRepo{db: tx},
+		Audit:       &AuditRepo{db: tx},
+		History:     &AssignmentHistoryRepo{db: tx},
+		Import:      &ImportRepo{db: tx},
+	}
+	if err := fn(ctx, repos); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// atomic runs fn so that its statements apply together: in a new transaction
+// on a *sql.DB, or under a savepoint inside a surrounding transaction, the way
+// pgx nests Begin.
+func atomic(ctx context.Context, db DBTX, fn func(db DBTX) error) error {
+	if sqlDB, ok := db.(*sql.DB); ok {
+		tx, err := sqlDB.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		if err := fn(tx); err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
+
+	if _, err := db.ExecContext(ctx, `SAVEPOINT atomic`); err != nil {
+		return err
+	}
+	if err := fn(db); err != nil {
+		// Rolling back to the savepoint keeps it open, so release it as well.
+		db.ExecContext(ctx, `ROLLBACK TO SAVEPOINT atomic`)
+		db.ExecContext(ctx, `RELEASE SAVEPOINT atomic`)
+		return err
+	}
+	_, err := db.ExecContext(ctx, `RELEASE SAVEPOINT atomic`)
+	return err
+}
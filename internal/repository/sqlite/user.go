@@ -0,0 +1,256 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"pr-review-service/internal/domain"
+	"strings"
+)
+
+type UserRepo struct {
+	db DBTX
+}
+
+func NewUserRepo(db *sql.DB) *UserRepo {
+	return &UserRepo{db: db}
+}
+
+const userColumns = `user_id, username, team_name, is_active, deleted_at`
+
+func scanUser(row interface{ Scan(...any) error }, user *domain.User) error {
+	return row.Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive, nullTimeColumn{&user.DeletedAt})
+}
+
+func (r *UserRepo) Create(ctx context.Context, user *domain.User) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO users (user_id, username, team_name, is_active)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE
+		SET username = excluded.username,
+		    team_name = excluded.team_name,
+		    is_active = excluded.is_active`,
+		user.UserID, user.Username, user.TeamName, user.IsActive)
+	return err
+}
+
+func (r *UserRepo) Update(ctx context.Context, user *domain.User) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE users
+		SET username = ?, team_name = ?, is_active = ?, deleted_at = ?
+		WHERE user_id = ?`,
+		user.Username, user.TeamName, user.IsActive, nullableTime(user.DeletedAt), user.UserID)
+	return err
+}
+
+func (r *UserRepo) Get(ctx context.Context, userID string) (*domain.User, error) {
+	user := &domain.User{}
+	err := scanUser(r.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE user_id = ?`, userID), user)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+func (r *UserRepo) GetByTeam(ctx context.Context, teamName string) ([]domain.User, error) {
+	return r.query(ctx, `
+		SELECT `+userColumns+`
+		FROM users WHERE team_name = ? AND deleted_at IS NULL
+		ORDER BY username, user_id`, teamName)
+}
+
+func (r *UserRepo) GetActiveByTeam(ctx context.Context, teamName string) ([]domain.User, error) {
+	return r.query(ctx, `
+		SELECT `+userColumns+`
+		FROM users WHERE team_name = ? AND is_active = 1 AND deleted_at IS NULL
+		ORDER BY username, user_id`, teamName)
+}
+
+func (r *UserRepo) GetByIDs(ctx context.Context, userIDs []string) (map[string]domain.User, error) {
+	users, err := r.query(ctx, `
+		SELECT `+userColumns+`
+		FROM users WHERE user_id IN (SELECT value FROM json_each(?))`, jsonArray(userIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]domain.User, len(users))
+	for _, user := range users {
+		byID[user.UserID] = user
+	}
+	return byID, nil
+}
+
+func (r *UserRepo) GetActiveByTeams(ctx context.Context, teamNames []string) (map[string][]domain.User, error) {
+	users, err := r.query(ctx, `
+		SELECT `+userColumns+`
+		FROM users
+		WHERE team_name IN (SELECT value FROM json_each(?)) AND is_active = 1 AND deleted_at IS NULL
+		ORDER BY team_name, username, user_id`, jsonArray(teamNames))
+	if err != nil {
+		return nil, err
+	}
+
+	byTeam := make(map[string][]domain.User, len(teamNames))
+	for _, user := range users {
+		byTeam[user.TeamName] = append(byTeam[user.TeamName], user)
+	}
+	return byTeam, nil
+}
+
+func (r *UserRepo) query(ctx context.Context, query string, args ...any) ([]domain.User, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []domain.User{}
+	for rows.Next() {
+		var user domain.User
+		if err := scanUser(rows, &user); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+func (r *UserRepo) SetIsActive(ctx context.Context, userID string, isActive bool) (*domain.User, error) {
+	user, err := r.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	user.IsActive = isActive
+
+	_, err = r.db.ExecContext(ctx, `UPDATE users SET is_active = ? WHERE user_id = ?`,
+		isActive, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (r *UserRepo) GetStats(ctx context.Context, filter domain.UserStatsFilter) ([]domain.UserStats, error) {
+	stats := []domain.UserStats{}
+	err := r.StreamStats(ctx, filter, func(stat *domain.UserStats) error {
+		stats = append(stats, *stat)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (r *UserRepo) StreamStats(ctx context.Context, filter domain.UserStatsFilter, fn func(*domain.UserStats) error) error {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT u.user_id, u.username, COUNT(pr.pull_request_id) AS review_count
+		FROM users u
+		LEFT JOIN pr_reviewers pr ON u.user_id = pr.user_id
+			AND (?2 IS NULL OR pr.assigned_at >= ?2)
+			AND (?3 IS NULL OR pr.assigned_at < ?3)
+		WHERE u.deleted_at IS NULL
+		GROUP BY u.user_id, u.username
+		ORDER BY review_count DESC, u.username, u.user_id
+		LIMIT CASE WHEN ?1 > 0 THEN ?1 ELSE -1 END`,
+		filter.Limit, nullableTime(filter.From), nullableTime(filter.To))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var stat domain.UserStats
+		if err := rows.Scan(&stat.UserID, &stat.Username, &stat.ReviewCount); err != nil {
+			return err
+		}
+		if err := fn(&stat); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (r *UserRepo) List(ctx context.Context, filter domain.UserFilter) ([]domain.User, error) {
+	conditions := []string{"deleted_at IS NULL"}
+	var args []any
+	addCondition := func(condition string, values ...any) {
+		conditions = append(conditions, condition)
+		args = append(args, values...)
+	}
+
+	if filter.TeamName != "" {
+		addCondition("team_name = ?", filter.TeamName)
+	}
+	if filter.IsActive != nil {
+		addCondition("is_active = ?", *filter.IsActive)
+	}
+	if filter.NamePrefix != "" {
+		// LIKE ignores ASCII case in SQLite, unlike in Postgres.
+		addCondition("substr(username, 1, length(?)) = ?", filter.NamePrefix, filter.NamePrefix)
+	}
+	if filter.After != nil {
+		addCondition("(username, user_id) > (?, ?)", filter.After.Username, filter.After.UserID)
+	}
+
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY username, user_id LIMIT ?`
+	args = append(args, filter.Limit)
+
+	return r.query(ctx, query, args...)
+}
+
+func (r *UserRepo) GetActivity(ctx context.Context, userIDs []string) (map[string]domain.UserActivity, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		WITH ids AS (
+			SELECT DISTINCT value AS user_id FROM json_each(?1)
+		),
+		reviews AS (
+			SELECT prr.user_id, COUNT(*) AS cnt
+			FROM pr_reviewers prr
+			INNER JOIN pull_requests pr ON pr.pull_request_id = prr.pull_request_id
+			WHERE pr.status = 'OPEN' AND prr.user_id IN (SELECT user_id FROM ids)
+			GROUP BY prr.user_id
+		),
+		authored AS (
+			SELECT author_id AS user_id, COUNT(*) AS cnt
+			FROM pull_requests
+			WHERE status = 'OPEN' AND author_id IN (SELECT user_id FROM ids)
+			GROUP BY author_id
+		),
+		assigned AS (
+			SELECT user_id, MAX(assigned_at) AS last_assigned_at
+			FROM pr_assignment_history
+			WHERE user_id IN (SELECT user_id FROM ids)
+			GROUP BY user_id
+		)
+		SELECT ids.user_id, COALESCE(reviews.cnt, 0), COALESCE(authored.cnt, 0), assigned.last_assigned_at
+		FROM ids
+		LEFT JOIN reviews USING (user_id)
+		LEFT JOIN authored USING (user_id)
+		LEFT JOIN assigned USING (user_id)`, jsonArray(userIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	activity := make(map[string]domain.UserActivity, len(userIDs))
+	for rows.Next() {
+		var userID string
+		var a domain.UserActivity
+		if err := rows.Scan(&userID, &a.OpenReviewCount, &a.AuthoredOpenPRCount, nullTimeColumn{&a.LastAssignedAt}); err != nil {
+			return nil, err
+		}
+		activity[userID] = a
+	}
+	return activity, rows.Err()
+}
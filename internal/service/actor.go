@@ -0,0 +1,21 @@
+package service
+
+import "context"
+
+// AnonymousActor is recorded when a change is made without an identified actor.
+const AnonymousActor = "anonymous"
+
+type actorKey struct{}
+
+// WithActor returns a context that attributes changes made with it to actor.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor set by WithActor, or AnonymousActor.
+func ActorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return AnonymousActor
+}
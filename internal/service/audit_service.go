@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"pr-review-service/internal/domain"
+	"pr-review-service/internal/repository"
+)
+
+type AuditService struct {
+	auditRepo repository.AuditRepository
+}
+
+func NewAuditService(auditRepo repository.AuditRepository) *AuditService {
+	return &AuditService{
+		auditRepo: auditRepo,
+	}
+}
+
+type auditCursor struct {
+	ID int64 `json:"id"`
+}
+
+// ListEvents returns one page of events matching filter, newest first, and
+// the cursor of the next page ("" on the last page).
+func (s *AuditService) ListEvents(ctx context.Context, filter domain.AuditFilter, cursor string) ([]domain.AuditEvent, string, error) {
+	if cursor != "" {
+		var pos auditCursor
+		if err := decodeCursor(cursor, &pos); err != nil {
+			return nil, "", err
+		}
+		filter.BeforeID = pos.ID
+	}
+
+	pageSize := normalizePageSize(filter.Limit)
+	filter.Limit = pageSize + 1
+
+	events, err := s.auditRepo.List(ctx, filter)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var next string
+	if len(events) > pageSize {
+		events = events[:pageSize]
+		next = encodeCursor(auditCursor{ID: events[pageSize-1].ID})
+	}
+
+	return events, next, nil
+}
+
+// recordAudit appends an event attributed to the actor in ctx. before and
+// after are stored as JSON snapshots of the entity; either may be nil.
+func recordAudit(ctx context.Context, audit repository.AuditRepository, action, entityType, entityID string, before, after interface{}) error {
+	event, err := auditEvent(ctx, action, entityType, entityID, before, after)
+	if err != nil {
+		return err
+	}
+	return audit.Append(ctx, event)
+}
+
+// auditEvent builds the event recordAudit appends, for callers that append
+// several at once.
+func auditEvent(ctx context.Context, action, entityType, entityID string, before, after interface{}) (*domain.AuditEvent, error) {
+	beforeJSON, err := snapshot(before)
+	if err != nil {
+		return nil, err
+	}
+	afterJSON, err := snapshot(after)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.AuditEvent{
+		Actor:      ActorFromContext(ctx),
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Before:     beforeJSON,
+		After:      afterJSON,
+	}, nil
+}
+
+func snapshot(v interface{}) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if string(data) == "null" {
+		return nil, nil
+	}
+	return data, nil
+}
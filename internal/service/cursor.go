@@ -0,0 +1,40 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"pr-review-service/internal/domain"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+// encodeCursor turns the position of the last returned row into an opaque
+// token that clients pass back to fetch the next page.
+func encodeCursor(position interface{}) string {
+	data, _ := json.Marshal(position)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(cursor string, position interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return domain.ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, position); err != nil {
+		return domain.ErrInvalidCursor
+	}
+	return nil
+}
+
+func normalizePageSize(limit int) int {
+	if limit <= 0 {
+		return defaultPageSize
+	}
+	if limit > maxPageSize {
+		return maxPageSize
+	}
+	return limit
+}
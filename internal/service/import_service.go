@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"pr-review-service/internal/domain"
+	"pr-review-service/internal/repository"
+	"time"
+)
+
+type ImportService struct {
+	uow repository.UnitOfWork
+}
+
+func NewImportService(uow repository.UnitOfWork) *ImportService {
+	return &ImportService{uow: uow}
+}
+
+// Import validates the whole bundle against itself and the stored data and
+// then loads it in a single transaction. If any row is invalid nothing is
+// loaded and a *domain.ImportError lists every invalid row.
+func (s *ImportService) Import(ctx context.Context, bundle *domain.ImportBundle) (*domain.ImportSummary, error) {
+	applyImportDefaults(bundle, time.Now())
+
+	err := s.uow.WithinTx(ctx, func(ctx context.Context, repos repository.Repository) error {
+		if err := validateImport(ctx, repos.Import, bundle); err != nil {
+			return err
+		}
+
+		if err := repos.Import.Load(ctx, bundle, ActorFromContext(ctx)); err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, repos.Audit, domain.AuditActionImport, domain.AuditEntityImport, "bundle", nil, importSummary(bundle))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return importSummary(bundle), nil
+}
+
+func importSummary(bundle *domain.ImportBundle) *domain.ImportSummary {
+	return &domain.ImportSummary{
+		Teams:        len(bundle.Teams),
+		Users:        len(bundle.Users),
+		PullRequests: len(bundle.PullRequests),
+		Assignments:  len(bundle.Assignments),
+	}
+}
+
+// applyImportDefaults fills in optional fields and normalizes timestamps to
+// UTC, the zone the database stores them in. A missing created_at defaults to
+// merged_at if there is one and to now otherwise.
+func applyImportDefaults(bundle *domain.ImportBundle, now time.Time) {
+	utc := func(t *time.Time) *time.Time {
+		if t == nil {
+			return nil
+		}
+		u := t.UTC()
+		return &u
+	}
+
+	for i := range bundle.Users {
+		if bundle.Users[i].IsActive == nil {
+			active := true
+			bundle.Users[i].IsActive = &active
+		}
+	}
+
+	createdAt := make(map[string]*time.Time, len(bundle.PullRequests))
+	for i := range bundle.PullRequests {
+		pr := &bundle.PullRequests[i]
+		if pr.Status == "" {
+			pr.Status = domain.PRStatusOpen
+		}
+		pr.CreatedAt = utc(pr.CreatedAt)
+		pr.MergedAt = utc(pr.MergedAt)
+		// Merged PRs without created_at are history from the old tool, so
+		// they cannot have been created now; merged_at is the best guess.
+		if pr.CreatedAt == nil && pr.MergedAt != nil {
+			pr.CreatedAt = pr.MergedAt
+		}
+		if pr.CreatedAt == nil {
+			created := now.UTC()
+			pr.CreatedAt = &created
+		}
+		createdAt[pr.PullRequestID] = pr.CreatedAt
+	}
+
+	for i := range bundle.Assignments {
+		a := &bundle.Assignments[i]
+		a.AssignedAt = utc(a.AssignedAt)
+		if a.AssignedAt == nil {
+			a.AssignedAt = createdAt[a.PullRequestID]
+		}
+	}
+}
+
+// validateImport checks every row and returns a *domain.ImportError with all
+// problems found, so clients can fix a bundle in one go.
+func validateImport(ctx context.Context, repo repository.ImportRepository, bundle *domain.ImportBundle) error {
+	var rowErrors []domain.ImportRowError
+	reject := func(table string, i int, format string, args ...any) {
+		rowErrors = append(rowErrors, domain.ImportRowError{Table: table, Row: i + 1, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// Look up every key the bundle defines or references in one go.
+	var teamNames, userIDs, prIDs []string
+	for _, t := range bundle.Teams {
+		teamNames = append(teamNames, t.TeamName)
+	}
+	for _, u := range bundle.Users {
+		teamNames = append(teamNames, u.TeamName)
+		userIDs = append(userIDs, u.UserID)
+	}
+	for _, pr := range bundle.PullRequests {
+		prIDs = append(prIDs, pr.PullRequestID)
+		userIDs = append(userIDs, pr.AuthorID)
+	}
+	for _, a := range bundle.Assignments {
+		userIDs = append(userIDs, a.UserID)
+	}
+
+	storedTeams, err := repo.ExistingTeams(ctx, teamNames)
+	if err != nil {
+		return err
+	}
+	storedUsers, err := repo.ExistingUsers(ctx, userIDs)
+	if err != nil {
+		return err
+	}
+	storedPRs, err := repo.ExistingPullRequests(ctx, prIDs)
+	if err != nil {
+		return err
+	}
+
+	teams := make(map[string]bool)
+	for i, t := range bundle.Teams {
+		switch {
+		case t.TeamName == "":
+			reject(domain.ImportTableTeams, i, "team_name is required")
+		case teams[t.TeamName]:
+			reject(domain.ImportTableTeams, i, "duplicate team %q", t.TeamName)
+		case storedTeams[t.TeamName]:
+			reject(domain.ImportTableTeams, i, "team %q already exists", t.TeamName)
+		}
+		teams[t.TeamName] = true
+	}
+
+	users := make(map[string]bool)
+	for i, u := range bundle.Users {
+		switch {
+		case u.UserID == "":
+			reject(domain.ImportTableUsers, i, "user_id is required")
+		case u.Username == "":
+			reject(domain.ImportTableUsers, i, "username is required")
+		case users[u.UserID]:
+			reject(domain.ImportTableUsers, i, "duplicate user %q", u.UserID)
+		case storedUsers[u.UserID]:
+			reject(domain.ImportTableUsers, i, "user %q already exists", u.UserID)
+		case !teams[u.TeamName] && !storedTeams[u.TeamName]:
+			reject(domain.ImportTableUsers, i, "unknown team %q", u.TeamName)
+		}
+		users[u.UserID] = true
+	}
+
+	prAuthors := make(map[string]string)
+	for i, pr := range bundle.PullRequests {
+		_, duplicate := prAuthors[pr.PullRequestID]
+		switch {
+		case pr.PullRequestID == "":
+			reject(domain.ImportTablePullRequests, i, "pull_request_id is required")
+		case pr.PullRequestName == "":
+			reject(domain.ImportTablePullRequests, i, "pull_request_name is required")
+		case duplicate:
+			reject(domain.ImportTablePullRequests, i, "duplicate pull request %q", pr.PullRequestID)
+		case storedPRs[pr.PullRequestID]:
+			reject(domain.ImportTablePullRequests, i, "pull request %q already exists", pr.PullRequestID)
+		case !users[pr.AuthorID] && !storedUsers[pr.AuthorID]:
+			reject(domain.ImportTablePullRequests, i, "unknown author %q", pr.AuthorID)
+		case pr.Status != domain.PRStatusOpen && pr.Status != domain.PRStatusMerged:
+			reject(domain.ImportTablePullRequests, i, "status must be OPEN or MERGED")
+		case pr.Status == domain.PRStatusMerged && pr.MergedAt == nil:
+			reject(domain.ImportTablePullRequests, i, "merged_at is required for MERGED pull requests")
+		case pr.Status == domain.PRStatusOpen && pr.MergedAt != nil:
+			reject(domain.ImportTablePullRequests, i, "open pull requests cannot have merged_at")
+		case pr.MergedAt != nil && pr.MergedAt.Before(*pr.CreatedAt):
+			reject(domain.ImportTablePullRequests, i, "merged_at is before created_at")
+		}
+		prAuthors[pr.PullRequestID] = pr.AuthorID
+	}
+
+	assigned := make(map[string]bool)
+	for i, a := range bundle.Assignments {
+		key := a.PullRequestID + "\x00" + a.UserID
+		author, known := prAuthors[a.PullRequestID]
+		switch {
+		case !known:
+			reject(domain.ImportTableAssignments, i, "pull request %q is not part of the bundle", a.PullRequestID)
+		case !users[a.UserID] && !storedUsers[a.UserID]:
+			reject(domain.ImportTableAssignments, i, "unknown reviewer %q", a.UserID)
+		case a.UserID == author:
+			reject(domain.ImportTableAssignments, i, "author cannot review their own pull request")
+		case assigned[key]:
+			reject(domain.ImportTableAssignments, i, "duplicate assignment of %q to %q", a.UserID, a.PullRequestID)
+		}
+		assigned[key] = true
+	}
+
+	if len(rowErrors) > 0 {
+		return &domain.ImportError{Rows: rowErrors}
+	}
+	return nil
+}
@@ -3,162 +3,447 @@ package service
 import (
 	"context"
 	"math/rand"
+	"net/url"
 	"pr-review-service/internal/domain"
 	"pr-review-service/internal/repository"
+	"strings"
 	"time"
 )
 
 type PRService struct {
-	prRepo   repository.PullRequestRepository
-	userRepo repository.UserRepository
-	teamRepo repository.TeamRepository
-	rand     *rand.Rand
+	prRepo      repository.PullRequestRepository
+	userRepo    repository.UserRepository
+	teamRepo    repository.TeamRepository
+	historyRepo repository.AssignmentHistoryRepository
+	uow         repository.UnitOfWork
+	rand        *rand.Rand
 }
 
 func NewPRService(
 	prRepo repository.PullRequestRepository,
 	userRepo repository.UserRepository,
 	teamRepo repository.TeamRepository,
+	historyRepo repository.AssignmentHistoryRepository,
+	uow repository.UnitOfWork,
 ) *PRService {
 	return &PRService{
-		prRepo:   prRepo,
-		userRepo: userRepo,
-		teamRepo: teamRepo,
-		rand:     rand.New(rand.NewSource(time.Now().UnixNano())),
+		prRepo:      prRepo,
+		userRepo:    userRepo,
+		teamRepo:    teamRepo,
+		historyRepo: historyRepo,
+		uow:         uow,
+		rand:        rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
 }
 
-func (s *PRService) CreatePR(ctx context.Context, prID, prName, authorID string) (*domain.PullRequest, error) {
-	exists, err := s.prRepo.Exists(ctx, prID)
+// CreatePR opens a PR reviewed by requestedReviewers, which must be active
+// users other than the author, topped up with random members of the author's
+// team to domain.MaxReviewers.
+func (s *PRService) CreatePR(ctx context.Context, prID, prName, authorID string, requestedReviewers []string) (*domain.PullRequest, error) {
+	var created *domain.PullRequest
+	err := s.uow.WithinTx(ctx, func(ctx context.Context, repos repository.Repository) error {
+		exists, err := repos.PullRequest.Exists(ctx, prID)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return domain.ErrPRExists
+		}
+
+		author, err := repos.User.Get(ctx, authorID)
+		if err != nil {
+			return domain.ErrAuthorNotFound
+		}
+		if author.DeletedAt != nil {
+			return domain.ErrUserDeleted
+		}
+
+		pr := &domain.PullRequest{
+			PullRequestID:     prID,
+			PullRequestName:   prName,
+			AuthorID:          authorID,
+			Status:            domain.PRStatusOpen,
+			AssignedReviewers: []string{},
+		}
+
+		if len(requestedReviewers) > domain.MaxReviewers {
+			return domain.ErrTooManyReviewers
+		}
+		for _, reviewerID := range requestedReviewers {
+			if err := checkNewReviewer(ctx, repos, pr, reviewerID); err != nil {
+				return err
+			}
+			pr.AssignedReviewers = append(pr.AssignedReviewers, reviewerID)
+		}
+
+		candidates, err := reviewerCandidates(ctx, repos, author.TeamName, authorID, pr.AssignedReviewers)
+		if err != nil {
+			return err
+		}
+		random := s.selectRandomReviewers(candidates, domain.MaxReviewers-len(pr.AssignedReviewers))
+		pr.AssignedReviewers = append(pr.AssignedReviewers, random...)
+
+		if err := repos.PullRequest.Create(ctx, pr); err != nil {
+			return err
+		}
+		for _, reviewerID := range requestedReviewers {
+			if err := repos.History.RecordAssigned(ctx, prID, reviewerID, domain.AssignmentReasonRequested, ActorFromContext(ctx)); err != nil {
+				return err
+			}
+		}
+		for _, reviewerID := range random {
+			if err := repos.History.RecordAssigned(ctx, prID, reviewerID, domain.AssignmentReasonInitial, ActorFromContext(ctx)); err != nil {
+				return err
+			}
+		}
+
+		created, err = repos.PullRequest.Get(ctx, prID)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, repos.Audit, domain.AuditActionPRCreate, domain.AuditEntityPullRequest, prID, nil, created)
+	})
 	if err != nil {
 		return nil, err
 	}
-	if exists {
-		return nil, domain.ErrPRExists
+
+	return created, nil
+}
+
+func (s *PRService) GetPR(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	return s.prRepo.Get(ctx, prID)
+}
+
+type prCursor struct {
+	SortBy        domain.PRSortField `json:"s"`
+	Descending    bool               `json:"d"`
+	SortValue     string             `json:"v"`
+	PullRequestID string             `json:"id"`
+}
+
+// ListPRs returns one page of PRs matching filter and the cursor of the next
+// page ("" on the last page). A cursor is only valid with the sort order it
+// was issued for.
+func (s *PRService) ListPRs(ctx context.Context, filter domain.PRFilter, cursor string) ([]domain.PullRequest, string, error) {
+	if err := normalizePRSort(&filter); err != nil {
+		return nil, "", err
 	}
 
-	author, err := s.userRepo.Get(ctx, authorID)
-	if err != nil {
-		return nil, domain.ErrAuthorNotFound
+	if cursor != "" {
+		var pos prCursor
+		if err := decodeCursor(cursor, &pos); err != nil {
+			return nil, "", err
+		}
+		if pos.SortBy != filter.SortBy || pos.Descending != filter.Descending {
+			return nil, "", domain.ErrInvalidCursor
+		}
+		filter.After = &domain.PRPageKey{SortValue: pos.SortValue, PullRequestID: pos.PullRequestID}
 	}
 
-	activeMembers, err := s.userRepo.GetActiveByTeam(ctx, author.TeamName)
+	pageSize := normalizePageSize(filter.Limit)
+	filter.Limit = pageSize + 1
+
+	prs, err := s.prRepo.List(ctx, filter)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	var candidates []string
-	for _, member := range activeMembers {
-		if member.UserID != authorID {
-			candidates = append(candidates, member.UserID)
-		}
+	var next string
+	if len(prs) > pageSize {
+		prs = prs[:pageSize]
+		last := prs[pageSize-1]
+		next = encodeCursor(prCursor{
+			SortBy:        filter.SortBy,
+			Descending:    filter.Descending,
+			SortValue:     prSortValue(&last, filter.SortBy),
+			PullRequestID: last.PullRequestID,
+		})
 	}
 
-	reviewers := s.selectRandomReviewers(candidates, 2)
+	return prs, next, nil
+}
 
-	pr := &domain.PullRequest{
-		PullRequestID:     prID,
-		PullRequestName:   prName,
-		AuthorID:          authorID,
-		Status:            domain.PRStatusOpen,
-		AssignedReviewers: reviewers,
+// ExportPRs calls fn for every PR matching filter, in filter's sort order,
+// streaming them from the database instead of paging.
+func (s *PRService) ExportPRs(ctx context.Context, filter domain.PRFilter, fn func(*domain.PullRequest) error) error {
+	if err := normalizePRSort(&filter); err != nil {
+		return err
 	}
+	filter.After = nil
+	filter.Limit = 0
 
-	if err := s.prRepo.Create(ctx, pr); err != nil {
-		return nil, err
+	return s.prRepo.Stream(ctx, filter, fn)
+}
+
+// ExportAssignmentHistory calls fn for every assignment history entry of the
+// PRs matching filter.
+func (s *PRService) ExportAssignmentHistory(ctx context.Context, filter domain.PRFilter, fn func(*domain.AssignmentRecord) error) error {
+	return s.historyRepo.Stream(ctx, filter, fn)
+}
+
+func normalizePRSort(filter *domain.PRFilter) error {
+	switch filter.SortBy {
+	case "":
+		filter.SortBy = domain.PRSortCreatedAt
+	case domain.PRSortCreatedAt, domain.PRSortMergedAt, domain.PRSortName:
+	default:
+		return domain.ErrInvalidSort
 	}
+	return nil
+}
 
-	return s.prRepo.Get(ctx, prID)
+// prSortValue renders the value pr is sorted by in the text form page keys use.
+func prSortValue(pr *domain.PullRequest, sortBy domain.PRSortField) string {
+	switch sortBy {
+	case domain.PRSortMergedAt:
+		if pr.MergedAt == nil {
+			return "infinity"
+		}
+		return pr.MergedAt.UTC().Format(time.RFC3339Nano)
+	case domain.PRSortName:
+		return pr.PullRequestName
+	default:
+		if pr.CreatedAt == nil {
+			return ""
+		}
+		return pr.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
 }
 
-func (s *PRService) MergePR(ctx context.Context, prID string) (*domain.PullRequest, error) {
-	pr, err := s.prRepo.Get(ctx, prID)
+// GetAssignmentHistory lists every reviewer that has ever been assigned to the
+// PR, including the ones that were later removed.
+func (s *PRService) GetAssignmentHistory(ctx context.Context, prID string) ([]domain.AssignmentRecord, error) {
+	exists, err := s.prRepo.Exists(ctx, prID)
 	if err != nil {
 		return nil, err
 	}
-
-	if pr.Status == domain.PRStatusMerged {
-		return pr, nil
+	if !exists {
+		return nil, domain.ErrPRNotFound
 	}
 
-	pr.Status = domain.PRStatusMerged
-	now := time.Now()
-	pr.MergedAt = &now
+	return s.historyRepo.ListByPR(ctx, prID)
+}
+
+// MergePR merges the PR. The current PR version must satisfy expected, which
+// lets clients guard against lost updates.
+func (s *PRService) MergePR(ctx context.Context, prID string, expected domain.VersionMatch) (*domain.PullRequest, error) {
+	var merged *domain.PullRequest
+	err := s.uow.WithinTx(ctx, func(ctx context.Context, repos repository.Repository) error {
+		pr, err := repos.PullRequest.GetForUpdate(ctx, prID)
+		if err != nil {
+			return err
+		}
+
+		if err := checkVersion(pr.Version, expected); err != nil {
+			return err
+		}
+
+		if pr.Status == domain.PRStatusMerged {
+			merged = pr
+			return nil
+		}
+
+		before := *pr
+		pr.Status = domain.PRStatusMerged
+		now := time.Now()
+		pr.MergedAt = &now
+
+		if err := repos.PullRequest.Update(ctx, pr); err != nil {
+			return err
+		}
 
-	if err := s.prRepo.Update(ctx, pr); err != nil {
+		merged = pr
+		return recordAudit(ctx, repos.Audit, domain.AuditActionPRMerge, domain.AuditEntityPullRequest, prID, before, pr)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	return pr, nil
+	return merged, nil
 }
 
-func (s *PRService) ReassignReviewer(ctx context.Context, prID, oldUserID string) (*domain.PullRequest, string, error) {
-	pr, err := s.prRepo.Get(ctx, prID)
-	if err != nil {
-		return nil, "", err
-	}
+// UpdatePR changes the PR name and metadata set in update. Merged PRs can be
+// updated too, since none of it affects review. The current PR version must
+// satisfy expected.
+func (s *PRService) UpdatePR(ctx context.Context, prID string, update domain.PRUpdate, expected domain.VersionMatch) (*domain.PullRequest, error) {
+	var updatedPR *domain.PullRequest
+	err := s.uow.WithinTx(ctx, func(ctx context.Context, repos repository.Repository) error {
+		pr, err := repos.PullRequest.GetForUpdate(ctx, prID)
+		if err != nil {
+			return err
+		}
 
-	if pr.Status == domain.PRStatusMerged {
-		return nil, "", domain.ErrPRMerged
-	}
+		if err := checkVersion(pr.Version, expected); err != nil {
+			return err
+		}
 
-	isReviewer, err := s.prRepo.IsReviewer(ctx, prID, oldUserID)
+		before := *pr
+		if err := applyPRUpdate(pr, update); err != nil {
+			return err
+		}
+		if err := repos.PullRequest.Update(ctx, pr); err != nil {
+			return err
+		}
+
+		updatedPR = pr
+		return recordAudit(ctx, repos.Audit, domain.AuditActionPRUpdate, domain.AuditEntityPullRequest, prID, before, pr)
+	})
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
-	if !isReviewer {
-		return nil, "", domain.ErrNotAssigned
+
+	return updatedPR, nil
+}
+
+// applyPRUpdate validates update and copies its fields into pr. Labels are
+// trimmed and deduplicated, keeping their order.
+func applyPRUpdate(pr *domain.PullRequest, update domain.PRUpdate) error {
+	if update.AuthorID != nil && *update.AuthorID != pr.AuthorID {
+		return domain.ErrAuthorImmutable
 	}
 
-	oldUser, err := s.userRepo.Get(ctx, oldUserID)
-	if err != nil {
-		return nil, "", err
+	if update.PullRequestName != nil {
+		name := strings.TrimSpace(*update.PullRequestName)
+		if name == "" {
+			return domain.ErrInvalidPRName
+		}
+		pr.PullRequestName = name
 	}
-	activeMembers, err := s.userRepo.GetActiveByTeam(ctx, oldUser.TeamName)
-	if err != nil {
-		return nil, "", err
+	if update.Description != nil {
+		pr.Description = *update.Description
 	}
+	if update.URL != nil {
+		rawURL := strings.TrimSpace(*update.URL)
+		if rawURL != "" {
+			u, err := url.Parse(rawURL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return domain.ErrInvalidPRURL
+			}
+		}
+		pr.URL = rawURL
+	}
+	if update.Repository != nil {
+		pr.Repository = strings.TrimSpace(*update.Repository)
+	}
+	if update.Labels != nil {
+		labels := []string{}
+		seen := make(map[string]bool)
+		for _, label := range *update.Labels {
+			label = strings.TrimSpace(label)
+			if label == "" {
+				return domain.ErrInvalidLabel
+			}
+			if !seen[label] {
+				seen[label] = true
+				labels = append(labels, label)
+			}
+		}
+		pr.Labels = labels
+	}
+	if update.Size != nil {
+		switch *update.Size {
+		case "", domain.PRSizeXS, domain.PRSizeS, domain.PRSizeM, domain.PRSizeL, domain.PRSizeXL:
+			pr.Size = *update.Size
+		default:
+			return domain.ErrInvalidPRSize
+		}
+	}
+	return nil
+}
+
+func (s *PRService) ReassignReviewer(ctx context.Context, prID, oldUserID string, expected domain.VersionMatch) (*domain.PullRequest, string, error) {
+	var updatedPR *domain.PullRequest
+	var newUserID string
+	err := s.uow.WithinTx(ctx, func(ctx context.Context, repos repository.Repository) error {
+		pr, err := repos.PullRequest.GetForUpdate(ctx, prID)
+		if err != nil {
+			return err
+		}
+
+		if err := checkVersion(pr.Version, expected); err != nil {
+			return err
+		}
+
+		if pr.Status == domain.PRStatusMerged {
+			return domain.ErrPRMerged
+		}
 
-	currentReviewers, err := s.prRepo.GetReviewers(ctx, prID)
+		isReviewer, err := repos.PullRequest.IsReviewer(ctx, prID, oldUserID)
+		if err != nil {
+			return err
+		}
+		if !isReviewer {
+			return domain.ErrNotAssigned
+		}
+
+		oldUser, err := repos.User.Get(ctx, oldUserID)
+		if err != nil {
+			return err
+		}
+
+		currentReviewers, err := repos.PullRequest.GetReviewers(ctx, prID)
+		if err != nil {
+			return err
+		}
+
+		candidates, err := reviewerCandidates(ctx, repos, oldUser.TeamName, pr.AuthorID, currentReviewers)
+		if err != nil {
+			return err
+		}
+		if len(candidates) == 0 {
+			return domain.ErrNoCandidate
+		}
+		newUserID = s.selectRandomReviewers(candidates, 1)[0]
+		if err := unassignReviewer(ctx, repos, prID, oldUserID, domain.AssignmentReasonManualReassign); err != nil {
+			return err
+		}
+
+		if err := assignReviewer(ctx, repos, prID, newUserID, domain.AssignmentReasonManualReassign); err != nil {
+			return err
+		}
+
+		// Reviewer changes are part of the PR state, so they bump its version.
+		before := *pr
+		if err := repos.PullRequest.Update(ctx, pr); err != nil {
+			return err
+		}
+
+		updatedPR, err = repos.PullRequest.Get(ctx, prID)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, repos.Audit, domain.AuditActionPRReassign, domain.AuditEntityPullRequest, prID, before, updatedPR)
+	})
 	if err != nil {
 		return nil, "", err
 	}
 
-	author, err := s.userRepo.Get(ctx, pr.AuthorID)
+	return updatedPR, newUserID, nil
+}
+
+// reviewerCandidates lists the active members of teamName who may review a PR
+// by authorID, leaving out the excluded users.
+func reviewerCandidates(ctx context.Context, repos repository.Repository, teamName, authorID string, excluded []string) ([]string, error) {
+	activeMembers, err := repos.User.GetActiveByTeam(ctx, teamName)
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
 
-	reviewerMap := make(map[string]bool)
-	for _, r := range currentReviewers {
-		reviewerMap[r] = true
+	skip := make(map[string]bool, len(excluded)+1)
+	skip[authorID] = true
+	for _, id := range excluded {
+		skip[id] = true
 	}
 
 	var candidates []string
 	for _, member := range activeMembers {
-		if !reviewerMap[member.UserID] && member.UserID != author.UserID {
+		if !skip[member.UserID] {
 			candidates = append(candidates, member.UserID)
 		}
 	}
-
-	if len(candidates) == 0 {
-		return nil, "", domain.ErrNoCandidate
-	}
-	newUserID := s.selectRandomReviewers(candidates, 1)[0]
-	if err := s.prRepo.RemoveReviewer(ctx, prID, oldUserID); err != nil {
-		return nil, "", err
-	}
-
-	if err := s.prRepo.AssignReviewer(ctx, prID, newUserID); err != nil {
-		return nil, "", err
-	}
-
-	updatedPR, err := s.prRepo.Get(ctx, prID)
-	if err != nil {
-		return nil, "", err
-	}
-
-	return updatedPR, newUserID, nil
+	return candidates, nil
 }
 
 func (s *PRService) selectRandomReviewers(candidates []string, count int) []string {
@@ -180,10 +465,83 @@ func (s *PRService) selectRandomReviewers(candidates []string, count int) []stri
 	return shuffled[:count]
 }
 
-func (s *PRService) DeactivateTeamAndReassign(ctx context.Context, teamName string) error {
-	members, err := s.userRepo.GetByTeam(ctx, teamName)
+// DeactivateTeamAndReassign deactivates every member of the team and moves
+// their open reviews to active members of each PR author's team, all in one
+// transaction. It reports what became of every review taken from the team.
+// The current team version must satisfy expected.
+func (s *PRService) DeactivateTeamAndReassign(ctx context.Context, teamName string, expected domain.VersionMatch) (*domain.Team, []domain.ReviewerReassignment, error) {
+	var team *domain.Team
+	var reassignments []domain.ReviewerReassignment
+	err := s.uow.WithinTx(ctx, func(ctx context.Context, repos repository.Repository) error {
+		before, err := repos.Team.Get(ctx, teamName)
+		if err != nil {
+			return err
+		}
+
+		// Bumping the version first also locks the team row, so concurrent
+		// deactivations of the same team run one after another.
+		if err := bumpTeamVersion(ctx, repos, before, expected); err != nil {
+			return err
+		}
+
+		reassignments, err = s.deactivateTeamAndReassign(ctx, repos, teamName)
+		if err != nil {
+			return err
+		}
+
+		team, err = repos.Team.Get(ctx, teamName)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, repos.Audit, domain.AuditActionTeamDeactivate, domain.AuditEntityTeam, teamName, before, team)
+	})
 	if err != nil {
-		return err
+		return nil, nil, err
+	}
+
+	return team, reassignments, nil
+}
+
+// PreviewTeamDeactivation computes what DeactivateTeamAndReassign would do
+// without writing anything: the team as it would look afterwards and the
+// reassignments it would make. Replacements are picked at random, so the
+// real run may pick different ones.
+func (s *PRService) PreviewTeamDeactivation(ctx context.Context, teamName string, expected domain.VersionMatch) (*domain.Team, []domain.ReviewerReassignment, error) {
+	repos := repository.Repository{Team: s.teamRepo, User: s.userRepo, PullRequest: s.prRepo}
+
+	team, err := repos.Team.Get(ctx, teamName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkVersion(team.Version, expected); err != nil {
+		return nil, nil, err
+	}
+
+	var memberIDs []string
+	for _, m := range team.Members {
+		memberIDs = append(memberIDs, m.UserID)
+	}
+
+	openPRs, err := repos.PullRequest.GetOpenPRsByReviewers(ctx, memberIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	reassignments, err := s.planReassignments(ctx, repos, openPRs, memberIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i := range team.Members {
+		team.Members[i].IsActive = false
+	}
+	return team, reassignments, nil
+}
+
+func (s *PRService) deactivateTeamAndReassign(ctx context.Context, repos repository.Repository, teamName string) ([]domain.ReviewerReassignment, error) {
+	members, err := repos.User.GetByTeam(ctx, teamName)
+	if err != nil {
+		return nil, err
 	}
 
 	var memberIDs []string
@@ -191,94 +549,217 @@ func (s *PRService) DeactivateTeamAndReassign(ctx context.Context, teamName stri
 		memberIDs = append(memberIDs, m.UserID)
 	}
 
-	openPRs, err := s.prRepo.GetOpenPRsByReviewers(ctx, memberIDs)
+	openPRs, err := repos.PullRequest.GetOpenPRsByReviewersForUpdate(ctx, memberIDs)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if err := s.teamRepo.DeactivateAll(ctx, teamName); err != nil {
-		return err
+	if err := repos.Team.DeactivateAll(ctx, teamName); err != nil {
+		return nil, err
+	}
+
+	return s.reassignReviews(ctx, repos, openPRs, memberIDs, domain.AssignmentReasonTeamDeactivation)
+}
+
+// reassignReviews moves the reviews removedIDs hold on openPRs to active
+// members of each PR author's team, or just drops them when nobody is left,
+// and reports every move.
+func (s *PRService) reassignReviews(ctx context.Context, repos repository.Repository, openPRs []domain.PullRequest, removedIDs []string, reason domain.AssignmentReason) ([]domain.ReviewerReassignment, error) {
+	reassignments, err := s.planReassignments(ctx, repos, openPRs, removedIDs)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyReassignments(ctx, repos, openPRs, reassignments, reason); err != nil {
+		return nil, err
 	}
+	return reassignments, nil
+}
 
-	assignments := make(map[string]string) // prID:oldUserID -> newUserID
+// planReassignments picks a replacement for every review removedIDs hold on
+// openPRs without writing anything. The candidates of all PRs are loaded up
+// front, in two queries however many PRs there are; removed users are never
+// candidates, whether or not they are still active.
+func (s *PRService) planReassignments(ctx context.Context, repos repository.Repository, openPRs []domain.PullRequest, removedIDs []string) ([]domain.ReviewerReassignment, error) {
+	removed := make(map[string]bool, len(removedIDs))
+	for _, id := range removedIDs {
+		removed[id] = true
+	}
 
+	authorIDs := make([]string, 0, len(openPRs))
 	for _, pr := range openPRs {
+		authorIDs = append(authorIDs, pr.AuthorID)
+	}
+	authors, err := repos.User.GetByIDs(ctx, authorIDs)
+	if err != nil {
+		return nil, err
+	}
 
-		author, err := s.userRepo.Get(ctx, pr.AuthorID)
-		if err != nil {
-			continue
-		}
-		activeMembers, err := s.userRepo.GetActiveByTeam(ctx, author.TeamName)
-		if err != nil {
-			continue
+	teamNames := make([]string, 0, len(authors))
+	seenTeams := make(map[string]bool)
+	for _, author := range authors {
+		if !seenTeams[author.TeamName] {
+			seenTeams[author.TeamName] = true
+			teamNames = append(teamNames, author.TeamName)
 		}
+	}
+	activeByTeam, err := repos.User.GetActiveByTeams(ctx, teamNames)
+	if err != nil {
+		return nil, err
+	}
 
+	reassignments := []domain.ReviewerReassignment{}
+	for _, pr := range openPRs {
 		reviewerMap := make(map[string]bool)
 		for _, r := range pr.AssignedReviewers {
 			reviewerMap[r] = true
 		}
 
 		var candidates []string
-		for _, member := range activeMembers {
-			if !reviewerMap[member.UserID] && member.UserID != author.UserID {
+		for _, member := range activeByTeam[authors[pr.AuthorID].TeamName] {
+			if !reviewerMap[member.UserID] && !removed[member.UserID] && member.UserID != pr.AuthorID {
 				candidates = append(candidates, member.UserID)
 			}
 		}
 
 		for _, reviewerID := range pr.AssignedReviewers {
-			// Check if this reviewer is in the deactivated team
-			isDeactivated := false
-			for _, memberID := range memberIDs {
-				if memberID == reviewerID {
-					isDeactivated = true
-					break
-				}
+			if !removed[reviewerID] {
+				continue
 			}
 
-			if isDeactivated {
-				key := pr.PullRequestID + ":" + reviewerID
-				if len(candidates) > 0 {
-					// Assign random candidate
-					newReviewer := s.selectRandomReviewers(candidates, 1)[0]
-					assignments[key] = newReviewer
-					// Remove from candidates to avoid duplicate assignment
-					for i, c := range candidates {
-						if c == newReviewer {
-							candidates = append(candidates[:i], candidates[i+1:]...)
-							break
-						}
+			ra := domain.ReviewerReassignment{
+				PullRequestID: pr.PullRequestID,
+				OldReviewerID: reviewerID,
+				Outcome:       domain.ReassignmentOutcomeLeftUnassigned,
+			}
+			if len(candidates) > 0 {
+				ra.NewReviewerID = s.selectRandomReviewers(candidates, 1)[0]
+				ra.Outcome = domain.ReassignmentOutcomeReassigned
+				// Remove from candidates to avoid duplicate assignment
+				for i, c := range candidates {
+					if c == ra.NewReviewerID {
+						candidates = append(candidates[:i], candidates[i+1:]...)
+						break
 					}
-				} else {
-					// No candidates, just remove
-					assignments[key] = ""
 				}
 			}
+			reassignments = append(reassignments, ra)
 		}
 	}
+	return reassignments, nil
+}
 
-	batchAssignments := make(map[string]string) // prID -> newUserID
-	for key, newUserID := range assignments {
-		var prID, oldUserID string
-		for i, ch := range key {
-			if ch == ':' {
-				prID = key[:i]
-				oldUserID = key[i+1:]
-				break
-			}
+// applyReassignments writes the reviewer changes with one batch per removed
+// reviewer, records them in the history at once and bumps the version of
+// every PR they touch. The audit after-images are derived from openPRs and the
+// plan, so no PR is read back.
+func applyReassignments(ctx context.Context, repos repository.Repository, openPRs []domain.PullRequest, reassignments []domain.ReviewerReassignment, reason domain.AssignmentReason) error {
+	if len(reassignments) == 0 {
+		return nil
+	}
+
+	var oldReviewerIDs, touchedPRIDs []string
+	batches := make(map[string]map[string]string) // oldUserID -> prID -> newUserID
+	changes := make(map[string][]domain.ReviewerReassignment)
+	for _, ra := range reassignments {
+		if batches[ra.OldReviewerID] == nil {
+			batches[ra.OldReviewerID] = make(map[string]string)
+			oldReviewerIDs = append(oldReviewerIDs, ra.OldReviewerID)
+		}
+		batches[ra.OldReviewerID][ra.PullRequestID] = ra.NewReviewerID
+		if changes[ra.PullRequestID] == nil {
+			touchedPRIDs = append(touchedPRIDs, ra.PullRequestID)
+		}
+		changes[ra.PullRequestID] = append(changes[ra.PullRequestID], ra)
+	}
+
+	for _, oldUserID := range oldReviewerIDs {
+		if err := repos.PullRequest.ReassignReviewersInBatch(ctx, oldUserID, batches[oldUserID]); err != nil {
+			return err
 		}
+	}
+	if err := repos.History.RecordReassignments(ctx, reassignments, reason, ActorFromContext(ctx)); err != nil {
+		return err
+	}
+
+	// Reviewer changes are part of the PR state, so they bump its version.
+	versions, err := repos.PullRequest.BumpVersions(ctx, touchedPRIDs)
+	if err != nil {
+		return err
+	}
 
-		if err := s.prRepo.RemoveReviewer(ctx, prID, oldUserID); err != nil {
+	var events []domain.AuditEvent
+	for _, before := range openPRs {
+		if changes[before.PullRequestID] == nil {
+			continue
+		}
+		after := reassignedPR(before, changes[before.PullRequestID], versions[before.PullRequestID])
+		event, err := auditEvent(ctx, domain.AuditActionPRReassign, domain.AuditEntityPullRequest, before.PullRequestID, before, after)
+		if err != nil {
 			return err
 		}
+		events = append(events, *event)
+	}
+	return repos.Audit.AppendBatch(ctx, events)
+}
 
-		if newUserID != "" {
-			if err := s.prRepo.AssignReviewer(ctx, prID, newUserID); err != nil {
-				return err
-			}
+// reassignedPR returns pr as it is stored after changes: the old reviewers are
+// gone and the new ones come last, as they were assigned most recently.
+func reassignedPR(pr domain.PullRequest, changes []domain.ReviewerReassignment, version int) domain.PullRequest {
+	removed := make(map[string]bool, len(changes))
+	for _, ra := range changes {
+		removed[ra.OldReviewerID] = true
+	}
+
+	reviewers := []string{}
+	for _, id := range pr.AssignedReviewers {
+		if !removed[id] {
+			reviewers = append(reviewers, id)
 		}
+	}
+	for _, ra := range changes {
+		if ra.NewReviewerID != "" {
+			reviewers = append(reviewers, ra.NewReviewerID)
+		}
+	}
+
+	pr.AssignedReviewers = reviewers
+	pr.Version = version
+	return pr
+}
 
-		batchAssignments[prID] = newUserID
+// assignReviewer adds the reviewer and records why in the assignment history.
+func assignReviewer(ctx context.Context, repos repository.Repository, prID, userID string, reason domain.AssignmentReason) error {
+	if err := repos.PullRequest.AssignReviewer(ctx, prID, userID); err != nil {
+		return err
 	}
+	return repos.History.RecordAssigned(ctx, prID, userID, reason, ActorFromContext(ctx))
+}
 
+// unassignReviewer removes the reviewer and closes its assignment history entry.
+func unassignReviewer(ctx context.Context, repos repository.Repository, prID, userID string, reason domain.AssignmentReason) error {
+	if err := repos.PullRequest.RemoveReviewer(ctx, prID, userID); err != nil {
+		return err
+	}
+	return repos.History.RecordUnassigned(ctx, prID, userID, reason, ActorFromContext(ctx))
+}
+
+func checkVersion(actual int, expected domain.VersionMatch) error {
+	if !expected.Matches(actual) {
+		return domain.ErrVersionMismatch
+	}
 	return nil
 }
+
+// bumpTeamVersion checks expected against team, as read earlier in the
+// transaction, and bumps the version only if it has not changed since.
+func bumpTeamVersion(ctx context.Context, repos repository.Repository, team *domain.Team, expected domain.VersionMatch) error {
+	if err := checkVersion(team.Version, expected); err != nil {
+		return err
+	}
+	expectedVersion := 0
+	if expected.Required {
+		expectedVersion = team.Version
+	}
+	_, err := repos.Team.BumpVersion(ctx, team.TeamName, expectedVersion)
+	return err
+}
@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"pr-review-service/internal/domain"
+	"pr-review-service/internal/repository"
+	"sort"
+)
+
+// ActivateTeam activates the listed members of the team, or all of them when
+// userIDs is empty. With rebalance it then spreads the team's open reviews
+// over its active members (see RebalanceTeam), so people coming back pick up
+// their share. The current team version must satisfy expected.
+func (s *PRService) ActivateTeam(ctx context.Context, teamName string, userIDs []string, rebalance bool, expected domain.VersionMatch) (*domain.Team, []domain.ReviewerReassignment, error) {
+	var team *domain.Team
+	reassignments := []domain.ReviewerReassignment{}
+	err := s.uow.WithinTx(ctx, func(ctx context.Context, repos repository.Repository) error {
+		before, err := repos.Team.Get(ctx, teamName)
+		if err != nil {
+			return err
+		}
+		if err := checkMembers(before, userIDs); err != nil {
+			return err
+		}
+
+		if err := bumpTeamVersion(ctx, repos, before, expected); err != nil {
+			return err
+		}
+		if err := repos.Team.ActivateAll(ctx, teamName, userIDs); err != nil {
+			return err
+		}
+
+		team, err = repos.Team.Get(ctx, teamName)
+		if err != nil {
+			return err
+		}
+
+		if rebalance {
+			var openPRs []domain.PullRequest
+			openPRs, reassignments, err = planTeamRebalance(ctx, repos, team, repos.PullRequest.GetOpenPRsByReviewersForUpdate)
+			if err != nil {
+				return err
+			}
+			if err := applyReassignments(ctx, repos, openPRs, reassignments, domain.AssignmentReasonRebalance); err != nil {
+				return err
+			}
+		}
+
+		return recordAudit(ctx, repos.Audit, domain.AuditActionTeamActivate, domain.AuditEntityTeam, teamName, before, team)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return team, reassignments, nil
+}
+
+// PreviewTeamActivation computes what ActivateTeam would do without writing
+// anything.
+func (s *PRService) PreviewTeamActivation(ctx context.Context, teamName string, userIDs []string, rebalance bool, expected domain.VersionMatch) (*domain.Team, []domain.ReviewerReassignment, error) {
+	repos := repository.Repository{Team: s.teamRepo, User: s.userRepo, PullRequest: s.prRepo}
+
+	team, err := repos.Team.Get(ctx, teamName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkVersion(team.Version, expected); err != nil {
+		return nil, nil, err
+	}
+	if err := checkMembers(team, userIDs); err != nil {
+		return nil, nil, err
+	}
+
+	listed := make(map[string]bool, len(userIDs))
+	for _, id := range userIDs {
+		listed[id] = true
+	}
+	for i := range team.Members {
+		if len(listed) == 0 || listed[team.Members[i].UserID] {
+			team.Members[i].IsActive = true
+		}
+	}
+
+	reassignments := []domain.ReviewerReassignment{}
+	if rebalance {
+		_, reassignments, err = planTeamRebalance(ctx, repos, team, repos.PullRequest.GetOpenPRsByReviewers)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return team, reassignments, nil
+}
+
+// RebalanceTeam spreads the open reviews of PRs authored in the team over its
+// active members with the least loaded strategy: reviews move from the most
+// to the least loaded members until their open review counts differ by at
+// most one or no further move is allowed.
+func (s *PRService) RebalanceTeam(ctx context.Context, teamName string) ([]domain.ReviewerReassignment, error) {
+	var reassignments []domain.ReviewerReassignment
+	err := s.uow.WithinTx(ctx, func(ctx context.Context, repos repository.Repository) error {
+		team, err := repos.Team.Get(ctx, teamName)
+		if err != nil {
+			return err
+		}
+
+		var openPRs []domain.PullRequest
+		openPRs, reassignments, err = planTeamRebalance(ctx, repos, team, repos.PullRequest.GetOpenPRsByReviewersForUpdate)
+		if err != nil {
+			return err
+		}
+		return applyReassignments(ctx, repos, openPRs, reassignments, domain.AssignmentReasonRebalance)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return reassignments, nil
+}
+
+// PreviewTeamRebalance computes what RebalanceTeam would do without writing
+// anything. The least loaded strategy is deterministic, so the real run makes
+// the same moves unless reviews change in between.
+func (s *PRService) PreviewTeamRebalance(ctx context.Context, teamName string) ([]domain.ReviewerReassignment, error) {
+	team, err := s.teamRepo.Get(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	repos := repository.Repository{Team: s.teamRepo, User: s.userRepo, PullRequest: s.prRepo}
+	_, reassignments, err := planTeamRebalance(ctx, repos, team, s.prRepo.GetOpenPRsByReviewers)
+	return reassignments, err
+}
+
+// checkMembers fails unless every one of userIDs is a member of team.
+func checkMembers(team *domain.Team, userIDs []string) error {
+	members := make(map[string]bool, len(team.Members))
+	for _, m := range team.Members {
+		members[m.UserID] = true
+	}
+	for _, id := range userIDs {
+		if !members[id] {
+			return domain.ErrNotTeamMember
+		}
+	}
+	return nil
+}
+
+// planTeamRebalance loads the open reviews of the team's active members, as
+// team.Members says they are, with loadPRs and plans the moves that even them
+// out. It returns the loaded PRs for applyReassignments.
+func planTeamRebalance(ctx context.Context, repos repository.Repository, team *domain.Team,
+	loadPRs func(ctx context.Context, userIDs []string) ([]domain.PullRequest, error)) ([]domain.PullRequest, []domain.ReviewerReassignment, error) {
+	var activeIDs []string
+	for _, m := range team.Members {
+		if m.IsActive {
+			activeIDs = append(activeIDs, m.UserID)
+		}
+	}
+	if len(activeIDs) == 0 {
+		return nil, []domain.ReviewerReassignment{}, nil
+	}
+
+	openPRs, err := loadPRs(ctx, activeIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	activity, err := repos.User.GetActivity(ctx, activeIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	load := make(map[string]int, len(activity))
+	for id, a := range activity {
+		load[id] = a.OpenReviewCount
+	}
+	return openPRs, planRebalance(team, activeIDs, load, openPRs), nil
+}
+
+// planRebalance repeatedly moves a review from the most loaded active member
+// to the least loaded one that may take it, as long as that narrows the gap.
+// Every move lowers the sum of squared loads, so it ends. Load counts all open
+// reviews, but only reviews on PRs authored in the team move, since reviewers
+// come from the author's team. A review is moved at most once, and ties go to
+// the lower user ID, so the plan is deterministic.
+func planRebalance(team *domain.Team, activeIDs []string, load map[string]int, openPRs []domain.PullRequest) []domain.ReviewerReassignment {
+	members := make(map[string]bool, len(team.Members))
+	for _, m := range team.Members {
+		members[m.UserID] = true
+	}
+
+	type review struct{ pr, userID string }
+	reviewing := make(map[review]bool)
+	var movable []domain.PullRequest
+	for _, pr := range openPRs {
+		if !members[pr.AuthorID] {
+			continue
+		}
+		movable = append(movable, pr)
+		for _, r := range pr.AssignedReviewers {
+			reviewing[review{pr.PullRequestID, r}] = true
+		}
+	}
+
+	byLoad := append([]string(nil), activeIDs...)
+	moved := make(map[review]bool)
+	reassignments := []domain.ReviewerReassignment{}
+	for {
+		sort.Slice(byLoad, func(i, j int) bool {
+			if load[byLoad[i]] != load[byLoad[j]] {
+				return load[byLoad[i]] < load[byLoad[j]]
+			}
+			return byLoad[i] < byLoad[j]
+		})
+
+		ra, ok := nextRebalanceMove(byLoad, load, movable, func(prID, userID string) (assigned, pinned bool) {
+			return reviewing[review{prID, userID}], moved[review{prID, userID}]
+		})
+		if !ok {
+			return reassignments
+		}
+
+		reviewing[review{ra.PullRequestID, ra.OldReviewerID}] = false
+		reviewing[review{ra.PullRequestID, ra.NewReviewerID}] = true
+		moved[review{ra.PullRequestID, ra.NewReviewerID}] = true
+		load[ra.OldReviewerID]--
+		load[ra.NewReviewerID]++
+		reassignments = append(reassignments, ra)
+	}
+}
+
+// nextRebalanceMove finds the move from the most loaded donor with a movable
+// review to the least loaded member that may take it. byLoad is sorted by
+// ascending load.
+func nextRebalanceMove(byLoad []string, load map[string]int, prs []domain.PullRequest,
+	state func(prID, userID string) (assigned, pinned bool)) (domain.ReviewerReassignment, bool) {
+	for d := len(byLoad) - 1; d > 0; d-- {
+		donor := byLoad[d]
+		for _, pr := range prs {
+			if assigned, pinned := state(pr.PullRequestID, donor); !assigned || pinned {
+				continue
+			}
+			for _, receiver := range byLoad[:d] {
+				if load[donor]-load[receiver] < 2 {
+					break
+				}
+				if assigned, _ := state(pr.PullRequestID, receiver); assigned || receiver == pr.AuthorID {
+					continue
+				}
+				return domain.ReviewerReassignment{
+					PullRequestID: pr.PullRequestID,
+					OldReviewerID: donor,
+					NewReviewerID: receiver,
+					Outcome:       domain.ReassignmentOutcomeReassigned,
+				}, true
+			}
+		}
+	}
+	return domain.ReviewerReassignment{}, false
+}
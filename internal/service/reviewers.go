@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"pr-review-service/internal/domain"
+	"pr-review-service/internal/repository"
+)
+
+// AddReviewer assigns userID to the PR as an extra reviewer, up to
+// domain.MaxReviewers. The current PR version must satisfy expected.
+func (s *PRService) AddReviewer(ctx context.Context, prID, userID string, expected domain.VersionMatch) (*domain.PullRequest, error) {
+	return s.changeReviewers(ctx, prID, expected, domain.AuditActionPRAssign,
+		func(ctx context.Context, repos repository.Repository, pr *domain.PullRequest) error {
+			if err := checkNewReviewer(ctx, repos, pr, userID); err != nil {
+				return err
+			}
+			if len(pr.AssignedReviewers) >= domain.MaxReviewers {
+				return domain.ErrTooManyReviewers
+			}
+			return assignReviewer(ctx, repos, prID, userID, domain.AssignmentReasonManual)
+		})
+}
+
+// RemoveReviewer unassigns userID from the PR without replacing them.
+func (s *PRService) RemoveReviewer(ctx context.Context, prID, userID string, expected domain.VersionMatch) (*domain.PullRequest, error) {
+	return s.changeReviewers(ctx, prID, expected, domain.AuditActionPRUnassign,
+		func(ctx context.Context, repos repository.Repository, pr *domain.PullRequest) error {
+			if !isAssigned(pr, userID) {
+				return domain.ErrNotAssigned
+			}
+			return unassignReviewer(ctx, repos, prID, userID, domain.AssignmentReasonManual)
+		})
+}
+
+// SwapReviewer replaces the reviewer oldUserID with newUserID, unlike
+// ReassignReviewer, which picks the replacement at random.
+func (s *PRService) SwapReviewer(ctx context.Context, prID, oldUserID, newUserID string, expected domain.VersionMatch) (*domain.PullRequest, error) {
+	return s.changeReviewers(ctx, prID, expected, domain.AuditActionPRReassign,
+		func(ctx context.Context, repos repository.Repository, pr *domain.PullRequest) error {
+			if !isAssigned(pr, oldUserID) {
+				return domain.ErrNotAssigned
+			}
+			if err := checkNewReviewer(ctx, repos, pr, newUserID); err != nil {
+				return err
+			}
+			if err := unassignReviewer(ctx, repos, prID, oldUserID, domain.AssignmentReasonManualReassign); err != nil {
+				return err
+			}
+			return assignReviewer(ctx, repos, prID, newUserID, domain.AssignmentReasonManualReassign)
+		})
+}
+
+// RerollReviewers picks a whole new random set of reviewers from the author's
+// team, as CreatePR does. Current reviewers may be picked again, in which case
+// they simply stay.
+func (s *PRService) RerollReviewers(ctx context.Context, prID string, expected domain.VersionMatch) (*domain.PullRequest, error) {
+	return s.changeReviewers(ctx, prID, expected, domain.AuditActionPRReassign,
+		func(ctx context.Context, repos repository.Repository, pr *domain.PullRequest) error {
+			author, err := repos.User.Get(ctx, pr.AuthorID)
+			if err != nil {
+				return err
+			}
+			candidates, err := reviewerCandidates(ctx, repos, author.TeamName, pr.AuthorID, nil)
+			if err != nil {
+				return err
+			}
+
+			picked := make(map[string]bool)
+			for _, id := range s.selectRandomReviewers(candidates, domain.MaxReviewers) {
+				picked[id] = true
+			}
+			for _, id := range pr.AssignedReviewers {
+				if picked[id] {
+					delete(picked, id)
+					continue
+				}
+				if err := unassignReviewer(ctx, repos, prID, id, domain.AssignmentReasonReroll); err != nil {
+					return err
+				}
+			}
+			for _, id := range candidates {
+				if !picked[id] {
+					continue
+				}
+				if err := assignReviewer(ctx, repos, prID, id, domain.AssignmentReasonReroll); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+}
+
+// changeReviewers locks the open PR, lets change edit its reviewers, then bumps
+// the PR version and records the change in the audit log as action.
+func (s *PRService) changeReviewers(ctx context.Context, prID string, expected domain.VersionMatch, action string,
+	change func(ctx context.Context, repos repository.Repository, pr *domain.PullRequest) error) (*domain.PullRequest, error) {
+	var updatedPR *domain.PullRequest
+	err := s.uow.WithinTx(ctx, func(ctx context.Context, repos repository.Repository) error {
+		pr, err := repos.PullRequest.GetForUpdate(ctx, prID)
+		if err != nil {
+			return err
+		}
+
+		if err := checkVersion(pr.Version, expected); err != nil {
+			return err
+		}
+
+		if pr.Status == domain.PRStatusMerged {
+			return domain.ErrPRMerged
+		}
+
+		before := *pr
+		if err := change(ctx, repos, pr); err != nil {
+			return err
+		}
+		if err := repos.PullRequest.Update(ctx, pr); err != nil {
+			return err
+		}
+
+		updatedPR, err = repos.PullRequest.Get(ctx, prID)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, repos.Audit, action, domain.AuditEntityPullRequest, prID, before, updatedPR)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updatedPR, nil
+}
+
+// checkNewReviewer fails unless userID is an active user who may start
+// reviewing pr.
+func checkNewReviewer(ctx context.Context, repos repository.Repository, pr *domain.PullRequest, userID string) error {
+	user, err := repos.User.Get(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.DeletedAt != nil {
+		return domain.ErrUserDeleted
+	}
+	if !user.IsActive {
+		return domain.ErrReviewerInactive
+	}
+	if userID == pr.AuthorID {
+		return domain.ErrReviewerIsAuthor
+	}
+	if isAssigned(pr, userID) {
+		return domain.ErrAlreadyAssigned
+	}
+	return nil
+}
+
+func isAssigned(pr *domain.PullRequest, userID string) bool {
+	for _, r := range pr.AssignedReviewers {
+		if r == userID {
+			return true
+		}
+	}
+	return false
+}
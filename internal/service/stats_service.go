@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"pr-review-service/internal/domain"
+	"pr-review-service/internal/repository"
+	"sort"
+	"time"
+)
+
+const (
+	defaultMetricsWindow = 30 * 24 * time.Hour
+	maxMetricsWindow     = 366 * 24 * time.Hour
+)
+
+type StatsService struct {
+	statsRepo repository.StatsRepository
+	teamRepo  repository.TeamRepository
+	userRepo  repository.UserRepository
+}
+
+func NewStatsService(statsRepo repository.StatsRepository, teamRepo repository.TeamRepository, userRepo repository.UserRepository) *StatsService {
+	return &StatsService{
+		statsRepo: statsRepo,
+		teamRepo:  teamRepo,
+		userRepo:  userRepo,
+	}
+}
+
+// GetReviewMetrics computes review metrics over [from, to). A nil to means
+// now and a nil from means 30 days before to; the window may span a year at
+// most.
+func (s *StatsService) GetReviewMetrics(ctx context.Context, teamName, userID string, from, to *time.Time) (*domain.ReviewMetrics, error) {
+	filter := domain.MetricsFilter{TeamName: teamName, UserID: userID}
+
+	var err error
+	if filter.From, filter.To, err = resolveWindow(from, to); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkTeam(ctx, teamName); err != nil {
+		return nil, err
+	}
+	if userID != "" {
+		if _, err := s.userRepo.Get(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.statsRepo.GetReviewMetrics(ctx, filter)
+}
+
+const (
+	overloadRatio  = 1.25
+	underloadRatio = 0.75
+)
+
+// GetFairnessReport reports how evenly reviews were spread over [from, to)
+// (see GetReviewMetrics for the defaults) within one team, or within every
+// team when teamName is empty. Each member's expected share of the team's
+// assignments is proportional to the time they were active in the window;
+// members getting over 125% of their share are overloaded, under 75% are
+// underloaded.
+func (s *StatsService) GetFairnessReport(ctx context.Context, teamName string, from, to *time.Time) (*domain.FairnessReport, error) {
+	filter := domain.FairnessFilter{TeamName: teamName}
+
+	var err error
+	if filter.From, filter.To, err = resolveWindow(from, to); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkTeam(ctx, teamName); err != nil {
+		return nil, err
+	}
+
+	loads, err := s.statsRepo.GetMemberLoad(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.FairnessReport{From: filter.From, To: filter.To, Teams: []domain.TeamFairness{}}
+	for start := 0; start < len(loads); {
+		end := start
+		for end < len(loads) && loads[end].TeamName == loads[start].TeamName {
+			end++
+		}
+		report.Teams = append(report.Teams, teamFairness(loads[start:end]))
+		start = end
+	}
+
+	return report, nil
+}
+
+// teamFairness computes the fairness of one team from its members' loads.
+func teamFairness(members []domain.MemberLoad) domain.TeamFairness {
+	team := domain.TeamFairness{
+		TeamName:    members[0].TeamName,
+		Members:     members,
+		Overloaded:  []string{},
+		Underloaded: []string{},
+	}
+
+	var activeDays float64
+	for _, m := range members {
+		team.Assignments += m.Assignments
+		activeDays += m.ActiveDays
+	}
+	if activeDays == 0 {
+		return team
+	}
+
+	var rates []float64
+	for i := range members {
+		m := &members[i]
+		if m.ActiveDays == 0 {
+			continue
+		}
+		rates = append(rates, float64(m.Assignments)/m.ActiveDays)
+
+		m.ExpectedAssignments = float64(team.Assignments) * m.ActiveDays / activeDays
+		if m.ExpectedAssignments == 0 {
+			continue
+		}
+		ratio := float64(m.Assignments) / m.ExpectedAssignments
+		m.LoadRatio = &ratio
+
+		switch {
+		case ratio > overloadRatio:
+			team.Overloaded = append(team.Overloaded, m.UserID)
+		case ratio < underloadRatio:
+			team.Underloaded = append(team.Underloaded, m.UserID)
+		}
+	}
+	team.Gini = gini(rates)
+
+	return team
+}
+
+// gini returns the Gini coefficient of values, 0 for an empty or all-zero set.
+func gini(values []float64) float64 {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	var sum, weighted float64
+	for i, v := range sorted {
+		sum += v
+		weighted += float64(i+1) * v
+	}
+	if sum == 0 {
+		return 0
+	}
+
+	n := float64(len(sorted))
+	return 2*weighted/(n*sum) - (n+1)/n
+}
+
+// resolveWindow defaults to now for a nil to and to 30 days before to for a
+// nil from, and rejects empty windows and windows over a year long. The
+// bounds arrive in UTC from the handler.
+func resolveWindow(from, to *time.Time) (time.Time, time.Time, error) {
+	end := time.Now().UTC()
+	if to != nil {
+		end = *to
+	}
+	start := end.Add(-defaultMetricsWindow)
+	if from != nil {
+		start = *from
+	}
+	if !start.Before(end) || end.Sub(start) > maxMetricsWindow {
+		return time.Time{}, time.Time{}, domain.ErrInvalidWindow
+	}
+	return start, end, nil
+}
+
+func (s *StatsService) checkTeam(ctx context.Context, teamName string) error {
+	if teamName == "" {
+		return nil
+	}
+	exists, err := s.teamRepo.Exists(ctx, teamName)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return domain.ErrTeamNotFound
+	}
+	return nil
+}
@@ -9,41 +9,56 @@ import (
 type TeamService struct {
 	teamRepo repository.TeamRepository
 	userRepo repository.UserRepository
+	uow      repository.UnitOfWork
 }
 
-func NewTeamService(teamRepo repository.TeamRepository, userRepo repository.UserRepository) *TeamService {
+func NewTeamService(teamRepo repository.TeamRepository, userRepo repository.UserRepository, uow repository.UnitOfWork) *TeamService {
 	return &TeamService{
 		teamRepo: teamRepo,
 		userRepo: userRepo,
+		uow:      uow,
 	}
 }
 
 func (s *TeamService) CreateTeam(ctx context.Context, team *domain.Team) (*domain.Team, error) {
-	exists, err := s.teamRepo.Exists(ctx, team.TeamName)
-	if err != nil {
-		return nil, err
-	}
-	if exists {
-		return nil, domain.ErrTeamExists
-	}
+	var created *domain.Team
+	err := s.uow.WithinTx(ctx, func(ctx context.Context, repos repository.Repository) error {
+		exists, err := repos.Team.Exists(ctx, team.TeamName)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return domain.ErrTeamExists
+		}
 
-	if err := s.teamRepo.Create(ctx, team); err != nil {
-		return nil, err
-	}
+		if err := repos.Team.Create(ctx, team); err != nil {
+			return err
+		}
 
-	for _, member := range team.Members {
-		user := &domain.User{
-			UserID:   member.UserID,
-			Username: member.Username,
-			TeamName: team.TeamName,
-			IsActive: member.IsActive,
+		for _, member := range team.Members {
+			user := &domain.User{
+				UserID:   member.UserID,
+				Username: member.Username,
+				TeamName: team.TeamName,
+				IsActive: member.IsActive,
+			}
+			if err := repos.User.Create(ctx, user); err != nil {
+				return err
+			}
 		}
-		if err := s.userRepo.Create(ctx, user); err != nil {
-			return nil, err
+
+		created, err = repos.Team.Get(ctx, team.TeamName)
+		if err != nil {
+			return err
 		}
+
+		return recordAudit(ctx, repos.Audit, domain.AuditActionTeamCreate, domain.AuditEntityTeam, team.TeamName, nil, created)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return s.teamRepo.Get(ctx, team.TeamName)
+	return created, nil
 }
 
 func (s *TeamService) GetTeam(ctx context.Context, teamName string) (*domain.Team, error) {
@@ -4,22 +4,194 @@ import (
 	"context"
 	"pr-review-service/internal/domain"
 	"pr-review-service/internal/repository"
+	"time"
 )
 
 type UserService struct {
-	userRepo repository.UserRepository
-	prRepo   repository.PullRequestRepository
+	userRepo  repository.UserRepository
+	prRepo    repository.PullRequestRepository
+	prService *PRService
+	uow       repository.UnitOfWork
 }
 
-func NewUserService(userRepo repository.UserRepository, prRepo repository.PullRequestRepository) *UserService {
+func NewUserService(
+	userRepo repository.UserRepository,
+	prRepo repository.PullRequestRepository,
+	prService *PRService,
+	uow repository.UnitOfWork,
+) *UserService {
 	return &UserService{
-		userRepo: userRepo,
-		prRepo:   prRepo,
+		userRepo:  userRepo,
+		prRepo:    prRepo,
+		prService: prService,
+		uow:       uow,
 	}
 }
 
 func (s *UserService) SetIsActive(ctx context.Context, userID string, isActive bool) (*domain.User, error) {
-	return s.userRepo.SetIsActive(ctx, userID, isActive)
+	var user *domain.User
+	err := s.uow.WithinTx(ctx, func(ctx context.Context, repos repository.Repository) error {
+		before, err := repos.User.Get(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if before.DeletedAt != nil {
+			return domain.ErrUserDeleted
+		}
+
+		user, err = repos.User.SetIsActive(ctx, userID, isActive)
+		if err != nil {
+			return err
+		}
+
+		// Member activity is part of the team resource, so its version moves too.
+		if _, err := repos.Team.BumpVersion(ctx, user.TeamName, 0); err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, repos.Audit, domain.AuditActionUserSetActive, domain.AuditEntityUser, userID, before, user)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// RenameUser changes the user's display name. PRs and history refer to users
+// by ID, so nothing else changes.
+func (s *UserService) RenameUser(ctx context.Context, userID, username string) (*domain.User, error) {
+	var user *domain.User
+	err := s.uow.WithinTx(ctx, func(ctx context.Context, repos repository.Repository) error {
+		before, err := repos.User.Get(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if before.DeletedAt != nil {
+			return domain.ErrUserDeleted
+		}
+
+		renamed := *before
+		renamed.Username = username
+		if err := repos.User.Update(ctx, &renamed); err != nil {
+			return err
+		}
+
+		// Member names are part of the team resource, so its version moves too.
+		if _, err := repos.Team.BumpVersion(ctx, renamed.TeamName, 0); err != nil {
+			return err
+		}
+
+		user = &renamed
+		return recordAudit(ctx, repos.Audit, domain.AuditActionUserRename, domain.AuditEntityUser, userID, before, user)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// DeleteUser soft-deletes the user: it leaves its team and its open reviews
+// move to other active members of each PR author's team. Users who still
+// author open PRs cannot be deleted; merged PRs and history keep pointing at
+// the deleted user. Deleting a deleted user is a no-op.
+func (s *UserService) DeleteUser(ctx context.Context, userID string) (*domain.User, error) {
+	var user *domain.User
+	err := s.uow.WithinTx(ctx, func(ctx context.Context, repos repository.Repository) error {
+		var err error
+		user, err = s.deleteUser(ctx, repos, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s *UserService) deleteUser(ctx context.Context, repos repository.Repository, userID string) (*domain.User, error) {
+	before, err := repos.User.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if before.DeletedAt != nil {
+		return before, nil
+	}
+
+	authored, err := repos.PullRequest.List(ctx, domain.PRFilter{
+		AuthorID: userID,
+		Status:   domain.PRStatusOpen,
+		SortBy:   domain.PRSortCreatedAt,
+		Limit:    1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(authored) > 0 {
+		return nil, domain.ErrUserHasOpenPRs
+	}
+
+	openPRs, err := repos.PullRequest.GetOpenPRsByReviewersForUpdate(ctx, []string{userID})
+	if err != nil {
+		return nil, err
+	}
+
+	deleted := *before
+	now := time.Now()
+	deleted.IsActive = false
+	deleted.DeletedAt = &now
+	if err := repos.User.Update(ctx, &deleted); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.prService.reassignReviews(ctx, repos, openPRs, []string{userID}, domain.AssignmentReasonUserDeleted); err != nil {
+		return nil, err
+	}
+
+	if _, err := repos.Team.BumpVersion(ctx, deleted.TeamName, 0); err != nil {
+		return nil, err
+	}
+
+	if err := recordAudit(ctx, repos.Audit, domain.AuditActionUserDelete, domain.AuditEntityUser, userID, before, &deleted); err != nil {
+		return nil, err
+	}
+	return &deleted, nil
+}
+
+// AnonymizeUser deletes the user (see DeleteUser) and scrubs its username,
+// both from the user record and from the audit log snapshots. The user ID is
+// kept so PR history stays consistent.
+func (s *UserService) AnonymizeUser(ctx context.Context, userID string) (*domain.User, error) {
+	var user *domain.User
+	err := s.uow.WithinTx(ctx, func(ctx context.Context, repos repository.Repository) error {
+		deleted, err := s.deleteUser(ctx, repos, userID)
+		if err != nil {
+			return err
+		}
+		if deleted.Username == domain.AnonymizedUsername {
+			user = deleted
+			return nil
+		}
+
+		anonymized := *deleted
+		anonymized.Username = domain.AnonymizedUsername
+		if err := repos.User.Update(ctx, &anonymized); err != nil {
+			return err
+		}
+
+		if err := repos.Audit.RedactUsername(ctx, userID, domain.AnonymizedUsername); err != nil {
+			return err
+		}
+
+		user = &anonymized
+		return recordAudit(ctx, repos.Audit, domain.AuditActionUserAnonymize, domain.AuditEntityUser, userID, nil, user)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
 }
 
 func (s *UserService) GetUserReviews(ctx context.Context, userID string) ([]domain.PullRequestShort, error) {
@@ -31,9 +203,89 @@ func (s *UserService) GetUserReviews(ctx context.Context, userID string) ([]doma
 	return s.prRepo.GetByReviewer(ctx, userID)
 }
 
-func (s *UserService) GetStats(ctx context.Context, limit int) ([]domain.UserStats, error) {
-	if limit <= 0 {
-		limit = 10
+// GetStats returns the reviewers with the most assignments in the filter's
+// window, 10 by default and at most 100.
+func (s *UserService) GetStats(ctx context.Context, filter domain.UserStatsFilter) ([]domain.UserStats, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = 10
 	}
-	return s.userRepo.GetStats(ctx, limit)
+	if filter.Limit > 100 {
+		filter.Limit = 100
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, domain.ErrInvalidWindow
+	}
+	return s.userRepo.GetStats(ctx, filter)
+}
+
+// ExportStats calls fn for the same rows as GetStats, except that a zero
+// filter.Limit exports every user.
+func (s *UserService) ExportStats(ctx context.Context, filter domain.UserStatsFilter, fn func(*domain.UserStats) error) error {
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return domain.ErrInvalidWindow
+	}
+	return s.userRepo.StreamStats(ctx, filter, fn)
+}
+
+func (s *UserService) GetUser(ctx context.Context, userID string) (*domain.UserProfile, error) {
+	user, err := s.userRepo.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	activity, err := s.userRepo.GetActivity(ctx, []string{userID})
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.UserProfile{User: *user, UserActivity: activity[userID]}, nil
+}
+
+type userCursor struct {
+	Username string `json:"u"`
+	UserID   string `json:"id"`
+}
+
+// ListUsers returns one page of the user directory and the cursor of the next
+// page ("" on the last page). Activity for the whole page is loaded in a
+// single batch.
+func (s *UserService) ListUsers(ctx context.Context, filter domain.UserFilter, cursor string) ([]domain.UserProfile, string, error) {
+	if cursor != "" {
+		var pos userCursor
+		if err := decodeCursor(cursor, &pos); err != nil {
+			return nil, "", err
+		}
+		filter.After = &domain.UserPageKey{Username: pos.Username, UserID: pos.UserID}
+	}
+
+	pageSize := normalizePageSize(filter.Limit)
+	filter.Limit = pageSize + 1
+
+	users, err := s.userRepo.List(ctx, filter)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var next string
+	if len(users) > pageSize {
+		users = users[:pageSize]
+		last := users[pageSize-1]
+		next = encodeCursor(userCursor{Username: last.Username, UserID: last.UserID})
+	}
+
+	userIDs := make([]string, len(users))
+	for i, u := range users {
+		userIDs[i] = u.UserID
+	}
+	activity, err := s.userRepo.GetActivity(ctx, userIDs)
+	if err != nil {
+		return nil, "", err
+	}
+
+	profiles := make([]domain.UserProfile, len(users))
+	for i, u := range users {
+		profiles[i] = domain.UserProfile{User: u, UserActivity: activity[u.UserID]}
+	}
+
+	return profiles, next, nil
 }
@@ -0,0 +1,235 @@
+package http
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"pr-review-service/internal/domain"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	exportFormatCSV    = "csv"
+	exportFormatNDJSON = "ndjson"
+
+	// exportFlushRows is how many rows are buffered before they are pushed
+	// to the client.
+	exportFlushRows = 500
+)
+
+// exportFormat picks the export format from the format query parameter, then
+// from the Accept header, defaulting to CSV. ok is false for an unknown format.
+func exportFormat(r *http.Request) (format string, ok bool) {
+	switch format := r.URL.Query().Get("format"); format {
+	case exportFormatCSV, exportFormatNDJSON:
+		return format, true
+	case "":
+	default:
+		return "", false
+	}
+
+	accept := r.Header.Get("Accept")
+	if strings.Contains(accept, "application/x-ndjson") || strings.Contains(accept, "application/ndjson") {
+		return exportFormatNDJSON, true
+	}
+	return exportFormatCSV, true
+}
+
+// exportWriter streams rows as CSV or NDJSON. The response starts with the
+// first row, so errors that happen before it still get a regular error
+// response.
+type exportWriter struct {
+	w       http.ResponseWriter
+	format  string
+	name    string
+	columns []string
+
+	started bool
+	rows    int
+	csv     *csv.Writer
+	json    *json.Encoder
+}
+
+func newExportWriter(w http.ResponseWriter, format, name string, columns []string) *exportWriter {
+	return &exportWriter{w: w, format: format, name: name, columns: columns}
+}
+
+func (e *exportWriter) start() error {
+	e.started = true
+
+	contentType := "text/csv; charset=utf-8"
+	if e.format == exportFormatNDJSON {
+		contentType = "application/x-ndjson"
+	}
+	e.w.Header().Set("Content-Type", contentType)
+	e.w.Header().Set("Content-Disposition", `attachment; filename="`+e.name+"."+e.format+`"`)
+
+	// Large exports outlive the server's write timeout.
+	_ = http.NewResponseController(e.w).SetWriteDeadline(time.Time{})
+	e.w.WriteHeader(http.StatusOK)
+
+	if e.format == exportFormatNDJSON {
+		e.json = json.NewEncoder(e.w)
+		return nil
+	}
+	e.csv = csv.NewWriter(e.w)
+	return e.csv.Write(e.columns)
+}
+
+// write sends one row: value as an NDJSON line or record as a CSV line.
+func (e *exportWriter) write(value interface{}, record []string) error {
+	if !e.started {
+		if err := e.start(); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	if e.json != nil {
+		err = e.json.Encode(value)
+	} else {
+		err = e.csv.Write(record)
+	}
+	if err != nil {
+		return err
+	}
+
+	e.rows++
+	if e.rows%exportFlushRows == 0 {
+		return e.flush()
+	}
+	return nil
+}
+
+func (e *exportWriter) flush() error {
+	if e.csv != nil {
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return err
+		}
+	}
+	return http.NewResponseController(e.w).Flush()
+}
+
+// finish completes the export started by the handler. Once rows have been
+// sent an error can no longer be reported, so the connection is aborted and
+// the client sees a truncated response instead of a short but valid one.
+func (e *exportWriter) finish(err error) {
+	if err != nil {
+		if !e.started {
+			handleDomainError(e.w, err)
+			return
+		}
+		panic(http.ErrAbortHandler)
+	}
+
+	if !e.started {
+		if err := e.start(); err != nil {
+			panic(http.ErrAbortHandler)
+		}
+	}
+	_ = e.flush()
+}
+
+var (
+	prExportColumns = []string{
+		"pull_request_id", "pull_request_name", "author_id", "status", "created_at", "merged_at", "assigned_reviewers",
+	}
+	historyExportColumns = []string{
+		"pull_request_id", "user_id", "assigned_at", "assigned_by", "assign_reason",
+		"unassigned_at", "unassigned_by", "unassign_reason",
+	}
+	statsExportColumns = []string{"user_id", "username", "review_count"}
+)
+
+// ExportPRs GET /export/pullRequests
+func (h *Handler) ExportPRs(w http.ResponseWriter, r *http.Request) {
+	format, ok := exportFormat(r)
+	if !ok {
+		respondError(w, http.StatusBadRequest, "INVALID_INPUT", "format must be csv or ndjson")
+		return
+	}
+	filter, ok := parsePRFilter(w, r.URL.Query())
+	if !ok {
+		return
+	}
+
+	out := newExportWriter(w, format, "pull_requests", prExportColumns)
+	out.finish(h.prService.ExportPRs(r.Context(), filter, func(pr *domain.PullRequest) error {
+		return out.write(pr, []string{
+			csvText(pr.PullRequestID),
+			csvText(pr.PullRequestName),
+			csvText(pr.AuthorID),
+			string(pr.Status),
+			csvTime(pr.CreatedAt),
+			csvTime(pr.MergedAt),
+			csvText(strings.Join(pr.AssignedReviewers, ";")),
+		})
+	}))
+}
+
+// ExportAssignmentHistory GET /export/history
+func (h *Handler) ExportAssignmentHistory(w http.ResponseWriter, r *http.Request) {
+	format, ok := exportFormat(r)
+	if !ok {
+		respondError(w, http.StatusBadRequest, "INVALID_INPUT", "format must be csv or ndjson")
+		return
+	}
+	filter, ok := parsePRFilter(w, r.URL.Query())
+	if !ok {
+		return
+	}
+
+	out := newExportWriter(w, format, "assignment_history", historyExportColumns)
+	out.finish(h.prService.ExportAssignmentHistory(r.Context(), filter, func(rec *domain.AssignmentRecord) error {
+		return out.write(rec, []string{
+			csvText(rec.PullRequestID),
+			csvText(rec.UserID),
+			csvTime(&rec.AssignedAt),
+			csvText(rec.AssignedBy),
+			string(rec.AssignReason),
+			csvTime(rec.UnassignedAt),
+			csvText(rec.UnassignedBy),
+			string(rec.UnassignReason),
+		})
+	}))
+}
+
+// ExportStats GET /export/stats
+func (h *Handler) ExportStats(w http.ResponseWriter, r *http.Request) {
+	format, ok := exportFormat(r)
+	if !ok {
+		respondError(w, http.StatusBadRequest, "INVALID_INPUT", "format must be csv or ndjson")
+		return
+	}
+	filter, ok := parseUserStatsFilter(w, r.URL.Query())
+	if !ok {
+		return
+	}
+
+	out := newExportWriter(w, format, "stats", statsExportColumns)
+	out.finish(h.userService.ExportStats(r.Context(), filter, func(stat *domain.UserStats) error {
+		return out.write(stat, []string{
+			csvText(stat.UserID),
+			csvText(stat.Username),
+			strconv.Itoa(stat.ReviewCount),
+		})
+	}))
+}
+
+// csvText keeps spreadsheets from evaluating user-supplied text as a formula.
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+func csvTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
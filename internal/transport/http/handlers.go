@@ -2,26 +2,41 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"net/url"
 	"pr-review-service/internal/domain"
 	"pr-review-service/internal/service"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Handler struct {
-	teamService *service.TeamService
-	userService *service.UserService
-	prService   *service.PRService
+	teamService   *service.TeamService
+	userService   *service.UserService
+	prService     *service.PRService
+	auditService  *service.AuditService
+	statsService  *service.StatsService
+	importService *service.ImportService
 }
 
 func NewHandler(
 	teamService *service.TeamService,
 	userService *service.UserService,
 	prService *service.PRService,
+	auditService *service.AuditService,
+	statsService *service.StatsService,
+	importService *service.ImportService,
 ) *Handler {
 	return &Handler{
-		teamService: teamService,
-		userService: userService,
-		prService:   prService,
+		teamService:   teamService,
+		userService:   userService,
+		prService:     prService,
+		auditService:  auditService,
+		statsService:  statsService,
+		importService: importService,
 	}
 }
 
@@ -39,6 +54,7 @@ func (h *Handler) CreateTeam(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	setETag(w, createdTeam.Version)
 	respondJSON(w, http.StatusCreated, map[string]interface{}{
 		"team": createdTeam,
 	})
@@ -58,6 +74,7 @@ func (h *Handler) GetTeam(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	setETag(w, team.Version)
 	respondJSON(w, http.StatusOK, team)
 }
 
@@ -84,12 +101,139 @@ func (h *Handler) SetIsActive(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// RenameUser POST /users/rename
+func (h *Handler) RenameUser(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		UserID   string `json:"user_id"`
+		Username string `json:"username"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_INPUT", "invalid request body")
+		return
+	}
+	if strings.TrimSpace(req.Username) == "" {
+		respondError(w, http.StatusBadRequest, "INVALID_INPUT", "username is required")
+		return
+	}
+
+	user, err := h.userService.RenameUser(r.Context(), req.UserID, req.Username)
+	if err != nil {
+		handleDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"user": user,
+	})
+}
+
+// DeleteUser POST /users/delete
+func (h *Handler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		UserID string `json:"user_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_INPUT", "invalid request body")
+		return
+	}
+
+	user, err := h.userService.DeleteUser(r.Context(), req.UserID)
+	if err != nil {
+		handleDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"user": user,
+	})
+}
+
+// AnonymizeUser POST /users/anonymize
+func (h *Handler) AnonymizeUser(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		UserID string `json:"user_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_INPUT", "invalid request body")
+		return
+	}
+
+	user, err := h.userService.AnonymizeUser(r.Context(), req.UserID)
+	if err != nil {
+		handleDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"user": user,
+	})
+}
+
+// GetUser GET /users/get
+func (h *Handler) GetUser(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		respondError(w, http.StatusBadRequest, "INVALID_INPUT", "user_id is required")
+		return
+	}
+
+	user, err := h.userService.GetUser(r.Context(), userID)
+	if err != nil {
+		handleDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"user": user,
+	})
+}
+
+// ListUsers GET /users/list
+func (h *Handler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := domain.UserFilter{
+		TeamName:   query.Get("team_name"),
+		NamePrefix: query.Get("name_prefix"),
+	}
+
+	if value := query.Get("is_active"); value != "" {
+		isActive, err := strconv.ParseBool(value)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "INVALID_INPUT", "is_active must be true or false")
+			return
+		}
+		filter.IsActive = &isActive
+	}
+
+	limit, err := parseLimitParam(query.Get("limit"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_INPUT", "limit must be a positive integer")
+		return
+	}
+	filter.Limit = limit
+
+	users, next, err := h.userService.ListUsers(r.Context(), filter, query.Get("cursor"))
+	if err != nil {
+		handleDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"users":       users,
+		"next_cursor": next,
+	})
+}
+
 // CreatePR POST /pullRequest/create
 func (h *Handler) CreatePR(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		PullRequestID   string `json:"pull_request_id"`
-		PullRequestName string `json:"pull_request_name"`
-		AuthorID        string `json:"author_id"`
+		PullRequestID      string   `json:"pull_request_id"`
+		PullRequestName    string   `json:"pull_request_name"`
+		AuthorID           string   `json:"author_id"`
+		RequestedReviewers []string `json:"requested_reviewers"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -97,17 +241,38 @@ func (h *Handler) CreatePR(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	pr, err := h.prService.CreatePR(r.Context(), req.PullRequestID, req.PullRequestName, req.AuthorID)
+	pr, err := h.prService.CreatePR(r.Context(), req.PullRequestID, req.PullRequestName, req.AuthorID, req.RequestedReviewers)
 	if err != nil {
 		handleDomainError(w, err)
 		return
 	}
 
+	setETag(w, pr.Version)
 	respondJSON(w, http.StatusCreated, map[string]interface{}{
 		"pr": pr,
 	})
 }
 
+// GetPR GET /pullRequest/get
+func (h *Handler) GetPR(w http.ResponseWriter, r *http.Request) {
+	prID := r.URL.Query().Get("pull_request_id")
+	if prID == "" {
+		respondError(w, http.StatusBadRequest, "INVALID_INPUT", "pull_request_id is required")
+		return
+	}
+
+	pr, err := h.prService.GetPR(r.Context(), prID)
+	if err != nil {
+		handleDomainError(w, err)
+		return
+	}
+
+	setETag(w, pr.Version)
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"pr": pr,
+	})
+}
+
 // MergePR POST /pullRequest/merge
 func (h *Handler) MergePR(w http.ResponseWriter, r *http.Request) {
 	var req struct {
@@ -119,12 +284,63 @@ func (h *Handler) MergePR(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	pr, err := h.prService.MergePR(r.Context(), req.PullRequestID)
+	ifMatch, ok := parseIfMatch(r)
+	if !ok {
+		respondError(w, http.StatusBadRequest, "INVALID_INPUT", "invalid If-Match header")
+		return
+	}
+
+	pr, err := h.prService.MergePR(r.Context(), req.PullRequestID, ifMatch)
+	if err != nil {
+		handleDomainError(w, err)
+		return
+	}
+
+	setETag(w, pr.Version)
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"pr": pr,
+	})
+}
+
+// UpdatePR POST /pullRequest/update
+func (h *Handler) UpdatePR(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		PullRequestID   string         `json:"pull_request_id"`
+		PullRequestName *string        `json:"pull_request_name"`
+		AuthorID        *string        `json:"author_id"`
+		Description     *string        `json:"description"`
+		URL             *string        `json:"url"`
+		Repository      *string        `json:"repository"`
+		Labels          *[]string      `json:"labels"`
+		Size            *domain.PRSize `json:"size"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_INPUT", "invalid request body")
+		return
+	}
+
+	ifMatch, ok := parseIfMatch(r)
+	if !ok {
+		respondError(w, http.StatusBadRequest, "INVALID_INPUT", "invalid If-Match header")
+		return
+	}
+
+	pr, err := h.prService.UpdatePR(r.Context(), req.PullRequestID, domain.PRUpdate{
+		PullRequestName: req.PullRequestName,
+		AuthorID:        req.AuthorID,
+		Description:     req.Description,
+		URL:             req.URL,
+		Repository:      req.Repository,
+		Labels:          req.Labels,
+		Size:            req.Size,
+	}, ifMatch)
 	if err != nil {
 		handleDomainError(w, err)
 		return
 	}
 
+	setETag(w, pr.Version)
 	respondJSON(w, http.StatusOK, map[string]interface{}{
 		"pr": pr,
 	})
@@ -142,18 +358,186 @@ func (h *Handler) ReassignReviewer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	pr, replacedBy, err := h.prService.ReassignReviewer(r.Context(), req.PullRequestID, req.OldUserID)
+	ifMatch, ok := parseIfMatch(r)
+	if !ok {
+		respondError(w, http.StatusBadRequest, "INVALID_INPUT", "invalid If-Match header")
+		return
+	}
+
+	pr, replacedBy, err := h.prService.ReassignReviewer(r.Context(), req.PullRequestID, req.OldUserID, ifMatch)
 	if err != nil {
 		handleDomainError(w, err)
 		return
 	}
 
+	setETag(w, pr.Version)
 	respondJSON(w, http.StatusOK, map[string]interface{}{
 		"pr":          pr,
 		"replaced_by": replacedBy,
 	})
 }
 
+// AddReviewer POST /pullRequest/addReviewer
+func (h *Handler) AddReviewer(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		PullRequestID string `json:"pull_request_id"`
+		UserID        string `json:"user_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_INPUT", "invalid request body")
+		return
+	}
+
+	ifMatch, ok := parseIfMatch(r)
+	if !ok {
+		respondError(w, http.StatusBadRequest, "INVALID_INPUT", "invalid If-Match header")
+		return
+	}
+
+	pr, err := h.prService.AddReviewer(r.Context(), req.PullRequestID, req.UserID, ifMatch)
+	if err != nil {
+		handleDomainError(w, err)
+		return
+	}
+
+	setETag(w, pr.Version)
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"pr": pr,
+	})
+}
+
+// RemoveReviewer POST /pullRequest/removeReviewer
+func (h *Handler) RemoveReviewer(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		PullRequestID string `json:"pull_request_id"`
+		UserID        string `json:"user_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_INPUT", "invalid request body")
+		return
+	}
+
+	ifMatch, ok := parseIfMatch(r)
+	if !ok {
+		respondError(w, http.StatusBadRequest, "INVALID_INPUT", "invalid If-Match header")
+		return
+	}
+
+	pr, err := h.prService.RemoveReviewer(r.Context(), req.PullRequestID, req.UserID, ifMatch)
+	if err != nil {
+		handleDomainError(w, err)
+		return
+	}
+
+	setETag(w, pr.Version)
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"pr": pr,
+	})
+}
+
+// SwapReviewer POST /pullRequest/swapReviewer
+func (h *Handler) SwapReviewer(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		PullRequestID string `json:"pull_request_id"`
+		OldUserID     string `json:"old_user_id"`
+		NewUserID     string `json:"new_user_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_INPUT", "invalid request body")
+		return
+	}
+
+	ifMatch, ok := parseIfMatch(r)
+	if !ok {
+		respondError(w, http.StatusBadRequest, "INVALID_INPUT", "invalid If-Match header")
+		return
+	}
+
+	pr, err := h.prService.SwapReviewer(r.Context(), req.PullRequestID, req.OldUserID, req.NewUserID, ifMatch)
+	if err != nil {
+		handleDomainError(w, err)
+		return
+	}
+
+	setETag(w, pr.Version)
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"pr":          pr,
+		"replaced_by": req.NewUserID,
+	})
+}
+
+// RerollReviewers POST /pullRequest/reroll
+func (h *Handler) RerollReviewers(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		PullRequestID string `json:"pull_request_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_INPUT", "invalid request body")
+		return
+	}
+
+	ifMatch, ok := parseIfMatch(r)
+	if !ok {
+		respondError(w, http.StatusBadRequest, "INVALID_INPUT", "invalid If-Match header")
+		return
+	}
+
+	pr, err := h.prService.RerollReviewers(r.Context(), req.PullRequestID, ifMatch)
+	if err != nil {
+		handleDomainError(w, err)
+		return
+	}
+
+	setETag(w, pr.Version)
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"pr": pr,
+	})
+}
+
+// ListPRs GET /pullRequests
+func (h *Handler) ListPRs(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter, ok := parsePRFilter(w, query)
+	if !ok {
+		return
+	}
+
+	prs, next, err := h.prService.ListPRs(r.Context(), filter, query.Get("cursor"))
+	if err != nil {
+		handleDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"pull_requests": prs,
+		"next_cursor":   next,
+	})
+}
+
+// GetAssignmentHistory GET /pullRequest/history
+func (h *Handler) GetAssignmentHistory(w http.ResponseWriter, r *http.Request) {
+	prID := r.URL.Query().Get("pull_request_id")
+	if prID == "" {
+		respondError(w, http.StatusBadRequest, "INVALID_INPUT", "pull_request_id is required")
+		return
+	}
+
+	history, err := h.prService.GetAssignmentHistory(r.Context(), prID)
+	if err != nil {
+		handleDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"pull_request_id": prID,
+		"history":         history,
+	})
+}
+
 // GetUserReviews GET /users/getReview
 func (h *Handler) GetUserReviews(w http.ResponseWriter, r *http.Request) {
 	userID := r.URL.Query().Get("user_id")
@@ -176,7 +560,12 @@ func (h *Handler) GetUserReviews(w http.ResponseWriter, r *http.Request) {
 
 // GetStats  GET /stats (Бонус)
 func (h *Handler) GetStats(w http.ResponseWriter, r *http.Request) {
-	stats, err := h.userService.GetStats(r.Context(), 10)
+	filter, ok := parseUserStatsFilter(w, r.URL.Query())
+	if !ok {
+		return
+	}
+
+	stats, err := h.userService.GetStats(r.Context(), filter)
 	if err != nil {
 		handleDomainError(w, err)
 		return
@@ -187,6 +576,58 @@ func (h *Handler) GetStats(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// GetReviewMetrics GET /stats/metrics
+func (h *Handler) GetReviewMetrics(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	from, err := parseTimeParam(query.Get("from"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_INPUT", "from must be an RFC 3339 timestamp")
+		return
+	}
+	to, err := parseTimeParam(query.Get("to"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_INPUT", "to must be an RFC 3339 timestamp")
+		return
+	}
+
+	metrics, err := h.statsService.GetReviewMetrics(r.Context(), query.Get("team_name"), query.Get("user_id"), from, to)
+	if err != nil {
+		handleDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"metrics": metrics,
+	})
+}
+
+// GetFairnessReport GET /stats/fairness
+func (h *Handler) GetFairnessReport(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	from, err := parseTimeParam(query.Get("from"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_INPUT", "from must be an RFC 3339 timestamp")
+		return
+	}
+	to, err := parseTimeParam(query.Get("to"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_INPUT", "to must be an RFC 3339 timestamp")
+		return
+	}
+
+	report, err := h.statsService.GetFairnessReport(r.Context(), query.Get("team_name"), from, to)
+	if err != nil {
+		handleDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"report": report,
+	})
+}
+
 // DeactivateTeam POST /team/{team_name}/deactivate-all (Бонус)
 func (h *Handler) DeactivateTeam(w http.ResponseWriter, r *http.Request) {
 	teamName := r.URL.Query().Get("team_name")
@@ -195,13 +636,160 @@ func (h *Handler) DeactivateTeam(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.prService.DeactivateTeamAndReassign(r.Context(), teamName); err != nil {
+	ifMatch, ok := parseIfMatch(r)
+	if !ok {
+		respondError(w, http.StatusBadRequest, "INVALID_INPUT", "invalid If-Match header")
+		return
+	}
+
+	dryRun, ok := parseDryRun(w, r.URL.Query())
+	if !ok {
+		return
+	}
+
+	var team *domain.Team
+	var reassignments []domain.ReviewerReassignment
+	var err error
+	message := "team deactivated and reviewers reassigned successfully"
+	if dryRun {
+		team, reassignments, err = h.prService.PreviewTeamDeactivation(r.Context(), teamName, ifMatch)
+		message = "dry run, nothing was changed"
+	} else {
+		team, reassignments, err = h.prService.DeactivateTeamAndReassign(r.Context(), teamName, ifMatch)
+	}
+	if err != nil {
 		handleDomainError(w, err)
 		return
 	}
 
+	setETag(w, team.Version)
 	respondJSON(w, http.StatusOK, map[string]interface{}{
-		"message": "team deactivated and reviewers reassigned successfully",
+		"message":       message,
+		"dry_run":       dryRun,
+		"team":          team,
+		"reassignments": reassignments,
+	})
+}
+
+// ActivateTeam POST /team/activate-all
+func (h *Handler) ActivateTeam(w http.ResponseWriter, r *http.Request) {
+	teamName := r.URL.Query().Get("team_name")
+	if teamName == "" {
+		respondError(w, http.StatusBadRequest, "INVALID_INPUT", "team_name is required")
+		return
+	}
+
+	ifMatch, ok := parseIfMatch(r)
+	if !ok {
+		respondError(w, http.StatusBadRequest, "INVALID_INPUT", "invalid If-Match header")
+		return
+	}
+
+	dryRun, ok := parseDryRun(w, r.URL.Query())
+	if !ok {
+		return
+	}
+
+	// The body is optional: without one every member is activated.
+	var req struct {
+		UserIDs   []string `json:"user_ids"`
+		Rebalance bool     `json:"rebalance"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondError(w, http.StatusBadRequest, "INVALID_INPUT", "invalid request body")
+		return
+	}
+
+	var team *domain.Team
+	var reassignments []domain.ReviewerReassignment
+	var err error
+	message := "team activated successfully"
+	if dryRun {
+		team, reassignments, err = h.prService.PreviewTeamActivation(r.Context(), teamName, req.UserIDs, req.Rebalance, ifMatch)
+		message = "dry run, nothing was changed"
+	} else {
+		team, reassignments, err = h.prService.ActivateTeam(r.Context(), teamName, req.UserIDs, req.Rebalance, ifMatch)
+	}
+	if err != nil {
+		handleDomainError(w, err)
+		return
+	}
+
+	setETag(w, team.Version)
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"message":       message,
+		"dry_run":       dryRun,
+		"team":          team,
+		"reassignments": reassignments,
+	})
+}
+
+// RebalanceTeam POST /team/rebalance
+func (h *Handler) RebalanceTeam(w http.ResponseWriter, r *http.Request) {
+	teamName := r.URL.Query().Get("team_name")
+	if teamName == "" {
+		respondError(w, http.StatusBadRequest, "INVALID_INPUT", "team_name is required")
+		return
+	}
+
+	dryRun, ok := parseDryRun(w, r.URL.Query())
+	if !ok {
+		return
+	}
+
+	var reassignments []domain.ReviewerReassignment
+	var err error
+	message := "reviews rebalanced successfully"
+	if dryRun {
+		reassignments, err = h.prService.PreviewTeamRebalance(r.Context(), teamName)
+		message = "dry run, nothing was changed"
+	} else {
+		reassignments, err = h.prService.RebalanceTeam(r.Context(), teamName)
+	}
+	if err != nil {
+		handleDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"message":       message,
+		"dry_run":       dryRun,
+		"reassignments": reassignments,
+	})
+}
+
+// ListAuditEvents GET /audit
+func (h *Handler) ListAuditEvents(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := domain.AuditFilter{
+		EntityType: query.Get("entity_type"),
+		EntityID:   query.Get("entity_id"),
+		Actor:      query.Get("actor"),
+	}
+
+	var err error
+	if filter.From, err = parseTimeParam(query.Get("from")); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_INPUT", "from must be an RFC 3339 timestamp")
+		return
+	}
+	if filter.To, err = parseTimeParam(query.Get("to")); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_INPUT", "to must be an RFC 3339 timestamp")
+		return
+	}
+	if filter.Limit, err = parseLimitParam(query.Get("limit")); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_INPUT", "limit must be a positive integer")
+		return
+	}
+
+	events, next, err := h.auditService.ListEvents(r.Context(), filter, query.Get("cursor"))
+	if err != nil {
+		handleDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"events":      events,
+		"next_cursor": next,
 	})
 }
 
@@ -211,3 +799,111 @@ func (h *Handler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 		"status": "ok",
 	})
 }
+
+// parseDryRun reads the dry_run flag bulk operations accept, answering 400
+// itself when it is malformed.
+func parseDryRun(w http.ResponseWriter, query url.Values) (dryRun, ok bool) {
+	value := query.Get("dry_run")
+	if value == "" {
+		return false, true
+	}
+	dryRun, err := strconv.ParseBool(value)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_INPUT", "dry_run must be true or false")
+		return false, false
+	}
+	return dryRun, true
+}
+
+func parseTimeParam(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	// Postgres compares the bound against TIMESTAMP columns without a zone,
+	// so it has to be in UTC like the stored values.
+	t = t.UTC()
+	return &t, nil
+}
+
+func parseLimitParam(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit <= 0 {
+		return 0, domain.ErrInvalidInput
+	}
+	return limit, nil
+}
+
+// parsePRFilter reads PR listing filters from the query string. On invalid
+// input it writes a 400 response and returns ok == false.
+func parsePRFilter(w http.ResponseWriter, query url.Values) (filter domain.PRFilter, ok bool) {
+	filter = domain.PRFilter{
+		Status:     domain.PRStatus(query.Get("status")),
+		AuthorID:   query.Get("author_id"),
+		ReviewerID: query.Get("reviewer_id"),
+		TeamName:   query.Get("team_name"),
+		SortBy:     domain.PRSortCreatedAt,
+		Descending: true,
+	}
+	if filter.Status != "" && filter.Status != domain.PRStatusOpen && filter.Status != domain.PRStatusMerged {
+		respondError(w, http.StatusBadRequest, "INVALID_INPUT", "status must be OPEN or MERGED")
+		return filter, false
+	}
+
+	if sort := query.Get("sort"); sort != "" {
+		filter.Descending = strings.HasPrefix(sort, "-")
+		filter.SortBy = domain.PRSortField(strings.TrimPrefix(sort, "-"))
+	}
+
+	timeParams := []struct {
+		name string
+		dst  **time.Time
+	}{
+		{"created_from", &filter.CreatedFrom},
+		{"created_to", &filter.CreatedTo},
+		{"merged_from", &filter.MergedFrom},
+		{"merged_to", &filter.MergedTo},
+	}
+	for _, p := range timeParams {
+		t, err := parseTimeParam(query.Get(p.name))
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "INVALID_INPUT", p.name+" must be an RFC 3339 timestamp")
+			return filter, false
+		}
+		*p.dst = t
+	}
+
+	limit, err := parseLimitParam(query.Get("limit"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_INPUT", "limit must be a positive integer")
+		return filter, false
+	}
+	filter.Limit = limit
+
+	return filter, true
+}
+
+// parseUserStatsFilter reads the stats window and limit from the query string.
+// On invalid input it writes a 400 response and returns ok == false.
+func parseUserStatsFilter(w http.ResponseWriter, query url.Values) (filter domain.UserStatsFilter, ok bool) {
+	var err error
+	if filter.From, err = parseTimeParam(query.Get("from")); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_INPUT", "from must be an RFC 3339 timestamp")
+		return filter, false
+	}
+	if filter.To, err = parseTimeParam(query.Get("to")); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_INPUT", "to must be an RFC 3339 timestamp")
+		return filter, false
+	}
+	if filter.Limit, err = parseLimitParam(query.Get("limit")); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_INPUT", "limit must be a positive integer")
+		return filter, false
+	}
+	return filter, true
+}
@@ -0,0 +1,73 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"pr-review-service/internal/domain"
+	"pr-review-service/internal/importer"
+)
+
+const maxImportSize = 64 << 20
+
+// Import POST /import
+//
+// The bundle is either a JSON document or a multipart form with one CSV file
+// per table, named after the table.
+func (h *Handler) Import(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+
+	bundle, err := decodeImportBundle(r)
+	if err == nil {
+		var summary *domain.ImportSummary
+		summary, err = h.importService.Import(r.Context(), bundle)
+		if err == nil {
+			respondJSON(w, http.StatusOK, map[string]interface{}{
+				"imported": summary,
+			})
+			return
+		}
+	}
+
+	var importErr *domain.ImportError
+	switch {
+	case errors.As(err, &importErr):
+		respondJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":      ErrorDetail{Code: domain.ErrCodeInvalidInput, Message: importErr.Error()},
+			"row_errors": importErr.Rows,
+		})
+	case errors.Is(err, domain.ErrInvalidInput):
+		respondError(w, http.StatusBadRequest, "INVALID_INPUT", err.Error())
+	default:
+		handleDomainError(w, err)
+	}
+}
+
+func decodeImportBundle(r *http.Request) (*domain.ImportBundle, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return importer.DecodeJSON(r.Body)
+	}
+
+	if err := r.ParseMultipartForm(maxImportSize); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	tables := make(map[string]io.Reader)
+	for name, files := range r.MultipartForm.File {
+		if len(files) != 1 {
+			return nil, fmt.Errorf("%w: expected one file for %s", domain.ErrInvalidInput, name)
+		}
+		f, err := files[0].Open()
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		tables[name] = f
+	}
+
+	return importer.DecodeCSV(tables)
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"net/http"
 	"pr-review-service/internal/domain"
+	"strconv"
+	"strings"
 )
 
 type ErrorResponse struct {
@@ -37,12 +39,18 @@ func handleDomainError(w http.ResponseWriter, err error) {
 		status := http.StatusBadRequest
 
 		switch domainErr.Code {
-		case domain.ErrCodeTeamExists, domain.ErrCodePRExists:
+		case domain.ErrCodeTeamExists, domain.ErrCodePRExists, domain.ErrCodeUserExists:
 			status = http.StatusConflict
 		case domain.ErrCodePRMerged, domain.ErrCodeNotAssigned, domain.ErrCodeNoCandidate:
 			status = http.StatusConflict
+		case domain.ErrCodeUserDeleted, domain.ErrCodeUserHasOpenPRs:
+			status = http.StatusConflict
+		case domain.ErrCodeAlreadyAssigned, domain.ErrCodeReviewerInactive, domain.ErrCodeReviewerIsAuthor, domain.ErrCodeTooManyReviewers:
+			status = http.StatusConflict
 		case domain.ErrCodeNotFound:
 			status = http.StatusNotFound
+		case domain.ErrCodeVersionMismatch:
+			status = http.StatusPreconditionFailed
 		}
 
 		respondError(w, status, domainErr.Code, domainErr.Message)
@@ -51,3 +59,38 @@ func handleDomainError(w http.ResponseWriter, err error) {
 
 	respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
 }
+
+func setETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", strconv.Quote(strconv.Itoa(version)))
+}
+
+// parseIfMatch reads the If-Match header, a "*" or a list of entity tags.
+// Strong tags match the version they quote; weak tags never match, as RFC 9110
+// requires for If-Match. ok is false for a malformed header.
+func parseIfMatch(r *http.Request) (match domain.VersionMatch, ok bool) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return match, true
+	}
+
+	match.Required = true
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		weak := strings.HasPrefix(tag, "W/")
+		tag = strings.TrimPrefix(tag, "W/")
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' || strings.Contains(tag[1:len(tag)-1], `"`) {
+			return domain.VersionMatch{}, false
+		}
+		if weak {
+			continue
+		}
+		// Tags that are not one of our versions are valid but never match.
+		if version, err := strconv.Atoi(tag[1 : len(tag)-1]); err == nil && version > 0 {
+			match.Versions = append(match.Versions, version)
+		}
+	}
+	return match, true
+}
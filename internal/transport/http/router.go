@@ -1,6 +1,9 @@
 package http
 
 import (
+	"net/http"
+	"pr-review-service/internal/service"
+
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 )
@@ -12,6 +15,7 @@ func NewRouter(h *Handler) *chi.Mux {
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.RequestID)
+	r.Use(actorMiddleware)
 
 	// Health check
 	r.Get("/health", h.HealthCheck)
@@ -20,18 +24,57 @@ func NewRouter(h *Handler) *chi.Mux {
 	r.Post("/team/add", h.CreateTeam)
 	r.Get("/team/get", h.GetTeam)
 	r.Post("/team/deactivate-all", h.DeactivateTeam) // Bonus task
+	r.Post("/team/activate-all", h.ActivateTeam)
+	r.Post("/team/rebalance", h.RebalanceTeam)
 
 	// Users
 	r.Post("/users/setIsActive", h.SetIsActive)
+	r.Post("/users/rename", h.RenameUser)
+	r.Post("/users/delete", h.DeleteUser)
+	r.Post("/users/anonymize", h.AnonymizeUser)
 	r.Get("/users/getReview", h.GetUserReviews)
+	r.Get("/users/get", h.GetUser)
+	r.Get("/users/list", h.ListUsers)
 
 	// Pull Requests
+	r.Get("/pullRequests", h.ListPRs)
 	r.Post("/pullRequest/create", h.CreatePR)
+	r.Get("/pullRequest/get", h.GetPR)
 	r.Post("/pullRequest/merge", h.MergePR)
+	r.Post("/pullRequest/update", h.UpdatePR)
 	r.Post("/pullRequest/reassign", h.ReassignReviewer)
+	r.Post("/pullRequest/addReviewer", h.AddReviewer)
+	r.Post("/pullRequest/removeReviewer", h.RemoveReviewer)
+	r.Post("/pullRequest/swapReviewer", h.SwapReviewer)
+	r.Post("/pullRequest/reroll", h.RerollReviewers)
+	r.Get("/pullRequest/history", h.GetAssignmentHistory)
 
 	// Stats (Bonus task)
 	r.Get("/stats", h.GetStats)
+	r.Get("/stats/metrics", h.GetReviewMetrics)
+	r.Get("/stats/fairness", h.GetFairnessReport)
+
+	// Audit
+	r.Get("/audit", h.ListAuditEvents)
+
+	// Import
+	r.Post("/import", h.Import)
+
+	// Exports
+	r.Get("/export/pullRequests", h.ExportPRs)
+	r.Get("/export/history", h.ExportAssignmentHistory)
+	r.Get("/export/stats", h.ExportStats)
 
 	return r
 }
+
+// actorMiddleware attributes changes made by the request to the caller named
+// in the X-Actor header.
+func actorMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if actor := r.Header.Get("X-Actor"); actor != "" {
+			r = r.WithContext(service.WithActor(r.Context(), actor))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS pr_assignment_history (
+    id BIGSERIAL PRIMARY KEY,
+    pull_request_id VARCHAR(255) NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    user_id VARCHAR(255) NOT NULL REFERENCES users(user_id),
+    assigned_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    assigned_by VARCHAR(255) NOT NULL,
+    assign_reason VARCHAR(50) NOT NULL,
+    unassigned_at TIMESTAMP,
+    unassigned_by VARCHAR(255),
+    unassign_reason VARCHAR(50)
+);
+
+CREATE INDEX IF NOT EXISTS idx_assignment_history_pr ON pr_assignment_history(pull_request_id, assigned_at);
+CREATE INDEX IF NOT EXISTS idx_assignment_history_user ON pr_assignment_history(user_id);
+-- At most one open entry per reviewer and PR, mirroring pr_reviewers.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_assignment_history_open
+    ON pr_assignment_history(pull_request_id, user_id) WHERE unassigned_at IS NULL;
+
+-- Assignments made before history was tracked start out as initial ones.
+INSERT INTO pr_assignment_history (pull_request_id, user_id, assigned_at, assigned_by, assign_reason)
+SELECT r.pull_request_id, r.user_id, r.assigned_at, 'system', 'INITIAL'
+FROM pr_reviewers r
+WHERE NOT EXISTS (
+    SELECT 1 FROM pr_assignment_history h
+    WHERE h.pull_request_id = r.pull_request_id AND h.user_id = r.user_id AND h.unassigned_at IS NULL
+);
@@ -0,0 +1,4 @@
+CREATE INDEX IF NOT EXISTS idx_pr_created_at ON pull_requests(created_at, pull_request_id);
+CREATE INDEX IF NOT EXISTS idx_pr_merged_at ON pull_requests((COALESCE(merged_at, 'infinity'::timestamp)), pull_request_id);
+CREATE INDEX IF NOT EXISTS idx_pr_name ON pull_requests(pull_request_name, pull_request_id);
+CREATE INDEX IF NOT EXISTS idx_pr_status_created_at ON pull_requests(status, created_at);
@@ -0,0 +1,44 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users(deleted_at) WHERE deleted_at IS NULL;
+
+-- redact_username replaces the username of every object carrying the given
+-- user_id anywhere inside an audit snapshot.
+CREATE OR REPLACE FUNCTION redact_username(doc jsonb, uid text, replacement text) RETURNS jsonb AS $$
+DECLARE
+    result jsonb;
+BEGIN
+    IF doc IS NULL THEN
+        RETURN NULL;
+    END IF;
+
+    CASE jsonb_typeof(doc)
+    WHEN 'object' THEN
+        SELECT COALESCE(jsonb_object_agg(key,
+                   CASE WHEN key = 'username' AND doc->>'user_id' = uid
+                        THEN to_jsonb(replacement)
+                        ELSE redact_username(value, uid, replacement) END), '{}'::jsonb)
+        INTO result
+        FROM jsonb_each(doc);
+        RETURN result;
+    WHEN 'array' THEN
+        SELECT COALESCE(jsonb_agg(redact_username(value, uid, replacement) ORDER BY ord), '[]'::jsonb)
+        INTO result
+        FROM jsonb_array_elements(doc) WITH ORDINALITY AS e(value, ord);
+        RETURN result;
+    ELSE
+        RETURN doc;
+    END CASE;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+-- The audit log stays append-only, except that anonymization may redact
+-- usernames inside snapshots when it explicitly enables app.audit_redaction.
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND current_setting('app.audit_redaction', true) = 'on' THEN
+        RETURN NEW;
+    END IF;
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
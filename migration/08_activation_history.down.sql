@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS trg_users_activation_history ON users;
+DROP FUNCTION IF EXISTS record_user_activation();
+DROP TABLE IF EXISTS user_activation_history;
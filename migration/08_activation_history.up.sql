@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS user_activation_history (
+    id BIGSERIAL PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL REFERENCES users(user_id),
+    is_active BOOLEAN NOT NULL,
+    changed_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_activation_history_user ON user_activation_history(user_id, changed_at, id);
+
+-- Every path that creates a user or flips is_active, including bulk team
+-- deactivation, goes through this trigger.
+CREATE OR REPLACE FUNCTION record_user_activation() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' OR NEW.is_active IS DISTINCT FROM OLD.is_active THEN
+        INSERT INTO user_activation_history (user_id, is_active) VALUES (NEW.user_id, NEW.is_active);
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_users_activation_history ON users;
+CREATE TRIGGER trg_users_activation_history
+    AFTER INSERT OR UPDATE OF is_active ON users
+    FOR EACH ROW EXECUTE FUNCTION record_user_activation();
+
+-- Users that predate the history have been in their current state forever.
+INSERT INTO user_activation_history (user_id, is_active, changed_at)
+SELECT u.user_id, u.is_active, '-infinity'::timestamp
+FROM users u
+WHERE NOT EXISTS (SELECT 1 FROM user_activation_history h WHERE h.user_id = u.user_id);
@@ -0,0 +1,6 @@
+ALTER TABLE pull_requests
+    DROP COLUMN IF EXISTS size,
+    DROP COLUMN IF EXISTS labels,
+    DROP COLUMN IF EXISTS repository,
+    DROP COLUMN IF EXISTS url,
+    DROP COLUMN IF EXISTS description;
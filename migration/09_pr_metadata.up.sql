@@ -0,0 +1,7 @@
+ALTER TABLE pull_requests
+    ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS url TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS repository VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS labels TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS size VARCHAR(2) NOT NULL DEFAULT ''
+        CONSTRAINT pull_requests_size_check CHECK (size IN ('', 'XS', 'S', 'M', 'L', 'XL'));
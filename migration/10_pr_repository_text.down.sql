@@ -0,0 +1 @@
+ALTER TABLE pull_requests ALTER COLUMN repository TYPE VARCHAR(255) USING left(repository, 255);
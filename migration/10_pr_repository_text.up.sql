@@ -0,0 +1,2 @@
+-- SQLite and the memory store put no limit on repository names.
+ALTER TABLE pull_requests ALTER COLUMN repository TYPE TEXT;
@@ -0,0 +1,26 @@
+// Package migration embeds the SQL migrations so the binary can migrate a
+// database without shipping the files next to it.
+package migration
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// FS holds the Postgres schema migrations at its root and the demo data under
+// seed/. The SQLite migrations live under sqlite/ in the same layout.
+//
+//go:embed *.sql seed/*.sql sqlite/*.sql sqlite/seed/*.sql
+var FS embed.FS
+
+// SQLite holds the SQLite schema migrations at its root and the demo data
+// under seed/.
+var SQLite = mustSub(FS, "sqlite")
+
+func mustSub(fsys fs.FS, dir string) fs.FS {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
@@ -0,0 +1,12 @@
+DELETE FROM pull_requests WHERE pull_request_id IN ('pr-1001', 'pr-1002', 'pr-1003', 'pr-1004');
+
+DELETE FROM user_activation_history
+WHERE user_id IN ('u1', 'u2', 'u3', 'u4', 'u6', 'u7', 'u8', 'u9', 'u10', 'u11', 'u12', 'u13', 'u14');
+
+DELETE FROM users
+WHERE user_id IN ('u1', 'u2', 'u3', 'u4', 'u6', 'u7', 'u8', 'u9', 'u10', 'u11', 'u12', 'u13', 'u14');
+
+-- Keep demo teams that real users have joined since.
+DELETE FROM teams t
+WHERE t.team_name IN ('backend', 'frontend', 'devops', 'mobile')
+  AND NOT EXISTS (SELECT 1 FROM users u WHERE u.team_name = t.team_name);
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS user_activation_history;
+DROP TABLE IF EXISTS pr_assignment_history;
+DROP TABLE IF EXISTS audit_redaction;
+DROP TABLE IF EXISTS audit_events;
+DROP TABLE IF EXISTS pr_reviewers;
+DROP TABLE IF EXISTS pull_requests;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS teams;
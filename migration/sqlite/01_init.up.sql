@@ -0,0 +1,125 @@
+-- The SQLite schema is equivalent to the Postgres one after all of its
+-- migrations. Timestamps are UTC text in the fixed-width format
+-- 'YYYY-MM-DD HH:MM:SS.SSSSSS', so they compare and sort as strings, and
+-- booleans are 0 or 1. SQLite's clock only has milliseconds, so defaults pad
+-- it to microseconds; the application passes its own, finer timestamps where
+-- order matters.
+
+CREATE TABLE IF NOT EXISTS teams (
+    team_name TEXT PRIMARY KEY,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000'),
+    version INTEGER NOT NULL DEFAULT 1
+);
+
+CREATE TABLE IF NOT EXISTS users (
+    user_id TEXT PRIMARY KEY,
+    username TEXT NOT NULL,
+    team_name TEXT NOT NULL REFERENCES teams(team_name) ON DELETE CASCADE,
+    is_active INTEGER NOT NULL DEFAULT 1 CHECK (is_active IN (0, 1)),
+    deleted_at TEXT
+);
+
+CREATE TABLE IF NOT EXISTS pull_requests (
+    pull_request_id TEXT PRIMARY KEY,
+    pull_request_name TEXT NOT NULL,
+    author_id TEXT NOT NULL REFERENCES users(user_id),
+    status TEXT NOT NULL DEFAULT 'OPEN' CHECK (status IN ('OPEN', 'MERGED')),
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000'),
+    merged_at TEXT,
+    version INTEGER NOT NULL DEFAULT 1
+);
+
+CREATE TABLE IF NOT EXISTS pr_reviewers (
+    pull_request_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(user_id),
+    assigned_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000'),
+    PRIMARY KEY (pull_request_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_pr_author_id ON pull_requests(author_id);
+CREATE INDEX IF NOT EXISTS idx_pr_status ON pull_requests(status);
+CREATE INDEX IF NOT EXISTS idx_users_team_name ON users(team_name);
+CREATE INDEX IF NOT EXISTS idx_users_is_active ON users(is_active);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users(deleted_at) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_pr_reviewers_user_id ON pr_reviewers(user_id);
+CREATE INDEX IF NOT EXISTS idx_pr_created_at ON pull_requests(created_at, pull_request_id);
+CREATE INDEX IF NOT EXISTS idx_pr_merged_at ON pull_requests(COALESCE(merged_at, 'infinity'), pull_request_id);
+CREATE INDEX IF NOT EXISTS idx_pr_name ON pull_requests(pull_request_name, pull_request_id);
+CREATE INDEX IF NOT EXISTS idx_pr_status_created_at ON pull_requests(status, created_at);
+
+CREATE TABLE IF NOT EXISTS audit_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    actor TEXT NOT NULL,
+    action TEXT NOT NULL,
+    entity_type TEXT NOT NULL,
+    entity_id TEXT NOT NULL,
+    before TEXT CHECK (before IS NULL OR json_valid(before)),
+    after TEXT CHECK (after IS NULL OR json_valid(after)),
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000')
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_entity ON audit_events(entity_type, entity_id, id);
+CREATE INDEX IF NOT EXISTS idx_audit_actor ON audit_events(actor, id);
+CREATE INDEX IF NOT EXISTS idx_audit_created_at ON audit_events(created_at);
+
+-- The audit log is append-only, except that anonymization may redact
+-- usernames inside snapshots while it holds a row in audit_redaction. The
+-- row only lives inside the anonymizing transaction.
+CREATE TABLE IF NOT EXISTS audit_redaction (
+    enabled INTEGER PRIMARY KEY CHECK (enabled = 1)
+);
+
+CREATE TRIGGER IF NOT EXISTS trg_audit_events_no_update
+    BEFORE UPDATE ON audit_events
+    WHEN NOT EXISTS (SELECT 1 FROM audit_redaction)
+BEGIN
+    SELECT RAISE(ABORT, 'audit_events is append-only');
+END;
+
+CREATE TRIGGER IF NOT EXISTS trg_audit_events_no_delete
+    BEFORE DELETE ON audit_events
+BEGIN
+    SELECT RAISE(ABORT, 'audit_events is append-only');
+END;
+
+CREATE TABLE IF NOT EXISTS pr_assignment_history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    pull_request_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(user_id),
+    assigned_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000'),
+    assigned_by TEXT NOT NULL,
+    assign_reason TEXT NOT NULL,
+    unassigned_at TEXT,
+    unassigned_by TEXT,
+    unassign_reason TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_assignment_history_pr ON pr_assignment_history(pull_request_id, assigned_at);
+CREATE INDEX IF NOT EXISTS idx_assignment_history_user ON pr_assignment_history(user_id);
+-- At most one open entry per reviewer and PR, mirroring pr_reviewers.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_assignment_history_open
+    ON pr_assignment_history(pull_request_id, user_id) WHERE unassigned_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS user_activation_history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id TEXT NOT NULL REFERENCES users(user_id),
+    is_active INTEGER NOT NULL CHECK (is_active IN (0, 1)),
+    changed_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000')
+);
+
+CREATE INDEX IF NOT EXISTS idx_activation_history_user ON user_activation_history(user_id, changed_at, id);
+
+-- Every path that creates a user or flips is_active, including bulk team
+-- deactivation, goes through these triggers.
+CREATE TRIGGER IF NOT EXISTS trg_users_activation_insert
+    AFTER INSERT ON users
+BEGIN
+    INSERT INTO user_activation_history (user_id, is_active) VALUES (NEW.user_id, NEW.is_active);
+END;
+
+CREATE TRIGGER IF NOT EXISTS trg_users_activation_update
+    AFTER UPDATE OF is_active ON users
+    WHEN NEW.is_active IS NOT OLD.is_active
+BEGIN
+    INSERT INTO user_activation_history (user_id, is_active) VALUES (NEW.user_id, NEW.is_active);
+END;
@@ -0,0 +1,5 @@
+ALTER TABLE pull_requests DROP COLUMN size;
+ALTER TABLE pull_requests DROP COLUMN labels;
+ALTER TABLE pull_requests DROP COLUMN repository;
+ALTER TABLE pull_requests DROP COLUMN url;
+ALTER TABLE pull_requests DROP COLUMN description;
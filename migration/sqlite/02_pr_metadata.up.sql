@@ -0,0 +1,6 @@
+ALTER TABLE pull_requests ADD COLUMN description TEXT NOT NULL DEFAULT '';
+ALTER TABLE pull_requests ADD COLUMN url TEXT NOT NULL DEFAULT '';
+ALTER TABLE pull_requests ADD COLUMN repository TEXT NOT NULL DEFAULT '';
+-- Labels are a JSON array of strings, like reviewers in query results.
+ALTER TABLE pull_requests ADD COLUMN labels TEXT NOT NULL DEFAULT '[]' CHECK (json_valid(labels));
+ALTER TABLE pull_requests ADD COLUMN size TEXT NOT NULL DEFAULT '' CHECK (size IN ('', 'XS', 'S', 'M', 'L', 'XL'));
@@ -0,0 +1,44 @@
+INSERT INTO teams (team_name) VALUES
+    ('backend'),
+    ('frontend'),
+    ('devops'),
+    ('mobile')
+ON CONFLICT (team_name) DO NOTHING;
+
+INSERT INTO users (user_id, username, team_name, is_active) VALUES
+    ('u1', 'Aleksandr', 'backend', 1),
+    ('u2', 'Dmitriy', 'backend', 1),
+    ('u3', 'Sergey', 'backend', 1),
+    ('u4', 'Mikhail', 'backend', 0),
+    
+    ('u6', 'Ekaterina', 'frontend', 1),
+    ('u7', 'Olga', 'frontend', 1),
+    ('u8', 'Maria', 'frontend', 1),
+    
+    ('u9', 'Vladimir', 'devops', 1),
+    ('u10', 'Nikolay', 'devops', 1),
+    ('u11', 'Ivan', 'devops', 0),
+    
+    ('u12', 'Yulia', 'mobile', 1),
+    ('u13', 'Tatiana', 'mobile', 1),
+    ('u14', 'Pavel', 'mobile', 1)
+ON CONFLICT (user_id) DO NOTHING;
+
+INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status, created_at) VALUES
+    ('pr-1001', 'Add authentication service', 'u1', 'OPEN', strftime('%Y-%m-%d %H:%M:%f', 'now', '-2 days') || '000'),
+    ('pr-1002', 'Fix database migration', 'u2', 'MERGED', strftime('%Y-%m-%d %H:%M:%f', 'now', '-5 days') || '000'),
+    ('pr-1003', 'Implement user dashboard', 'u6', 'OPEN', strftime('%Y-%m-%d %H:%M:%f', 'now', '-1 day') || '000'),
+    ('pr-1004', 'Update deployment scripts', 'u9', 'OPEN', strftime('%Y-%m-%d %H:%M:%f', 'now', '-3 hours') || '000')
+ON CONFLICT (pull_request_id) DO NOTHING;
+
+INSERT INTO pr_reviewers (pull_request_id, user_id, assigned_at) VALUES
+    ('pr-1001', 'u2', strftime('%Y-%m-%d %H:%M:%f', 'now', '-2 days') || '000'),
+    ('pr-1001', 'u3', strftime('%Y-%m-%d %H:%M:%f', 'now', '-2 days') || '000'),
+    ('pr-1002', 'u1', strftime('%Y-%m-%d %H:%M:%f', 'now', '-5 days') || '000'),
+    ('pr-1002', 'u3', strftime('%Y-%m-%d %H:%M:%f', 'now', '-5 days') || '000'),
+    ('pr-1003', 'u7', strftime('%Y-%m-%d %H:%M:%f', 'now', '-1 day') || '000'),
+    ('pr-1003', 'u8', strftime('%Y-%m-%d %H:%M:%f', 'now', '-1 day') || '000'),
+    ('pr-1004', 'u10', strftime('%Y-%m-%d %H:%M:%f', 'now', '-3 hours') || '000')
+ON CONFLICT (pull_request_id, user_id) DO NOTHING;
+
+UPDATE pull_requests SET merged_at = strftime('%Y-%m-%d %H:%M:%f', 'now', '-4 days') || '000' WHERE pull_request_id = 'pr-1002';
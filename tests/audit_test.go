@@ -0,0 +1,203 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"pr-review-service/internal/domain"
+)
+
+func postAs(t *testing.T, actor, target string, payload interface{}) int {
+	t.Helper()
+
+	body, _ := json.Marshal(payload)
+	req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to build request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Actor", actor)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request to %s failed: %v", target, err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+type auditPage struct {
+	Events     []domain.AuditEvent `json:"events"`
+	NextCursor string              `json:"next_cursor"`
+}
+
+func getAudit(t *testing.T, baseURL string, params url.Values) auditPage {
+	t.Helper()
+
+	resp, err := http.Get(baseURL + "/audit?" + params.Encode())
+	if err != nil {
+		t.Fatalf("Failed to list audit events: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	var page auditPage
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		t.Fatalf("Failed to decode audit page: %v", err)
+	}
+	return page
+}
+
+func TestAuditLog(t *testing.T) {
+	pool, teardown := setupTestDB(t)
+	if pool == nil {
+		return
+	}
+	defer teardown()
+
+	server := newTestServer(t, pool)
+	defer server.Close()
+
+	team := domain.Team{
+		TeamName: "audit-team",
+		Members: []domain.TeamMember{
+			{UserID: "au1", Username: "Audit1", IsActive: true},
+			{UserID: "au2", Username: "Audit2", IsActive: true},
+			{UserID: "au3", Username: "Audit3", IsActive: true},
+		},
+	}
+	if status := postAs(t, "alice", server.URL+"/team/add", team); status != http.StatusCreated {
+		t.Fatalf("Expected status 201 creating team, got %d", status)
+	}
+	if status := postAs(t, "bob", server.URL+"/pullRequest/create", map[string]string{
+		"pull_request_id":   "pr-audit-001",
+		"pull_request_name": "Audit Feature",
+		"author_id":         "au1",
+	}); status != http.StatusCreated {
+		t.Fatalf("Expected status 201 creating PR, got %d", status)
+	}
+	if status := postAs(t, "bob", server.URL+"/pullRequest/merge", map[string]string{
+		"pull_request_id": "pr-audit-001",
+	}); status != http.StatusOK {
+		t.Fatalf("Expected status 200 merging PR, got %d", status)
+	}
+	if status := postAs(t, "carol", server.URL+"/users/setIsActive", map[string]interface{}{
+		"user_id":   "au3",
+		"is_active": false,
+	}); status != http.StatusOK {
+		t.Fatalf("Expected status 200 deactivating user, got %d", status)
+	}
+
+	t.Run("Filter by entity", func(t *testing.T) {
+		page := getAudit(t, server.URL, url.Values{
+			"entity_type": {domain.AuditEntityPullRequest},
+			"entity_id":   {"pr-audit-001"},
+		})
+		if len(page.Events) != 2 {
+			t.Fatalf("Expected 2 events, got %d", len(page.Events))
+		}
+		if page.Events[0].Action != domain.AuditActionPRMerge || page.Events[1].Action != domain.AuditActionPRCreate {
+			t.Errorf("Expected merge then create, got %s then %s", page.Events[0].Action, page.Events[1].Action)
+		}
+		if page.Events[0].Before == nil || page.Events[0].After == nil {
+			t.Error("Expected merge event to carry before and after snapshots")
+		}
+		if page.Events[1].Before != nil {
+			t.Error("Expected create event to have no before snapshot")
+		}
+	})
+
+	t.Run("Filter by actor", func(t *testing.T) {
+		page := getAudit(t, server.URL, url.Values{"actor": {"carol"}})
+		if len(page.Events) != 1 || page.Events[0].Action != domain.AuditActionUserSetActive {
+			t.Errorf("Expected a single user.set_active event, got %+v", page.Events)
+		}
+	})
+
+	t.Run("Cursor pagination", func(t *testing.T) {
+		var actions []string
+		params := url.Values{"limit": {"1"}}
+		for {
+			page := getAudit(t, server.URL, params)
+			for _, e := range page.Events {
+				actions = append(actions, e.Action)
+			}
+			if page.NextCursor == "" {
+				break
+			}
+			params.Set("cursor", page.NextCursor)
+		}
+
+		expected := []string{
+			domain.AuditActionUserSetActive,
+			domain.AuditActionPRMerge,
+			domain.AuditActionPRCreate,
+			domain.AuditActionTeamCreate,
+		}
+		if len(actions) != len(expected) {
+			t.Fatalf("Expected %v, got %v", expected, actions)
+		}
+		for i := range expected {
+			if actions[i] != expected[i] {
+				t.Errorf("Expected %v, got %v", expected, actions)
+				break
+			}
+		}
+	})
+
+	t.Run("Invalid cursor", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/audit?cursor=" + url.QueryEscape("!!!"))
+		if err != nil {
+			t.Fatalf("Failed to list audit events: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", resp.StatusCode)
+		}
+	})
+}
+
+// utcPlus3 formats time bounds with a non-UTC offset, which every backend must
+// treat as the same instant as its UTC form.
+var utcPlus3 = time.FixedZone("UTC+3", 3*60*60)
+
+func TestAuditWindowWithOffset(t *testing.T) {
+	for name, newBackend := range testBackends {
+		t.Run(name, func(t *testing.T) {
+			server, _ := newBackend(t)
+			defer server.Close()
+
+			from := time.Now().Add(-time.Minute)
+			team := domain.Team{
+				TeamName: "audit-offset",
+				Members:  []domain.TeamMember{{UserID: "ao1", Username: "Offset1", IsActive: true}},
+			}
+			if status := postAs(t, "alice", server.URL+"/team/add", team); status != http.StatusCreated {
+				t.Fatalf("Expected status 201 creating team, got %d", status)
+			}
+			to := time.Now().Add(time.Minute)
+
+			for _, zone := range []*time.Location{time.UTC, utcPlus3} {
+				page := getAudit(t, server.URL, url.Values{
+					"actor": {"alice"},
+					"from":  {from.In(zone).Format(time.RFC3339)},
+					"to":    {to.In(zone).Format(time.RFC3339)},
+				})
+				if len(page.Events) != 1 || page.Events[0].Action != domain.AuditActionTeamCreate {
+					t.Errorf("Expected the team.create event with %s bounds, got %+v", zone, page.Events)
+				}
+
+				page = getAudit(t, server.URL, url.Values{"actor": {"alice"}, "from": {to.In(zone).Format(time.RFC3339)}})
+				if len(page.Events) != 0 {
+					t.Errorf("Expected no events after %s, got %+v", to.In(zone).Format(time.RFC3339), page.Events)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"pr-review-service/internal/domain"
+	"pr-review-service/internal/repository/postgres"
+)
+
+const concurrentRequests = 20
+
+func postJSON(t *testing.T, url string, payload interface{}) (int, map[string]interface{}) {
+	t.Helper()
+
+	body, _ := json.Marshal(payload)
+	resp, err := http.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Errorf("Request to %s failed: %v", url, err)
+		return 0, nil
+	}
+	defer resp.Body.Close()
+
+	var result map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&result)
+	return resp.StatusCode, result
+}
+
+// fireConcurrently sends the same request n times at once and returns the
+// status codes.
+func fireConcurrently(t *testing.T, n int, url string, payload interface{}) []int {
+	t.Helper()
+
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	statuses := make([]int, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			statuses[i], _ = postJSON(t, url, payload)
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	return statuses
+}
+
+func countStatuses(statuses []int) map[int]int {
+	counts := make(map[int]int)
+	for _, s := range statuses {
+		counts[s]++
+	}
+	return counts
+}
+
+func TestConcurrentMutations(t *testing.T) {
+	pool, teardown := setupTestDB(t)
+	if pool == nil {
+		return
+	}
+	defer teardown()
+
+	server := newTestServer(t, pool)
+	defer server.Close()
+
+	prRepo := postgres.NewPullRequestRepo(pool)
+	ctx := context.Background()
+
+	members := make([]domain.TeamMember, 0, 8)
+	for i := 1; i <= 8; i++ {
+		members = append(members, domain.TeamMember{
+			UserID:   fmt.Sprintf("c%d", i),
+			Username: fmt.Sprintf("Concurrent%d", i),
+			IsActive: true,
+		})
+	}
+	status, _ := postJSON(t, server.URL+"/team/add", domain.Team{TeamName: "concurrency", Members: members})
+	if status != http.StatusCreated {
+		t.Fatalf("Expected status 201 creating team, got %d", status)
+	}
+
+	t.Run("Concurrent create of the same PR", func(t *testing.T) {
+		statuses := fireConcurrently(t, concurrentRequests, server.URL+"/pullRequest/create", map[string]string{
+			"pull_request_id":   "pr-race-create",
+			"pull_request_name": "Race Create",
+			"author_id":         "c1",
+		})
+
+		counts := countStatuses(statuses)
+		if counts[http.StatusCreated] != 1 {
+			t.Errorf("Expected exactly one 201, got statuses %v", counts)
+		}
+		if counts[http.StatusConflict] != concurrentRequests-1 {
+			t.Errorf("Expected %d conflicts, got statuses %v", concurrentRequests-1, counts)
+		}
+	})
+
+	t.Run("Concurrent reassign of the same reviewer", func(t *testing.T) {
+		status, result := postJSON(t, server.URL+"/pullRequest/create", map[string]string{
+			"pull_request_id":   "pr-race-reassign",
+			"pull_request_name": "Race Reassign",
+			"author_id":         "c1",
+		})
+		if status != http.StatusCreated {
+			t.Fatalf("Expected status 201 creating PR, got %d", status)
+		}
+		reviewers := result["pr"].(map[string]interface{})["assigned_reviewers"].([]interface{})
+		oldReviewer := reviewers[0].(string)
+
+		statuses := fireConcurrently(t, concurrentRequests, server.URL+"/pullRequest/reassign", map[string]string{
+			"pull_request_id": "pr-race-reassign",
+			"old_user_id":     oldReviewer,
+		})
+
+		counts := countStatuses(statuses)
+		if counts[http.StatusOK] != 1 {
+			t.Errorf("Expected exactly one successful reassignment, got statuses %v", counts)
+		}
+		if counts[http.StatusConflict] != concurrentRequests-1 {
+			t.Errorf("Expected %d conflicts, got statuses %v", concurrentRequests-1, counts)
+		}
+
+		assertReviewerInvariants(t, prRepo, "pr-race-reassign", "c1")
+	})
+
+	t.Run("Concurrent reassign of every reviewer", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < concurrentRequests; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				current, err := prRepo.GetReviewers(ctx, "pr-race-reassign")
+				if err != nil || len(current) == 0 {
+					return
+				}
+				status, _ := postJSON(t, server.URL+"/pullRequest/reassign", map[string]string{
+					"pull_request_id": "pr-race-reassign",
+					"old_user_id":     current[0],
+				})
+				if status != http.StatusOK && status != http.StatusConflict {
+					t.Errorf("Unexpected status %d", status)
+				}
+			}()
+		}
+		wg.Wait()
+
+		assertReviewerInvariants(t, prRepo, "pr-race-reassign", "c1")
+	})
+}
+
+func assertReviewerInvariants(t *testing.T, prRepo *postgres.PullRequestRepo, prID, authorID string) {
+	t.Helper()
+
+	reviewers, err := prRepo.GetReviewers(context.Background(), prID)
+	if err != nil {
+		t.Fatalf("Failed to get reviewers: %v", err)
+	}
+	if len(reviewers) != 2 {
+		t.Errorf("Expected 2 reviewers, got %v", reviewers)
+	}
+	for _, r := range reviewers {
+		if r == authorID {
+			t.Errorf("Author %s is assigned as reviewer", authorID)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"pr-review-service/internal/repository"
+	"pr-review-service/internal/repository/memory"
+	"pr-review-service/internal/repository/postgres"
+	"pr-review-service/internal/repository/repotest"
+	"pr-review-service/internal/repository/sqlite"
+)
+
+func TestRepositoryContract(t *testing.T) {
+	t.Run("Memory", func(t *testing.T) {
+		repotest.Run(t, func(t *testing.T) repository.Repository {
+			store := memory.NewStore()
+			return repository.Repository{
+				Team:        memory.NewTeamRepo(store),
+				User:        memory.NewUserRepo(store),
+				PullRequest: memory.NewPullRequestRepo(store),
+			}
+		})
+	})
+
+	t.Run("SQLite", func(t *testing.T) {
+		repotest.Run(t, func(t *testing.T) repository.Repository {
+			db := newSQLiteTestDB(t)
+			return repository.Repository{
+				Team:        sqlite.NewTeamRepo(db),
+				User:        sqlite.NewUserRepo(db),
+				PullRequest: sqlite.NewPullRequestRepo(db),
+			}
+		})
+	})
+
+	t.Run("Postgres", func(t *testing.T) {
+		pool, teardown := setupTestDB(t)
+		if pool == nil {
+			return
+		}
+		defer teardown()
+
+		ctx := context.Background()
+		if err := postgres.RunMigrations(ctx, pool, "../migration"); err != nil {
+			t.Fatalf("Failed to run migrations: %v", err)
+		}
+
+		repotest.Run(t, func(t *testing.T) repository.Repository {
+			if _, err := pool.Exec(ctx, "TRUNCATE TABLE audit_events, pr_reviewers, pull_requests, users, teams CASCADE"); err != nil {
+				t.Fatalf("Failed to clean up tables: %v", err)
+			}
+			return repository.Repository{
+				Team:        postgres.NewTeamRepo(pool),
+				User:        postgres.NewUserRepo(pool),
+				PullRequest: postgres.NewPullRequestRepo(pool),
+			}
+		})
+	})
+}
@@ -0,0 +1,270 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+
+	"pr-review-service/internal/domain"
+	"pr-review-service/internal/repository"
+	"pr-review-service/internal/repository/memory"
+	"pr-review-service/internal/repository/postgres"
+	"pr-review-service/internal/repository/sqlite"
+)
+
+// deactivateTeam deactivates teamName, or only previews that with dryRun, and
+// returns the reassignment report.
+func deactivateTeam(t *testing.T, serverURL, teamName string, dryRun bool) []domain.ReviewerReassignment {
+	t.Helper()
+
+	query := url.Values{"team_name": {teamName}, "dry_run": {strconv.FormatBool(dryRun)}}
+	resp, err := http.Post(serverURL+"/team/deactivate-all?"+query.Encode(), "application/json", nil)
+	if err != nil {
+		t.Fatalf("Failed to deactivate team: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Reassignments []domain.ReviewerReassignment `json:"reassignments"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return body.Reassignments
+}
+
+// testBackend starts a server over one storage backend and returns the
+// repositories behind it, for seeding state the API cannot create.
+type testBackend func(t *testing.T) (*httptest.Server, repository.Repository)
+
+// testBackends covers every storage; Postgres skips without a database.
+var testBackends = map[string]testBackend{
+	"Memory": func(t *testing.T) (*httptest.Server, repository.Repository) {
+		server, store := newMemoryTestServer(t)
+		return server, repository.Repository{
+			Team:        memory.NewTeamRepo(store),
+			User:        memory.NewUserRepo(store),
+			PullRequest: memory.NewPullRequestRepo(store),
+			History:     memory.NewAssignmentHistoryRepo(store),
+			Audit:       memory.NewAuditRepo(store),
+		}
+	},
+	"SQLite": func(t *testing.T) (*httptest.Server, repository.Repository) {
+		db := newSQLiteTestDB(t)
+		return newSQLiteTestServer(t, db), repository.Repository{
+			Team:        sqlite.NewTeamRepo(db),
+			User:        sqlite.NewUserRepo(db),
+			PullRequest: sqlite.NewPullRequestRepo(db),
+			History:     sqlite.NewAssignmentHistoryRepo(db),
+			Audit:       sqlite.NewAuditRepo(db),
+		}
+	},
+	"Postgres": func(t *testing.T) (*httptest.Server, repository.Repository) {
+		pool, teardown := setupTestDB(t)
+		if pool == nil {
+			t.SkipNow()
+		}
+		t.Cleanup(teardown)
+		return newTestServer(t, pool), repository.Repository{
+			Team:        postgres.NewTeamRepo(pool),
+			User:        postgres.NewUserRepo(pool),
+			PullRequest: postgres.NewPullRequestRepo(pool),
+			History:     postgres.NewAssignmentHistoryRepo(pool),
+			Audit:       postgres.NewAuditRepo(pool),
+		}
+	},
+}
+
+// seedReviews creates open PRs with the given reviewers, recording their
+// assignments like the service would.
+func seedReviews(t *testing.T, repos repository.Repository, prs ...domain.PullRequest) {
+	t.Helper()
+
+	ctx := context.Background()
+	for _, pr := range prs {
+		pr.PullRequestName = pr.PullRequestID
+		pr.Status = domain.PRStatusOpen
+		if err := repos.PullRequest.Create(ctx, &pr); err != nil {
+			t.Fatalf("Failed to create %s: %v", pr.PullRequestID, err)
+		}
+		for _, reviewerID := range pr.AssignedReviewers {
+			if err := repos.History.RecordAssigned(ctx, pr.PullRequestID, reviewerID, domain.AssignmentReasonInitial, "seed"); err != nil {
+				t.Fatalf("Failed to record assignment: %v", err)
+			}
+		}
+	}
+}
+
+func TestTeamDeactivationReport(t *testing.T) {
+	for name, newBackend := range testBackends {
+		t.Run(name, func(t *testing.T) {
+			server, repos := newBackend(t)
+			defer server.Close()
+			testTeamDeactivationReport(t, server.URL, repos)
+		})
+	}
+}
+
+func testTeamDeactivationReport(t *testing.T, serverURL string, repos repository.Repository) {
+	ctx := context.Background()
+
+	for _, team := range []domain.Team{
+		{TeamName: "staying", Members: []domain.TeamMember{
+			{UserID: "st-author", Username: "Author", IsActive: true},
+			{UserID: "st-k1", Username: "Keeper 1", IsActive: true},
+			{UserID: "st-k2", Username: "Keeper 2", IsActive: true},
+		}},
+		{TeamName: "leaving", Members: []domain.TeamMember{
+			{UserID: "lv-1", Username: "Leaver 1", IsActive: true},
+			{UserID: "lv-2", Username: "Leaver 2", IsActive: true},
+		}},
+	} {
+		if status, _ := postJSON(t, serverURL+"/team/add", team); status != http.StatusCreated {
+			t.Fatalf("Expected status 201 creating team %s, got %d", team.TeamName, status)
+		}
+	}
+
+	// Reviewers are seeded directly: automatic assignment never picks
+	// reviewers from outside the author's team.
+	seedReviews(t, repos,
+		domain.PullRequest{PullRequestID: "pr-deact-1", AuthorID: "st-author", AssignedReviewers: []string{"lv-1", "lv-2"}},
+		domain.PullRequest{PullRequestID: "pr-deact-2", AuthorID: "lv-1", AssignedReviewers: []string{"lv-2", "st-k1"}},
+		domain.PullRequest{PullRequestID: "pr-deact-3", AuthorID: "st-author", AssignedReviewers: []string{"st-k1", "lv-1"}},
+		domain.PullRequest{PullRequestID: "pr-deact-4", AuthorID: "st-author", AssignedReviewers: []string{"lv-1"}},
+	)
+	merged, err := repos.PullRequest.Get(ctx, "pr-deact-4")
+	if err != nil {
+		t.Fatalf("Failed to get PR: %v", err)
+	}
+	merged.Status = domain.PRStatusMerged
+	if err := repos.PullRequest.Update(ctx, merged); err != nil {
+		t.Fatalf("Failed to merge PR: %v", err)
+	}
+
+	t.Run("Dry run", func(t *testing.T) {
+		checkDeactivationReport(t, deactivateTeam(t, serverURL, "leaving", true))
+
+		team, err := repos.Team.Get(ctx, "leaving")
+		if err != nil {
+			t.Fatalf("Failed to get team: %v", err)
+		}
+		for _, m := range team.Members {
+			if !m.IsActive {
+				t.Errorf("Expected %s to stay active after a dry run", m.UserID)
+			}
+		}
+		pr, err := repos.PullRequest.Get(ctx, "pr-deact-1")
+		if err != nil {
+			t.Fatalf("Failed to get PR: %v", err)
+		}
+		if pr.Version != 1 || !reflect.DeepEqual(pr.AssignedReviewers, []string{"lv-1", "lv-2"}) {
+			t.Errorf("Expected a dry run to leave pr-deact-1 alone, got %+v", pr)
+		}
+	})
+
+	checkDeactivationReport(t, deactivateTeam(t, serverURL, "leaving", false))
+
+	for prID, want := range map[string][]string{
+		"pr-deact-1": {"st-k1", "st-k2"},
+		"pr-deact-2": {"st-k1"},
+		"pr-deact-3": {"st-k1", "st-k2"},
+		"pr-deact-4": {"lv-1"},
+	} {
+		pr, err := repos.PullRequest.Get(ctx, prID)
+		if err != nil {
+			t.Fatalf("Failed to get %s: %v", prID, err)
+		}
+		sort.Strings(pr.AssignedReviewers)
+		if !reflect.DeepEqual(pr.AssignedReviewers, want) {
+			t.Errorf("Expected %s to be reviewed by %v, got %v", prID, want, pr.AssignedReviewers)
+		}
+		// Every PR was bumped once: the open ones by the reassignment, the
+		// merged one by merging it.
+		if pr.Version != 2 {
+			t.Errorf("Expected %s to be at version 2, got %d", prID, pr.Version)
+		}
+		if prID != "pr-deact-4" {
+			checkReassignAudit(t, repos, pr)
+		}
+	}
+
+	history, err := repos.History.ListByPR(ctx, "pr-deact-1")
+	if err != nil {
+		t.Fatalf("Failed to list history: %v", err)
+	}
+	var unassigned, assigned int
+	for _, rec := range history {
+		if rec.UnassignReason == domain.AssignmentReasonTeamDeactivation {
+			unassigned++
+		}
+		if rec.AssignReason == domain.AssignmentReasonTeamDeactivation && rec.UnassignedAt == nil {
+			assigned++
+		}
+	}
+	if unassigned != 2 || assigned != 2 {
+		t.Errorf("Expected two closed and two new TEAM_DEACTIVATION entries, got %+v", history)
+	}
+}
+
+// checkReassignAudit checks that the latest audit event of pr records the
+// reassignment with an after-image listing the stored reviewers.
+func checkReassignAudit(t *testing.T, repos repository.Repository, pr *domain.PullRequest) {
+	t.Helper()
+
+	events, err := repos.Audit.List(context.Background(), domain.AuditFilter{
+		EntityType: domain.AuditEntityPullRequest,
+		EntityID:   pr.PullRequestID,
+		Limit:      1,
+	})
+	if err != nil {
+		t.Fatalf("Failed to list audit events: %v", err)
+	}
+	if len(events) != 1 || events[0].Action != domain.AuditActionPRReassign {
+		t.Fatalf("Expected a %s event for %s, got %+v", domain.AuditActionPRReassign, pr.PullRequestID, events)
+	}
+
+	var after domain.PullRequest
+	if err := json.Unmarshal(events[0].After, &after); err != nil {
+		t.Fatalf("Failed to decode after-image: %v", err)
+	}
+	sort.Strings(after.AssignedReviewers)
+	if !reflect.DeepEqual(after.AssignedReviewers, pr.AssignedReviewers) {
+		t.Errorf("Expected the after-image of %s to list reviewers %v, got %v", pr.PullRequestID, pr.AssignedReviewers, after.AssignedReviewers)
+	}
+}
+
+// checkDeactivationReport checks the report of deactivating the leaving team
+// seeded by testTeamDeactivationReport.
+func checkDeactivationReport(t *testing.T, report []domain.ReviewerReassignment) {
+	t.Helper()
+
+	type move struct{ prID, oldID string }
+	moves := make(map[move]domain.ReviewerReassignment)
+	for _, ra := range report {
+		moves[move{ra.PullRequestID, ra.OldReviewerID}] = ra
+	}
+	if len(moves) != 4 || len(report) != 4 {
+		t.Fatalf("Expected a report entry per review of the team on open PRs, got %+v", report)
+	}
+
+	pr1a, pr1b := moves[move{"pr-deact-1", "lv-1"}], moves[move{"pr-deact-1", "lv-2"}]
+	if pr1a.Outcome != domain.ReassignmentOutcomeReassigned || pr1b.Outcome != domain.ReassignmentOutcomeReassigned ||
+		pr1a.NewReviewerID == pr1b.NewReviewerID {
+		t.Errorf("Expected both reviews of pr-deact-1 to go to distinct keepers, got %+v and %+v", pr1a, pr1b)
+	}
+	if ra := moves[move{"pr-deact-2", "lv-2"}]; ra.Outcome != domain.ReassignmentOutcomeLeftUnassigned || ra.NewReviewerID != "" {
+		t.Errorf("Expected pr-deact-2 to be left unassigned, its author's team has nobody left, got %+v", ra)
+	}
+	if ra := moves[move{"pr-deact-3", "lv-1"}]; ra.Outcome != domain.ReassignmentOutcomeReassigned || ra.NewReviewerID != "st-k2" {
+		t.Errorf("Expected pr-deact-3 to go to the only free keeper, got %+v", ra)
+	}
+}
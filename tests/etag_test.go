@@ -0,0 +1,212 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"pr-review-service/internal/domain"
+)
+
+func postWithIfMatch(t *testing.T, url, ifMatch string, payload interface{}) *http.Response {
+	t.Helper()
+
+	body, _ := json.Marshal(payload)
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to build request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if ifMatch != "" {
+		req.Header.Set("If-Match", ifMatch)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request to %s failed: %v", url, err)
+	}
+	return resp
+}
+
+func TestOptimisticConcurrency(t *testing.T) {
+	pool, teardown := setupTestDB(t)
+	if pool == nil {
+		return
+	}
+	defer teardown()
+
+	server := newTestServer(t, pool)
+	defer server.Close()
+
+	team := domain.Team{
+		TeamName: "etag-team",
+		Members: []domain.TeamMember{
+			{UserID: "et1", Username: "ETag1", IsActive: true},
+			{UserID: "et2", Username: "ETag2", IsActive: true},
+			{UserID: "et3", Username: "ETag3", IsActive: true},
+			{UserID: "et4", Username: "ETag4", IsActive: true},
+		},
+	}
+	if status, _ := postJSON(t, server.URL+"/team/add", team); status != http.StatusCreated {
+		t.Fatalf("Expected status 201 creating team, got %d", status)
+	}
+	if status, _ := postJSON(t, server.URL+"/pullRequest/create", map[string]string{
+		"pull_request_id":   "pr-etag-001",
+		"pull_request_name": "ETag Feature",
+		"author_id":         "et1",
+	}); status != http.StatusCreated {
+		t.Fatalf("Expected status 201 creating PR, got %d", status)
+	}
+
+	resp, err := http.Get(server.URL + "/pullRequest/get?pull_request_id=pr-etag-001")
+	if err != nil {
+		t.Fatalf("Failed to get PR: %v", err)
+	}
+	resp.Body.Close()
+	etag := resp.Header.Get("ETag")
+	if etag == "" {
+		t.Fatal("Expected ETag on GET /pullRequest/get")
+	}
+
+	t.Run("Reassign with current ETag", func(t *testing.T) {
+		var result map[string]interface{}
+		pr := map[string]string{"pull_request_id": "pr-etag-001"}
+		resp, err := http.Get(server.URL + "/pullRequest/get?pull_request_id=pr-etag-001")
+		if err != nil {
+			t.Fatalf("Failed to get PR: %v", err)
+		}
+		json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		reviewer := result["pr"].(map[string]interface{})["assigned_reviewers"].([]interface{})[0].(string)
+		pr["old_user_id"] = reviewer
+
+		resp = postWithIfMatch(t, server.URL+"/pullRequest/reassign", etag, pr)
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+		if newTag := resp.Header.Get("ETag"); newTag == "" || newTag == etag {
+			t.Errorf("Expected a new ETag after reassignment, got %q (was %q)", newTag, etag)
+		}
+	})
+
+	t.Run("Merge with stale ETag", func(t *testing.T) {
+		resp := postWithIfMatch(t, server.URL+"/pullRequest/merge", etag, map[string]string{
+			"pull_request_id": "pr-etag-001",
+		})
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusPreconditionFailed {
+			t.Errorf("Expected status 412, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Merge with malformed If-Match", func(t *testing.T) {
+		resp := postWithIfMatch(t, server.URL+"/pullRequest/merge", "not-a-tag", map[string]string{
+			"pull_request_id": "pr-etag-001",
+		})
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Merge without If-Match", func(t *testing.T) {
+		resp := postWithIfMatch(t, server.URL+"/pullRequest/merge", "", map[string]string{
+			"pull_request_id": "pr-etag-001",
+		})
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Team ETag changes with member activity", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/team/get?team_name=etag-team")
+		if err != nil {
+			t.Fatalf("Failed to get team: %v", err)
+		}
+		resp.Body.Close()
+		teamTag := resp.Header.Get("ETag")
+
+		if status, _ := postJSON(t, server.URL+"/users/setIsActive", map[string]interface{}{
+			"user_id":   "et4",
+			"is_active": false,
+		}); status != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", status)
+		}
+
+		resp = postWithIfMatch(t, server.URL+"/team/deactivate-all?team_name=etag-team", teamTag, nil)
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusPreconditionFailed {
+			t.Errorf("Expected status 412 for stale team ETag, got %d", resp.StatusCode)
+		}
+	})
+}
+
+func TestIfMatchTagLists(t *testing.T) {
+	for name, newBackend := range testBackends {
+		t.Run(name, func(t *testing.T) {
+			server, _ := newBackend(t)
+			defer server.Close()
+
+			team := domain.Team{
+				TeamName: "etag-lists",
+				Members: []domain.TeamMember{
+					{UserID: "el1", Username: "List1", IsActive: true},
+					{UserID: "el2", Username: "List2", IsActive: true},
+				},
+			}
+			if status, _ := postJSON(t, server.URL+"/team/add", team); status != http.StatusCreated {
+				t.Fatalf("Expected status 201 creating team, got %d", status)
+			}
+			if status, _ := postJSON(t, server.URL+"/pullRequest/create", map[string]string{
+				"pull_request_id":   "pr-el-1",
+				"pull_request_name": "Lists",
+				"author_id":         "el1",
+			}); status != http.StatusCreated {
+				t.Fatalf("Expected status 201 creating PR, got %d", status)
+			}
+
+			// Each accepted update bumps the PR from the version in its name.
+			for _, tc := range []struct {
+				ifMatch string
+				status  int
+			}{
+				{`W/"1"`, http.StatusPreconditionFailed},
+				{`"9", "1"`, http.StatusOK},
+				{`"2", W/"2"`, http.StatusOK},
+				{`W/"3", "4"`, http.StatusPreconditionFailed},
+				{`"main", , "3"`, http.StatusOK},
+				{`"4" "5"`, http.StatusBadRequest},
+				{`"4", not-a-tag`, http.StatusBadRequest},
+			} {
+				resp := postWithIfMatch(t, server.URL+"/pullRequest/update", tc.ifMatch,
+					map[string]string{"pull_request_id": "pr-el-1", "description": tc.ifMatch})
+				resp.Body.Close()
+				if resp.StatusCode != tc.status {
+					t.Errorf("Expected status %d for If-Match %s, got %d", tc.status, tc.ifMatch, resp.StatusCode)
+				}
+			}
+
+			resp, err := http.Get(server.URL + "/team/get?team_name=etag-lists")
+			if err != nil {
+				t.Fatalf("Failed to get team: %v", err)
+			}
+			resp.Body.Close()
+			teamTag := resp.Header.Get("ETag")
+
+			for ifMatch, status := range map[string]int{
+				"W/" + teamTag:       http.StatusPreconditionFailed,
+				`"99", ` + teamTag:   http.StatusOK,
+				`"99", W/` + teamTag: http.StatusPreconditionFailed,
+			} {
+				resp := postWithIfMatch(t, server.URL+"/team/activate-all?team_name=etag-lists", ifMatch, nil)
+				resp.Body.Close()
+				if resp.StatusCode != status {
+					t.Errorf("Expected status %d for If-Match %s, got %d", status, ifMatch, resp.StatusCode)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package tests
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"pr-review-service/internal/domain"
+)
+
+func getExport(t *testing.T, target, accept string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, target, nil)
+	if err != nil {
+		t.Fatalf("Failed to build request: %v", err)
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request to %s failed: %v", target, err)
+	}
+	return resp
+}
+
+func TestExports(t *testing.T) {
+	pool, teardown := setupTestDB(t)
+	if pool == nil {
+		return
+	}
+	defer teardown()
+
+	server := newTestServer(t, pool)
+	defer server.Close()
+
+	team := domain.Team{
+		TeamName: "export-team",
+		Members: []domain.TeamMember{
+			{UserID: "e1", Username: "Export1", IsActive: true},
+			{UserID: "e2", Username: "Export2", IsActive: true},
+			{UserID: "e3", Username: "Export3", IsActive: true},
+		},
+	}
+	if status, _ := postJSON(t, server.URL+"/team/add", team); status != http.StatusCreated {
+		t.Fatalf("Expected status 201 creating team, got %d", status)
+	}
+	for i := 1; i <= 3; i++ {
+		if status, _ := postJSON(t, server.URL+"/pullRequest/create", map[string]string{
+			"pull_request_id":   fmt.Sprintf("pr-export-%d", i),
+			"pull_request_name": "=HYPERLINK(\"x\")",
+			"author_id":         "e1",
+		}); status != http.StatusCreated {
+			t.Fatalf("Expected status 201 creating PR, got %d", status)
+		}
+	}
+
+	t.Run("PRs as CSV", func(t *testing.T) {
+		resp := getExport(t, server.URL+"/export/pullRequests?team_name=export-team&format=csv", "")
+		defer resp.Body.Close()
+		if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+			t.Fatalf("Expected CSV content type, got %q", ct)
+		}
+
+		records, err := csv.NewReader(resp.Body).ReadAll()
+		if err != nil {
+			t.Fatalf("Failed to parse CSV: %v", err)
+		}
+		if len(records) != 4 {
+			t.Fatalf("Expected header and 3 rows, got %d records", len(records))
+		}
+		if records[0][0] != "pull_request_id" {
+			t.Errorf("Expected header row, got %v", records[0])
+		}
+		if name := records[1][1]; !strings.HasPrefix(name, "'") {
+			t.Errorf("Expected formula-like name to be escaped, got %q", name)
+		}
+	})
+
+	t.Run("History as NDJSON via Accept", func(t *testing.T) {
+		resp := getExport(t, server.URL+"/export/history?team_name=export-team", "application/x-ndjson")
+		defer resp.Body.Close()
+
+		var records int
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			var rec domain.AssignmentRecord
+			if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+				t.Fatalf("Failed to parse NDJSON line %q: %v", scanner.Text(), err)
+			}
+			records++
+		}
+		// Two initial reviewers for each of the three PRs.
+		if records != 6 {
+			t.Errorf("Expected 6 history records, got %d", records)
+		}
+	})
+
+	t.Run("Stats", func(t *testing.T) {
+		resp := getExport(t, server.URL+"/export/stats?format=ndjson", "")
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+
+		// Seed users are exported too, so only count this test's team.
+		scanner := bufio.NewScanner(resp.Body)
+		var users int
+		for scanner.Scan() {
+			var stat domain.UserStats
+			if err := json.Unmarshal(scanner.Bytes(), &stat); err != nil {
+				t.Fatalf("Failed to parse NDJSON line %q: %v", scanner.Text(), err)
+			}
+			if strings.HasPrefix(stat.UserID, "e") {
+				users++
+			}
+		}
+		if users != 3 {
+			t.Errorf("Expected stats for 3 export users, got %d", users)
+		}
+	})
+
+	t.Run("Invalid input", func(t *testing.T) {
+		for _, target := range []string{
+			"/export/pullRequests?format=xlsx",
+			"/export/pullRequests?sort=author_id",
+			"/export/stats?from=yesterday",
+		} {
+			resp := getExport(t, server.URL+target, "")
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Errorf("Expected status 400 for %s, got %d", target, resp.StatusCode)
+			}
+		}
+	})
+}
@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"pr-review-service/internal/domain"
+)
+
+func TestAssignmentHistory(t *testing.T) {
+	pool, teardown := setupTestDB(t)
+	if pool == nil {
+		return
+	}
+	defer teardown()
+
+	server := newTestServer(t, pool)
+	defer server.Close()
+
+	team := domain.Team{
+		TeamName: "history-team",
+		Members: []domain.TeamMember{
+			{UserID: "h1", Username: "History1", IsActive: true},
+			{UserID: "h2", Username: "History2", IsActive: true},
+			{UserID: "h3", Username: "History3", IsActive: true},
+			{UserID: "h4", Username: "History4", IsActive: true},
+		},
+	}
+	if status, _ := postJSON(t, server.URL+"/team/add", team); status != http.StatusCreated {
+		t.Fatalf("Expected status 201 creating team, got %d", status)
+	}
+	status, result := postJSON(t, server.URL+"/pullRequest/create", map[string]string{
+		"pull_request_id":   "pr-history-001",
+		"pull_request_name": "History Feature",
+		"author_id":         "h1",
+	})
+	if status != http.StatusCreated {
+		t.Fatalf("Expected status 201 creating PR, got %d", status)
+	}
+	oldReviewer := result["pr"].(map[string]interface{})["assigned_reviewers"].([]interface{})[0].(string)
+
+	if status := postAs(t, "lead", server.URL+"/pullRequest/reassign", map[string]string{
+		"pull_request_id": "pr-history-001",
+		"old_user_id":     oldReviewer,
+	}); status != http.StatusOK {
+		t.Fatalf("Expected status 200 reassigning, got %d", status)
+	}
+
+	resp, err := http.Get(server.URL + "/pullRequest/history?pull_request_id=pr-history-001")
+	if err != nil {
+		t.Fatalf("Failed to get history: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	var body struct {
+		History []domain.AssignmentRecord `json:"history"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+
+	if len(body.History) != 3 {
+		t.Fatalf("Expected 3 history entries, got %d", len(body.History))
+	}
+
+	var removed, added int
+	for _, rec := range body.History {
+		switch {
+		case rec.UserID == oldReviewer:
+			removed++
+			if rec.AssignReason != domain.AssignmentReasonInitial {
+				t.Errorf("Expected old reviewer to be assigned as INITIAL, got %s", rec.AssignReason)
+			}
+			if rec.UnassignedAt == nil || rec.UnassignReason != domain.AssignmentReasonManualReassign || rec.UnassignedBy != "lead" {
+				t.Errorf("Expected old reviewer to be unassigned by lead on manual reassign, got %+v", rec)
+			}
+		case rec.AssignReason == domain.AssignmentReasonManualReassign:
+			added++
+			if rec.AssignedBy != "lead" || rec.UnassignedAt != nil {
+				t.Errorf("Expected replacement to be assigned by lead and still active, got %+v", rec)
+			}
+		}
+	}
+	if removed != 1 || added != 1 {
+		t.Errorf("Expected one removed and one added reviewer, got %d and %d", removed, added)
+	}
+
+	t.Run("Unknown PR", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/pullRequest/history?pull_request_id=pr-missing")
+		if err != nil {
+			t.Fatalf("Failed to get history: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", resp.StatusCode)
+		}
+	})
+}
@@ -0,0 +1,206 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"pr-review-service/internal/domain"
+	"pr-review-service/internal/repository"
+	"pr-review-service/internal/repository/postgres"
+)
+
+type importResponse struct {
+	Imported  domain.ImportSummary    `json:"imported"`
+	RowErrors []domain.ImportRowError `json:"row_errors"`
+}
+
+func postImport(t *testing.T, baseURL, contentType string, body []byte) (int, importResponse) {
+	t.Helper()
+
+	resp, err := http.Post(baseURL+"/import", contentType, bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to import: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var result importResponse
+	json.NewDecoder(resp.Body).Decode(&result)
+	return resp.StatusCode, result
+}
+
+func TestImport(t *testing.T) {
+	pool, teardown := setupTestDB(t)
+	if pool == nil {
+		return
+	}
+	defer teardown()
+
+	server := newTestServer(t, pool)
+	defer server.Close()
+
+	t.Run("JSON bundle", func(t *testing.T) {
+		bundle := `{
+			"teams": [{"team_name": "imported"}],
+			"users": [
+				{"user_id": "i1", "username": "Import1", "team_name": "imported"},
+				{"user_id": "i2", "username": "Import2", "team_name": "imported"},
+				{"user_id": "i3", "username": "Import3", "team_name": "imported", "is_active": false}
+			],
+			"pull_requests": [
+				{"pull_request_id": "pr-imp-1", "pull_request_name": "Old", "author_id": "i1",
+				 "status": "MERGED", "created_at": "2024-01-01T10:00:00Z", "merged_at": "2024-01-02T10:00:00Z"},
+				{"pull_request_id": "pr-imp-2", "pull_request_name": "Open", "author_id": "i1"}
+			],
+			"assignments": [
+				{"pull_request_id": "pr-imp-1", "user_id": "i2"},
+				{"pull_request_id": "pr-imp-2", "user_id": "i3"}
+			]
+		}`
+		status, result := postImport(t, server.URL, "application/json", []byte(bundle))
+		if status != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %+v", status, result.RowErrors)
+		}
+		expected := domain.ImportSummary{Teams: 1, Users: 3, PullRequests: 2, Assignments: 2}
+		if result.Imported != expected {
+			t.Errorf("Expected %+v, got %+v", expected, result.Imported)
+		}
+
+		_, page := listPRs(t, server.URL, url.Values{"team_name": {"imported"}, "status": {"MERGED"}})
+		if len(page.PullRequests) != 1 || len(page.PullRequests[0].AssignedReviewers) != 1 {
+			t.Errorf("Expected the merged PR with its reviewer, got %+v", page.PullRequests)
+		}
+	})
+
+	t.Run("Invalid rows reject the whole bundle", func(t *testing.T) {
+		bundle := `{
+			"teams": [{"team_name": "rejected"}],
+			"users": [
+				{"user_id": "r1", "username": "Rejected1", "team_name": "rejected"},
+				{"user_id": "r2", "username": "Rejected2", "team_name": "nowhere"}
+			],
+			"pull_requests": [
+				{"pull_request_id": "pr-imp-1", "pull_request_name": "Taken", "author_id": "r1"},
+				{"pull_request_id": "pr-rej-2", "pull_request_name": "Ghost", "author_id": "ghost"},
+				{"pull_request_id": "pr-rej-3", "pull_request_name": "Merged", "author_id": "r1", "status": "MERGED"}
+			],
+			"assignments": [
+				{"pull_request_id": "pr-rej-3", "user_id": "r1"}
+			]
+		}`
+		status, result := postImport(t, server.URL, "application/json", []byte(bundle))
+		if status != http.StatusUnprocessableEntity {
+			t.Fatalf("Expected status 422, got %d", status)
+		}
+		if len(result.RowErrors) != 5 {
+			t.Errorf("Expected 5 row errors, got %+v", result.RowErrors)
+		}
+
+		resp, err := http.Get(server.URL + "/team/get?team_name=rejected")
+		if err != nil {
+			t.Fatalf("Failed to get team: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected nothing to be loaded, but team exists")
+		}
+	})
+
+	t.Run("CSV bundle", func(t *testing.T) {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		for name, content := range map[string]string{
+			domain.ImportTableTeams:        "team_name\ncsv-team\n",
+			domain.ImportTableUsers:        "user_id,username,team_name,is_active\nc1,Csv1,csv-team,true\nc2,Csv2,csv-team,\n",
+			domain.ImportTablePullRequests: "pull_request_id,pull_request_name,author_id,status,created_at,merged_at\npr-csv-1,Csv,c1,OPEN,,\n",
+			domain.ImportTableAssignments:  "pull_request_id,user_id,assigned_at\npr-csv-1,c2,\n",
+		} {
+			part, _ := form.CreateFormFile(name, name+".csv")
+			part.Write([]byte(content))
+		}
+		form.Close()
+
+		status, result := postImport(t, server.URL, form.FormDataContentType(), body.Bytes())
+		if status != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %+v", status, result.RowErrors)
+		}
+		if result.Imported.Users != 2 || result.Imported.Assignments != 1 {
+			t.Errorf("Unexpected summary %+v", result.Imported)
+		}
+	})
+
+	t.Run("Rows inserted after validation conflict", func(t *testing.T) {
+		// Loading skips validation, like a bundle whose keys another
+		// transaction inserted after it was validated.
+		active := true
+		for _, tc := range []struct {
+			bundle domain.ImportBundle
+			want   error
+		}{
+			{domain.ImportBundle{Teams: []domain.ImportTeam{{TeamName: "imported"}}}, domain.ErrTeamExists},
+			{domain.ImportBundle{Users: []domain.ImportUser{{UserID: "i1", Username: "Again", TeamName: "imported", IsActive: &active}}}, domain.ErrUserExists},
+			{domain.ImportBundle{PullRequests: []domain.ImportPullRequest{{PullRequestID: "pr-imp-1", PullRequestName: "Again",
+				AuthorID: "i1", Status: domain.PRStatusOpen, CreatedAt: &time.Time{}}}}, domain.ErrPRExists},
+		} {
+			err := postgres.NewUnitOfWork(pool).WithinTx(context.Background(), func(ctx context.Context, repos repository.Repository) error {
+				return repos.Import.Load(ctx, &tc.bundle, "test")
+			})
+			if !errors.Is(err, tc.want) {
+				t.Errorf("Expected %v, got %v", tc.want, err)
+			}
+		}
+	})
+
+	t.Run("CSV parse errors are reported per row", func(t *testing.T) {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		part, _ := form.CreateFormFile(domain.ImportTableUsers, "users.csv")
+		part.Write([]byte("user_id,username,team_name,is_active\nx1,X,csv-team,maybe\n"))
+		form.Close()
+
+		status, result := postImport(t, server.URL, form.FormDataContentType(), body.Bytes())
+		if status != http.StatusUnprocessableEntity {
+			t.Fatalf("Expected status 422, got %d", status)
+		}
+		if len(result.RowErrors) != 1 || result.RowErrors[0].Row != 1 {
+			t.Errorf("Expected one error on row 1, got %+v", result.RowErrors)
+		}
+	})
+}
+
+func TestImportMergedWithoutCreatedAt(t *testing.T) {
+	for name, newBackend := range testBackends {
+		t.Run(name, func(t *testing.T) {
+			server, repos := newBackend(t)
+			defer server.Close()
+
+			bundle := `{
+				"teams": [{"team_name": "legacy"}],
+				"users": [{"user_id": "lg1", "username": "Legacy1", "team_name": "legacy"}],
+				"pull_requests": [
+					{"pull_request_id": "pr-legacy-1", "pull_request_name": "Old", "author_id": "lg1",
+					 "status": "MERGED", "merged_at": "2023-05-01T10:00:00Z"}
+				]
+			}`
+			status, result := postImport(t, server.URL, "application/json", []byte(bundle))
+			if status != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %+v", status, result.RowErrors)
+			}
+
+			pr, err := repos.PullRequest.Get(context.Background(), "pr-legacy-1")
+			if err != nil {
+				t.Fatalf("Failed to get PR: %v", err)
+			}
+			mergedAt := time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)
+			if pr.CreatedAt == nil || !pr.CreatedAt.Equal(mergedAt) || pr.MergedAt == nil || !pr.MergedAt.Equal(mergedAt) {
+				t.Errorf("Expected created_at to default to merged_at, got %v and %v", pr.CreatedAt, pr.MergedAt)
+			}
+		})
+	}
+}
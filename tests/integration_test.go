@@ -31,7 +31,7 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
-func setupTestDB(t *testing.T) (*pgxpool.Pool, func()) {
+func setupTestDB(t testing.TB) (*pgxpool.Pool, func()) {
 	ctx := context.Background()
 
 	// Connect to database
@@ -43,7 +43,7 @@ func setupTestDB(t *testing.T) (*pgxpool.Pool, func()) {
 
 	// Clean up tables before each test
 	cleanup := func() {
-		pool.Exec(ctx, "TRUNCATE TABLE pr_reviewers, pull_requests, users, teams CASCADE")
+		pool.Exec(ctx, "TRUNCATE TABLE audit_events, pr_reviewers, pull_requests, users, teams CASCADE")
 	}
 
 	cleanup()
@@ -54,6 +54,34 @@ func setupTestDB(t *testing.T) (*pgxpool.Pool, func()) {
 	}
 }
 
+// newTestServer runs migrations and wires the full stack on top of pool.
+func newTestServer(t *testing.T, pool *pgxpool.Pool) *httptest.Server {
+	t.Helper()
+
+	ctx := context.Background()
+	if err := postgres.RunMigrations(ctx, pool, "../migration"); err != nil {
+		t.Fatalf("Failed to run migrations: %v", err)
+	}
+
+	teamRepo := postgres.NewTeamRepo(pool)
+	userRepo := postgres.NewUserRepo(pool)
+	prRepo := postgres.NewPullRequestRepo(pool)
+	auditRepo := postgres.NewAuditRepo(pool)
+	historyRepo := postgres.NewAssignmentHistoryRepo(pool)
+	statsRepo := postgres.NewStatsRepo(pool)
+	uow := postgres.NewUnitOfWork(pool)
+
+	teamService := service.NewTeamService(teamRepo, userRepo, uow)
+	prService := service.NewPRService(prRepo, userRepo, teamRepo, historyRepo, uow)
+	userService := service.NewUserService(userRepo, prRepo, prService, uow)
+	auditService := service.NewAuditService(auditRepo)
+	statsService := service.NewStatsService(statsRepo, teamRepo, userRepo)
+	importService := service.NewImportService(uow)
+
+	handler := httpTransport.NewHandler(teamService, userService, prService, auditService, statsService, importService)
+	return httptest.NewServer(httpTransport.NewRouter(handler))
+}
+
 func TestIntegrationFlow(t *testing.T) {
 	pool, teardown := setupTestDB(t)
 	if pool == nil {
@@ -72,14 +100,21 @@ func TestIntegrationFlow(t *testing.T) {
 	teamRepo := postgres.NewTeamRepo(pool)
 	userRepo := postgres.NewUserRepo(pool)
 	prRepo := postgres.NewPullRequestRepo(pool)
+	auditRepo := postgres.NewAuditRepo(pool)
+	historyRepo := postgres.NewAssignmentHistoryRepo(pool)
+	statsRepo := postgres.NewStatsRepo(pool)
+	uow := postgres.NewUnitOfWork(pool)
 
 	// Initialize services
-	teamService := service.NewTeamService(teamRepo, userRepo)
-	userService := service.NewUserService(userRepo, prRepo)
-	prService := service.NewPRService(prRepo, userRepo, teamRepo)
+	teamService := service.NewTeamService(teamRepo, userRepo, uow)
+	prService := service.NewPRService(prRepo, userRepo, teamRepo, historyRepo, uow)
+	userService := service.NewUserService(userRepo, prRepo, prService, uow)
+	auditService := service.NewAuditService(auditRepo)
+	statsService := service.NewStatsService(statsRepo, teamRepo, userRepo)
+	importService := service.NewImportService(uow)
 
 	// Initialize HTTP handler
-	handler := httpTransport.NewHandler(teamService, userService, prService)
+	handler := httpTransport.NewHandler(teamService, userService, prService, auditService, statsService, importService)
 	router := httpTransport.NewRouter(handler)
 
 	server := httptest.NewServer(router)
@@ -233,12 +268,19 @@ func TestStatsEndpoint(t *testing.T) {
 	teamRepo := postgres.NewTeamRepo(pool)
 	userRepo := postgres.NewUserRepo(pool)
 	prRepo := postgres.NewPullRequestRepo(pool)
-
-	teamService := service.NewTeamService(teamRepo, userRepo)
-	userService := service.NewUserService(userRepo, prRepo)
-	prService := service.NewPRService(prRepo, userRepo, teamRepo)
-
-	handler := httpTransport.NewHandler(teamService, userService, prService)
+	auditRepo := postgres.NewAuditRepo(pool)
+	historyRepo := postgres.NewAssignmentHistoryRepo(pool)
+	statsRepo := postgres.NewStatsRepo(pool)
+	uow := postgres.NewUnitOfWork(pool)
+
+	teamService := service.NewTeamService(teamRepo, userRepo, uow)
+	prService := service.NewPRService(prRepo, userRepo, teamRepo, historyRepo, uow)
+	userService := service.NewUserService(userRepo, prRepo, prService, uow)
+	auditService := service.NewAuditService(auditRepo)
+	statsService := service.NewStatsService(statsRepo, teamRepo, userRepo)
+	importService := service.NewImportService(uow)
+
+	handler := httpTransport.NewHandler(teamService, userService, prService, auditService, statsService, importService)
 	router := httpTransport.NewRouter(handler)
 
 	server := httptest.NewServer(router)
@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"pr-review-service/internal/domain"
+)
+
+func TestUserLifecycle(t *testing.T) {
+	pool, teardown := setupTestDB(t)
+	if pool == nil {
+		return
+	}
+	defer teardown()
+
+	server := newTestServer(t, pool)
+	defer server.Close()
+
+	team := domain.Team{
+		TeamName: "lifecycle",
+		Members: []domain.TeamMember{
+			{UserID: "lc1", Username: "Author", IsActive: true},
+			{UserID: "lc2", Username: "Reviewer", IsActive: true},
+			{UserID: "lc3", Username: "Spare", IsActive: true},
+			{UserID: "lc4", Username: "Secret Name", IsActive: true},
+		},
+	}
+	if status, _ := postJSON(t, server.URL+"/team/add", team); status != http.StatusCreated {
+		t.Fatalf("Expected status 201 creating team, got %d", status)
+	}
+
+	t.Run("Rename", func(t *testing.T) {
+		status, result := postJSON(t, server.URL+"/users/rename", map[string]string{
+			"user_id":  "lc3",
+			"username": "Renamed",
+		})
+		if status != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", status)
+		}
+		if name := result["user"].(map[string]interface{})["username"]; name != "Renamed" {
+			t.Errorf("Expected username Renamed, got %v", name)
+		}
+	})
+
+	t.Run("Author of open PR cannot be deleted", func(t *testing.T) {
+		if status, _ := postJSON(t, server.URL+"/pullRequest/create", map[string]string{
+			"pull_request_id":   "pr-lifecycle-1",
+			"pull_request_name": "Lifecycle",
+			"author_id":         "lc1",
+		}); status != http.StatusCreated {
+			t.Fatalf("Expected status 201 creating PR, got %d", status)
+		}
+
+		status, result := postJSON(t, server.URL+"/users/delete", map[string]string{"user_id": "lc1"})
+		if status != http.StatusConflict {
+			t.Fatalf("Expected status 409, got %d", status)
+		}
+		if code := result["error"].(map[string]interface{})["code"]; code != domain.ErrCodeUserHasOpenPRs {
+			t.Errorf("Expected %s, got %v", domain.ErrCodeUserHasOpenPRs, code)
+		}
+	})
+
+	t.Run("Delete reassigns open reviews", func(t *testing.T) {
+		status, _ := postJSON(t, server.URL+"/users/delete", map[string]string{"user_id": "lc2"})
+		if status != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", status)
+		}
+
+		_, page := listPRs(t, server.URL, url.Values{"author_id": {"lc1"}})
+		for _, pr := range page.PullRequests {
+			for _, r := range pr.AssignedReviewers {
+				if r == "lc2" {
+					t.Errorf("Deleted user is still reviewing %s", pr.PullRequestID)
+				}
+			}
+		}
+
+		_, users := listUsers(t, server.URL, url.Values{"team_name": {"lifecycle"}})
+		for _, u := range users.Users {
+			if u.UserID == "lc2" {
+				t.Error("Expected deleted user to be hidden from the directory")
+			}
+		}
+
+		if status, _ := postJSON(t, server.URL+"/users/setIsActive", map[string]interface{}{
+			"user_id":   "lc2",
+			"is_active": true,
+		}); status != http.StatusConflict {
+			t.Errorf("Expected status 409 reactivating deleted user, got %d", status)
+		}
+	})
+
+	t.Run("Anonymize scrubs username everywhere", func(t *testing.T) {
+		status, result := postJSON(t, server.URL+"/users/anonymize", map[string]string{"user_id": "lc4"})
+		if status != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", status)
+		}
+		user := result["user"].(map[string]interface{})
+		if user["username"] != domain.AnonymizedUsername || user["deleted_at"] == nil {
+			t.Errorf("Expected an anonymized, deleted user, got %v", user)
+		}
+
+		page := getAudit(t, server.URL, url.Values{"limit": {"500"}})
+		for _, e := range page.Events {
+			if strings.Contains(string(e.Before), "Secret Name") || strings.Contains(string(e.After), "Secret Name") {
+				t.Errorf("Audit event %d still contains the original username", e.ID)
+			}
+		}
+	})
+}
@@ -0,0 +1,213 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"pr-review-service/internal/domain"
+)
+
+type prPage struct {
+	PullRequests []domain.PullRequest `json:"pull_requests"`
+	NextCursor   string               `json:"next_cursor"`
+}
+
+func listPRs(t *testing.T, baseURL string, params url.Values) (int, prPage) {
+	t.Helper()
+
+	resp, err := http.Get(baseURL + "/pullRequests?" + params.Encode())
+	if err != nil {
+		t.Fatalf("Failed to list PRs: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var page prPage
+	json.NewDecoder(resp.Body).Decode(&page)
+	return resp.StatusCode, page
+}
+
+func TestListPullRequests(t *testing.T) {
+	pool, teardown := setupTestDB(t)
+	if pool == nil {
+		return
+	}
+	defer teardown()
+
+	server := newTestServer(t, pool)
+	defer server.Close()
+
+	team := domain.Team{
+		TeamName: "listing-team",
+		Members: []domain.TeamMember{
+			{UserID: "l1", Username: "Listing1", IsActive: true},
+			{UserID: "l2", Username: "Listing2", IsActive: true},
+			{UserID: "l3", Username: "Listing3", IsActive: true},
+		},
+	}
+	if status, _ := postJSON(t, server.URL+"/team/add", team); status != http.StatusCreated {
+		t.Fatalf("Expected status 201 creating team, got %d", status)
+	}
+
+	for i := 1; i <= 5; i++ {
+		author := "l1"
+		if i%2 == 0 {
+			author = "l2"
+		}
+		if status, _ := postJSON(t, server.URL+"/pullRequest/create", map[string]string{
+			"pull_request_id":   fmt.Sprintf("pr-list-%d", i),
+			"pull_request_name": fmt.Sprintf("Listing %c", 'E'-i+1),
+			"author_id":         author,
+		}); status != http.StatusCreated {
+			t.Fatalf("Expected status 201 creating PR, got %d", status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	for _, id := range []string{"pr-list-1", "pr-list-2"} {
+		if status, _ := postJSON(t, server.URL+"/pullRequest/merge", map[string]string{"pull_request_id": id}); status != http.StatusOK {
+			t.Fatalf("Expected status 200 merging PR, got %d", status)
+		}
+	}
+
+	t.Run("Filter by status and team", func(t *testing.T) {
+		_, page := listPRs(t, server.URL, url.Values{"team_name": {"listing-team"}, "status": {"MERGED"}})
+		if len(page.PullRequests) != 2 {
+			t.Errorf("Expected 2 merged PRs, got %d", len(page.PullRequests))
+		}
+	})
+
+	t.Run("Filter by author", func(t *testing.T) {
+		_, page := listPRs(t, server.URL, url.Values{"author_id": {"l2"}})
+		if len(page.PullRequests) != 2 {
+			t.Errorf("Expected 2 PRs by l2, got %d", len(page.PullRequests))
+		}
+	})
+
+	t.Run("Filter by reviewer", func(t *testing.T) {
+		_, page := listPRs(t, server.URL, url.Values{"reviewer_id": {"l3"}})
+		for _, pr := range page.PullRequests {
+			found := false
+			for _, r := range pr.AssignedReviewers {
+				found = found || r == "l3"
+			}
+			if !found {
+				t.Errorf("PR %s is not reviewed by l3", pr.PullRequestID)
+			}
+		}
+	})
+
+	t.Run("Paginate newest first", func(t *testing.T) {
+		var ids []string
+		params := url.Values{"team_name": {"listing-team"}, "limit": {"2"}}
+		for {
+			status, page := listPRs(t, server.URL, params)
+			if status != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", status)
+			}
+			for _, pr := range page.PullRequests {
+				ids = append(ids, pr.PullRequestID)
+			}
+			if page.NextCursor == "" {
+				break
+			}
+			params.Set("cursor", page.NextCursor)
+		}
+
+		expected := []string{"pr-list-5", "pr-list-4", "pr-list-3", "pr-list-2", "pr-list-1"}
+		if fmt.Sprint(ids) != fmt.Sprint(expected) {
+			t.Errorf("Expected %v, got %v", expected, ids)
+		}
+	})
+
+	t.Run("Sort by name ascending", func(t *testing.T) {
+		_, page := listPRs(t, server.URL, url.Values{"team_name": {"listing-team"}, "sort": {"pull_request_name"}})
+		for i := 1; i < len(page.PullRequests); i++ {
+			if page.PullRequests[i-1].PullRequestName > page.PullRequests[i].PullRequestName {
+				t.Errorf("PRs are not sorted by name: %s before %s",
+					page.PullRequests[i-1].PullRequestName, page.PullRequests[i].PullRequestName)
+			}
+		}
+	})
+
+	t.Run("Sort by merged_at pages through open PRs", func(t *testing.T) {
+		seen := 0
+		params := url.Values{"team_name": {"listing-team"}, "sort": {"-merged_at"}, "limit": {"1"}}
+		for {
+			_, page := listPRs(t, server.URL, params)
+			seen += len(page.PullRequests)
+			if page.NextCursor == "" {
+				break
+			}
+			params.Set("cursor", page.NextCursor)
+		}
+		if seen != 5 {
+			t.Errorf("Expected to page through 5 PRs, got %d", seen)
+		}
+	})
+
+	t.Run("Invalid input", func(t *testing.T) {
+		for _, params := range []url.Values{
+			{"sort": {"author_id"}},
+			{"status": {"CLOSED"}},
+			{"created_from": {"yesterday"}},
+			{"cursor": {"garbage"}},
+		} {
+			if status, _ := listPRs(t, server.URL, params); status != http.StatusBadRequest {
+				t.Errorf("Expected status 400 for %v, got %d", params, status)
+			}
+		}
+	})
+}
+
+func TestListPullRequestsWithOffsetBounds(t *testing.T) {
+	for name, newBackend := range testBackends {
+		t.Run(name, func(t *testing.T) {
+			server, _ := newBackend(t)
+			defer server.Close()
+
+			team := domain.Team{
+				TeamName: "listing-offset",
+				Members: []domain.TeamMember{
+					{UserID: "lo1", Username: "Offset1", IsActive: true},
+					{UserID: "lo2", Username: "Offset2", IsActive: true},
+				},
+			}
+			if status, _ := postJSON(t, server.URL+"/team/add", team); status != http.StatusCreated {
+				t.Fatalf("Expected status 201 creating team, got %d", status)
+			}
+
+			from := time.Now().Add(-time.Minute)
+			if status, _ := postJSON(t, server.URL+"/pullRequest/create", map[string]string{
+				"pull_request_id":   "pr-lo-1",
+				"pull_request_name": "Offset",
+				"author_id":         "lo1",
+			}); status != http.StatusCreated {
+				t.Fatalf("Expected status 201 creating PR, got %d", status)
+			}
+			if status, _ := postJSON(t, server.URL+"/pullRequest/merge", map[string]string{"pull_request_id": "pr-lo-1"}); status != http.StatusOK {
+				t.Fatalf("Expected status 200 merging PR, got %d", status)
+			}
+			to := time.Now().Add(time.Minute)
+
+			for _, zone := range []*time.Location{time.UTC, utcPlus3} {
+				for _, bounds := range [][2]string{{"created_from", "created_to"}, {"merged_from", "merged_to"}} {
+					status, page := listPRs(t, server.URL, url.Values{
+						bounds[0]: {from.In(zone).Format(time.RFC3339)},
+						bounds[1]: {to.In(zone).Format(time.RFC3339)},
+					})
+					if status != http.StatusOK || len(page.PullRequests) != 1 {
+						t.Errorf("Expected pr-lo-1 within %s bounds %v, got %d %+v", zone, bounds, status, page.PullRequests)
+					}
+
+					_, page = listPRs(t, server.URL, url.Values{bounds[0]: {to.In(zone).Format(time.RFC3339)}})
+					if len(page.PullRequests) != 0 {
+						t.Errorf("Expected nothing after %s for %s, got %+v", to.In(zone).Format(time.RFC3339), bounds[0], page.PullRequests)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"pr-review-service/internal/domain"
+	"pr-review-service/internal/repository"
+	"pr-review-service/internal/repository/memory"
+	"pr-review-service/internal/service"
+	httpTransport "pr-review-service/internal/transport/http"
+)
+
+// newMemoryTestServer wires the services to the in-memory repositories, so
+// the tests using it run without a database.
+func newMemoryTestServer(t *testing.T) (*httptest.Server, *memory.Store) {
+	t.Helper()
+
+	store := memory.NewStore()
+	teamRepo := memory.NewTeamRepo(store)
+	userRepo := memory.NewUserRepo(store)
+	prRepo := memory.NewPullRequestRepo(store)
+	auditRepo := memory.NewAuditRepo(store)
+	historyRepo := memory.NewAssignmentHistoryRepo(store)
+	statsRepo := memory.NewStatsRepo(store)
+	uow := memory.NewUnitOfWork(store)
+
+	teamService := service.NewTeamService(teamRepo, userRepo, uow)
+	prService := service.NewPRService(prRepo, userRepo, teamRepo, historyRepo, uow)
+	userService := service.NewUserService(userRepo, prRepo, prService, uow)
+	auditService := service.NewAuditService(auditRepo)
+	statsService := service.NewStatsService(statsRepo, teamRepo, userRepo)
+	importService := service.NewImportService(uow)
+
+	handler := httpTransport.NewHandler(teamService, userService, prService, auditService, statsService, importService)
+	return httptest.NewServer(httpTransport.NewRouter(handler)), store
+}
+
+func TestMemoryStorage(t *testing.T) {
+	server, store := newMemoryTestServer(t)
+	defer server.Close()
+
+	ctx := context.Background()
+
+	team := domain.Team{
+		TeamName: "memory",
+		Members: []domain.TeamMember{
+			{UserID: "m1", Username: "Author", IsActive: true},
+			{UserID: "m2", Username: "Reviewer", IsActive: true},
+			{UserID: "m3", Username: "Secret Name", IsActive: true},
+			{UserID: "m4", Username: "Spare", IsActive: true},
+		},
+	}
+	if status, _ := postJSON(t, server.URL+"/team/add", team); status != http.StatusCreated {
+		t.Fatalf("Expected status 201 creating team, got %d", status)
+	}
+
+	t.Run("Create, reassign and merge", func(t *testing.T) {
+		status, result := postJSON(t, server.URL+"/pullRequest/create", map[string]string{
+			"pull_request_id":   "pr-memory-1",
+			"pull_request_name": "Memory",
+			"author_id":         "m1",
+		})
+		if status != http.StatusCreated {
+			t.Fatalf("Expected status 201 creating PR, got %d", status)
+		}
+		reviewers := result["pr"].(map[string]interface{})["assigned_reviewers"].([]interface{})
+		if len(reviewers) != 2 {
+			t.Fatalf("Expected 2 reviewers, got %v", reviewers)
+		}
+
+		status, _ = postJSON(t, server.URL+"/pullRequest/reassign", map[string]string{
+			"pull_request_id": "pr-memory-1",
+			"old_user_id":     reviewers[0].(string),
+		})
+		if status != http.StatusOK {
+			t.Fatalf("Expected status 200 reassigning, got %d", status)
+		}
+		assertMemoryReviewerInvariants(t, memory.NewPullRequestRepo(store), "pr-memory-1", "m1")
+
+		status, result = postJSON(t, server.URL+"/pullRequest/merge", map[string]string{"pull_request_id": "pr-memory-1"})
+		if status != http.StatusOK {
+			t.Fatalf("Expected status 200 merging, got %d", status)
+		}
+		if s := result["pr"].(map[string]interface{})["status"]; s != string(domain.PRStatusMerged) {
+			t.Errorf("Expected status MERGED, got %v", s)
+		}
+
+		_, page := listPRs(t, server.URL, url.Values{"status": {string(domain.PRStatusMerged)}})
+		if len(page.PullRequests) != 1 || page.PullRequests[0].PullRequestID != "pr-memory-1" {
+			t.Errorf("Expected the merged PR in the listing, got %v", page.PullRequests)
+		}
+	})
+
+	t.Run("Concurrent create of the same PR", func(t *testing.T) {
+		statuses := fireConcurrently(t, concurrentRequests, server.URL+"/pullRequest/create", map[string]string{
+			"pull_request_id":   "pr-memory-race",
+			"pull_request_name": "Race",
+			"author_id":         "m1",
+		})
+
+		counts := countStatuses(statuses)
+		if counts[http.StatusCreated] != 1 || counts[http.StatusConflict] != concurrentRequests-1 {
+			t.Errorf("Expected one 201 and %d conflicts, got statuses %v", concurrentRequests-1, counts)
+		}
+	})
+
+	t.Run("Anonymize scrubs username everywhere", func(t *testing.T) {
+		status, _ := postJSON(t, server.URL+"/users/anonymize", map[string]string{"user_id": "m3"})
+		if status != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", status)
+		}
+
+		page := getAudit(t, server.URL, url.Values{"limit": {"500"}})
+		if len(page.Events) == 0 {
+			t.Fatal("Expected audit events")
+		}
+		for _, e := range page.Events {
+			if strings.Contains(string(e.Before), "Secret Name") || strings.Contains(string(e.After), "Secret Name") {
+				t.Errorf("Audit event %d still contains the original username", e.ID)
+			}
+		}
+	})
+
+	t.Run("Deactivate team", func(t *testing.T) {
+		status, _ := postJSON(t, server.URL+"/team/deactivate-all?team_name=memory", nil)
+		if status != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", status)
+		}
+
+		team, err := memory.NewTeamRepo(store).Get(ctx, "memory")
+		if err != nil {
+			t.Fatalf("Failed to get team: %v", err)
+		}
+		for _, m := range team.Members {
+			if m.IsActive {
+				t.Errorf("Expected %s to be inactive", m.UserID)
+			}
+		}
+	})
+
+	t.Run("Unit of work rolls back on error", func(t *testing.T) {
+		errAbort := errors.New("abort")
+		err := memory.NewUnitOfWork(store).WithinTx(ctx, func(ctx context.Context, repos repository.Repository) error {
+			if err := repos.Team.Create(ctx, &domain.Team{TeamName: "rolled-back"}); err != nil {
+				return err
+			}
+			return errAbort
+		})
+		if !errors.Is(err, errAbort) {
+			t.Fatalf("Expected the error from fn, got %v", err)
+		}
+
+		exists, err := memory.NewTeamRepo(store).Exists(ctx, "rolled-back")
+		if err != nil {
+			t.Fatalf("Failed to check team: %v", err)
+		}
+		if exists {
+			t.Error("Expected the team created inside the failed unit of work to be gone")
+		}
+	})
+}
+
+func assertMemoryReviewerInvariants(t *testing.T, prRepo *memory.PullRequestRepo, prID, authorID string) {
+	t.Helper()
+
+	reviewers, err := prRepo.GetReviewers(context.Background(), prID)
+	if err != nil {
+		t.Fatalf("Failed to get reviewers: %v", err)
+	}
+	seen := make(map[string]bool)
+	for _, r := range reviewers {
+		if r == authorID {
+			t.Errorf("Author %s is reviewing their own PR %s", authorID, prID)
+		}
+		if seen[r] {
+			t.Errorf("Reviewer %s assigned twice to %s", r, prID)
+		}
+		seen[r] = true
+	}
+}
@@ -0,0 +1,148 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"pr-review-service/internal/repository/migrate"
+	"pr-review-service/internal/repository/postgres"
+	"pr-review-service/migration"
+)
+
+func TestMigrationSource(t *testing.T) {
+	if _, err := postgres.NewMigrator(nil, os.DirFS("../migration")); err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+	if _, err := postgres.NewMigrator(nil, migration.FS); err != nil {
+		t.Fatalf("Failed to load embedded migrations: %v", err)
+	}
+
+	for name, fsys := range map[string]fstest.MapFS{
+		"unversioned file": {"init.sql": {Data: []byte("SELECT 1")}},
+		"missing up":       {"01_init.down.sql": {Data: []byte("SELECT 1")}},
+		"version clash": {
+			"01_init.up.sql":  {Data: []byte("SELECT 1")},
+			"01_other.up.sql": {Data: []byte("SELECT 1")},
+		},
+	} {
+		if _, err := postgres.NewMigrator(nil, fsys); err == nil {
+			t.Errorf("Expected %s to be rejected", name)
+		}
+	}
+}
+
+func TestMigrator(t *testing.T) {
+	pool, teardown := setupTestDB(t)
+	if pool == nil {
+		return
+	}
+	defer teardown()
+
+	ctx := context.Background()
+	if err := postgres.RunMigrations(ctx, pool, "../migration"); err != nil {
+		t.Fatalf("Failed to run migrations: %v", err)
+	}
+
+	t.Run("Seeds are not applied by default", func(t *testing.T) {
+		if err := postgres.RunMigrations(ctx, pool, "../migration"); err != nil {
+			t.Fatalf("Failed to rerun migrations: %v", err)
+		}
+		var users int
+		pool.QueryRow(ctx, "SELECT COUNT(*) FROM users").Scan(&users)
+		if users != 0 {
+			t.Errorf("Expected no demo users, got %d", users)
+		}
+	})
+
+	// A private migration with a version far past the real ones.
+	source := fstest.MapFS{
+		"9001_widgets.up.sql":   {Data: []byte("CREATE TABLE migrate_test_widgets (id INTEGER)")},
+		"9001_widgets.down.sql": {Data: []byte("DROP TABLE migrate_test_widgets")},
+	}
+	migrator, err := postgres.NewMigrator(pool, source)
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+	defer migrator.Down(ctx, 1)
+
+	t.Run("Concurrent Up applies pending migrations once", func(t *testing.T) {
+		const replicas = 5
+		var wg sync.WaitGroup
+		results := make(chan int, replicas)
+		errs := make(chan error, replicas)
+		for i := 0; i < replicas; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				applied, err := migrator.Up(ctx, false)
+				if err != nil {
+					errs <- err
+					return
+				}
+				results <- len(applied)
+			}()
+		}
+		wg.Wait()
+		close(results)
+		close(errs)
+
+		for err := range errs {
+			t.Errorf("Up failed: %v", err)
+		}
+		total := 0
+		for n := range results {
+			total += n
+		}
+		if total != 1 {
+			t.Errorf("Expected the migration to be applied exactly once, got %d", total)
+		}
+	})
+
+	t.Run("Status", func(t *testing.T) {
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			t.Fatalf("Failed to get status: %v", err)
+		}
+		if statuses[0].Version != 9001 || statuses[0].AppliedAt == nil {
+			t.Errorf("Expected 9001 to be applied, got %+v", statuses[0])
+		}
+		for _, s := range statuses[1:] {
+			if !s.Missing {
+				t.Errorf("Expected real migrations to be reported as missing, got %+v", s)
+			}
+		}
+	})
+
+	t.Run("Edited migrations are rejected", func(t *testing.T) {
+		edited := fstest.MapFS{
+			"9001_widgets.up.sql": {Data: []byte("CREATE TABLE migrate_test_widgets (id BIGINT)")},
+		}
+		m, err := postgres.NewMigrator(pool, edited)
+		if err != nil {
+			t.Fatalf("Failed to load migrations: %v", err)
+		}
+		if _, err := m.Up(ctx, false); !errors.Is(err, migrate.ErrMigrationModified) {
+			t.Errorf("Expected ErrMigrationModified, got %v", err)
+		}
+	})
+
+	t.Run("Redo and down", func(t *testing.T) {
+		if m, err := migrator.Redo(ctx); err != nil || m.Version != 9001 {
+			t.Fatalf("Expected 9001 to be redone, got %v, %v", m, err)
+		}
+
+		rolledBack, err := migrator.Down(ctx, 1)
+		if err != nil || len(rolledBack) != 1 {
+			t.Fatalf("Expected one rolled back migration, got %v, %v", rolledBack, err)
+		}
+		var exists bool
+		pool.QueryRow(ctx, "SELECT to_regclass('migrate_test_widgets') IS NOT NULL").Scan(&exists)
+		if exists {
+			t.Error("Expected migrate_test_widgets to be dropped")
+		}
+	})
+}
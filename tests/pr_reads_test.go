@@ -0,0 +1,178 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"pr-review-service/internal/domain"
+	"pr-review-service/internal/repository"
+	"pr-review-service/internal/repository/postgres"
+	"pr-review-service/internal/repository/sqlite"
+)
+
+const (
+	benchReviewers = 20
+	benchOpenPRs   = 300
+)
+
+// BenchmarkOpenPRsByReviewers loads the open PRs of a team the way team
+// deactivation does. PerPR is the former access pattern, one query for the
+// PRs and one more per PR for its reviewers; Batched is GetOpenPRsByReviewers,
+// which fetches the reviewers in the same query.
+func BenchmarkOpenPRsByReviewers(b *testing.B) {
+	b.Run("SQLite", func(b *testing.B) {
+		db := newSQLiteTestDB(b)
+		repos := repository.Repository{
+			Team:        sqlite.NewTeamRepo(db),
+			User:        sqlite.NewUserRepo(db),
+			PullRequest: sqlite.NewPullRequestRepo(db),
+		}
+		openPRIDs := func(ctx context.Context, userIDs []string) ([]string, error) {
+			rows, err := db.QueryContext(ctx, `
+				SELECT DISTINCT pr.pull_request_id
+				FROM pull_requests pr
+				INNER JOIN pr_reviewers prr ON pr.pull_request_id = prr.pull_request_id
+				WHERE pr.status = 'OPEN' AND prr.user_id IN (SELECT value FROM json_each(?))
+				ORDER BY pr.pull_request_id`, jsonIDs(userIDs))
+			if err != nil {
+				return nil, err
+			}
+			defer rows.Close()
+			return scanIDs(rows)
+		}
+		benchmarkOpenPRsByReviewers(b, repos, openPRIDs)
+	})
+
+	b.Run("Postgres", func(b *testing.B) {
+		pool, teardown := setupTestDB(b)
+		if pool == nil {
+			return
+		}
+		defer teardown()
+
+		if err := postgres.RunMigrations(context.Background(), pool, "../migration"); err != nil {
+			b.Fatalf("Failed to run migrations: %v", err)
+		}
+		repos := repository.Repository{
+			Team:        postgres.NewTeamRepo(pool),
+			User:        postgres.NewUserRepo(pool),
+			PullRequest: postgres.NewPullRequestRepo(pool),
+		}
+		openPRIDs := func(ctx context.Context, userIDs []string) ([]string, error) {
+			rows, err := pool.Query(ctx, `
+				SELECT DISTINCT pr.pull_request_id
+				FROM pull_requests pr
+				INNER JOIN pr_reviewers prr ON pr.pull_request_id = prr.pull_request_id
+				WHERE pr.status = 'OPEN' AND prr.user_id = ANY($1)
+				ORDER BY pr.pull_request_id`, userIDs)
+			if err != nil {
+				return nil, err
+			}
+			defer rows.Close()
+			return scanIDs(rows)
+		}
+		benchmarkOpenPRsByReviewers(b, repos, openPRIDs)
+	})
+}
+
+func benchmarkOpenPRsByReviewers(b *testing.B, repos repository.Repository,
+	openPRIDs func(ctx context.Context, userIDs []string) ([]string, error)) {
+	ctx := context.Background()
+
+	team := &domain.Team{TeamName: "bench"}
+	if err := repos.Team.Create(ctx, team); err != nil {
+		b.Fatalf("Failed to create team: %v", err)
+	}
+	userIDs := make([]string, benchReviewers)
+	for i := range userIDs {
+		userIDs[i] = fmt.Sprintf("bench-u%02d", i)
+		user := &domain.User{UserID: userIDs[i], Username: userIDs[i], TeamName: team.TeamName, IsActive: true}
+		if err := repos.User.Create(ctx, user); err != nil {
+			b.Fatalf("Failed to create user: %v", err)
+		}
+	}
+	for i := 0; i < benchOpenPRs; i++ {
+		pr := &domain.PullRequest{
+			PullRequestID:     fmt.Sprintf("bench-pr-%03d", i),
+			PullRequestName:   "Bench",
+			AuthorID:          userIDs[i%benchReviewers],
+			Status:            domain.PRStatusOpen,
+			AssignedReviewers: []string{userIDs[(i+1)%benchReviewers], userIDs[(i+2)%benchReviewers]},
+		}
+		if err := repos.PullRequest.Create(ctx, pr); err != nil {
+			b.Fatalf("Failed to create PR: %v", err)
+		}
+	}
+
+	perPR := func() ([]domain.PullRequest, error) {
+		ids, err := openPRIDs(ctx, userIDs)
+		if err != nil {
+			return nil, err
+		}
+		prs := make([]domain.PullRequest, len(ids))
+		for i, id := range ids {
+			reviewers, err := repos.PullRequest.GetReviewers(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			prs[i] = domain.PullRequest{PullRequestID: id, AssignedReviewers: reviewers}
+		}
+		return prs, nil
+	}
+
+	batched, err := repos.PullRequest.GetOpenPRsByReviewers(ctx, userIDs)
+	if err != nil {
+		b.Fatalf("GetOpenPRsByReviewers failed: %v", err)
+	}
+	baseline, err := perPR()
+	if err != nil {
+		b.Fatalf("Per-PR load failed: %v", err)
+	}
+	if len(batched) != benchOpenPRs || len(baseline) != benchOpenPRs {
+		b.Fatalf("Expected %d PRs, got %d batched and %d per PR", benchOpenPRs, len(batched), len(baseline))
+	}
+	for i := range batched {
+		if fmt.Sprint(batched[i].AssignedReviewers) != fmt.Sprint(baseline[i].AssignedReviewers) {
+			b.Fatalf("Reviewers of %s differ: %v batched, %v per PR",
+				batched[i].PullRequestID, batched[i].AssignedReviewers, baseline[i].AssignedReviewers)
+		}
+	}
+
+	b.Run("PerPR", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := perPR(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Batched", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := repos.PullRequest.GetOpenPRsByReviewers(ctx, userIDs); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func scanIDs(rows interface {
+	Next() bool
+	Scan(...any) error
+	Err() error
+}) ([]string, error) {
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func jsonIDs(ids []string) string {
+	encoded, _ := json.Marshal(ids)
+	return string(encoded)
+}
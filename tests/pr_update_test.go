@@ -0,0 +1,158 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"pr-review-service/internal/domain"
+	"pr-review-service/internal/repository"
+)
+
+func TestPRUpdate(t *testing.T) {
+	for name, newBackend := range testBackends {
+		t.Run(name, func(t *testing.T) {
+			server, repos := newBackend(t)
+			defer server.Close()
+			testPRUpdate(t, server.URL, repos)
+		})
+	}
+}
+
+func testPRUpdate(t *testing.T, serverURL string, repos repository.Repository) {
+	ctx := context.Background()
+
+	team := domain.Team{
+		TeamName: "metadata",
+		Members: []domain.TeamMember{
+			{UserID: "md-author", Username: "Author", IsActive: true},
+			{UserID: "md-other", Username: "Other", IsActive: true},
+		},
+	}
+	if status, _ := postJSON(t, serverURL+"/team/add", team); status != http.StatusCreated {
+		t.Fatalf("Expected status 201 creating team, got %d", status)
+	}
+	if status, _ := postJSON(t, serverURL+"/pullRequest/create", map[string]string{
+		"pull_request_id":   "pr-md-1",
+		"pull_request_name": "Draft",
+		"author_id":         "md-author",
+	}); status != http.StatusCreated {
+		t.Fatalf("Expected status 201 creating PR, got %d", status)
+	}
+
+	update := func(t *testing.T, ifMatch string, payload map[string]interface{}) (int, domain.PullRequest, string) {
+		t.Helper()
+
+		payload["pull_request_id"] = "pr-md-1"
+		resp := postWithIfMatch(t, serverURL+"/pullRequest/update", ifMatch, payload)
+		defer resp.Body.Close()
+
+		var body struct {
+			PR    domain.PullRequest `json:"pr"`
+			Error struct {
+				Code string `json:"code"`
+			} `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		return resp.StatusCode, body.PR, body.Error.Code
+	}
+
+	t.Run("Set every field", func(t *testing.T) {
+		status, pr, _ := update(t, `"1"`, map[string]interface{}{
+			"pull_request_name": " Add metadata ",
+			"author_id":         "md-author",
+			"description":       "Lets authors describe PRs.",
+			"url":               "https://git.example.com/svc/pull/7",
+			"repository":        "svc",
+			"labels":            []string{"api", " backend", "api"},
+			"size":              "M",
+		})
+		if status != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", status)
+		}
+		want := domain.PullRequest{
+			PullRequestName: "Add metadata",
+			Description:     "Lets authors describe PRs.",
+			URL:             "https://git.example.com/svc/pull/7",
+			Repository:      "svc",
+			Labels:          []string{"api", "backend"},
+			Size:            domain.PRSizeM,
+		}
+		got := domain.PullRequest{
+			PullRequestName: pr.PullRequestName,
+			Description:     pr.Description,
+			URL:             pr.URL,
+			Repository:      pr.Repository,
+			Labels:          pr.Labels,
+			Size:            pr.Size,
+		}
+		if !reflect.DeepEqual(got, want) || pr.AuthorID != "md-author" || len(pr.AssignedReviewers) != 1 {
+			t.Errorf("Expected %+v, got %+v", want, pr)
+		}
+	})
+
+	t.Run("Omitted fields stay", func(t *testing.T) {
+		status, pr, _ := update(t, "", map[string]interface{}{"labels": []string{}, "size": ""})
+		if status != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", status)
+		}
+		if pr.PullRequestName != "Add metadata" || pr.Repository != "svc" || pr.Size != "" ||
+			pr.Labels == nil || len(pr.Labels) != 0 {
+			t.Errorf("Expected only the labels and size to be cleared, got %+v", pr)
+		}
+	})
+
+	t.Run("Long repository", func(t *testing.T) {
+		repo := strings.Repeat("r", 300)
+		status, pr, _ := update(t, "", map[string]interface{}{"repository": repo})
+		if status != http.StatusOK || pr.Repository != repo {
+			t.Errorf("Expected every backend to store a long repository name, got %d %q", status, pr.Repository)
+		}
+		if status, _, _ := update(t, "", map[string]interface{}{"repository": "svc"}); status != http.StatusOK {
+			t.Fatalf("Expected status 200 restoring the repository, got %d", status)
+		}
+	})
+
+	for _, tc := range []struct {
+		name    string
+		ifMatch string
+		payload map[string]interface{}
+		status  int
+		code    string
+	}{
+		{"Change the author", "", map[string]interface{}{"author_id": "md-other"}, http.StatusBadRequest, domain.ErrCodeAuthorImmutable},
+		{"Empty name", "", map[string]interface{}{"pull_request_name": "  "}, http.StatusBadRequest, domain.ErrCodeInvalidInput},
+		{"Relative URL", "", map[string]interface{}{"url": "svc/pull/7"}, http.StatusBadRequest, domain.ErrCodeInvalidInput},
+		{"Unknown size", "", map[string]interface{}{"size": "XXL"}, http.StatusBadRequest, domain.ErrCodeInvalidInput},
+		{"Empty label", "", map[string]interface{}{"labels": []string{"api", ""}}, http.StatusBadRequest, domain.ErrCodeInvalidInput},
+		{"Stale version", `"1"`, map[string]interface{}{"description": "Late"}, http.StatusPreconditionFailed, domain.ErrCodeVersionMismatch},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			status, _, code := update(t, tc.ifMatch, tc.payload)
+			if status != tc.status || code != tc.code {
+				t.Errorf("Expected %d %s, got %d %s", tc.status, tc.code, status, code)
+			}
+		})
+	}
+
+	pr, err := repos.PullRequest.Get(ctx, "pr-md-1")
+	if err != nil {
+		t.Fatalf("Failed to get PR: %v", err)
+	}
+	if pr.AuthorID != "md-author" || pr.Description != "Lets authors describe PRs." || pr.Version != 5 {
+		t.Errorf("Expected rejected updates to change nothing, got %+v", pr)
+	}
+
+	t.Run("Merged PR", func(t *testing.T) {
+		if status, _ := postJSON(t, serverURL+"/pullRequest/merge", map[string]string{"pull_request_id": "pr-md-1"}); status != http.StatusOK {
+			t.Fatalf("Expected status 200 merging, got %d", status)
+		}
+		status, pr, _ := update(t, "", map[string]interface{}{"labels": []string{"shipped"}})
+		if status != http.StatusOK || !reflect.DeepEqual(pr.Labels, []string{"shipped"}) || pr.Status != domain.PRStatusMerged {
+			t.Errorf("Expected a merged PR to accept metadata, got %d %+v", status, pr)
+		}
+	})
+}
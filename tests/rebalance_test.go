@@ -0,0 +1,168 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"pr-review-service/internal/domain"
+	"pr-review-service/internal/repository"
+)
+
+type bulkResponse struct {
+	Team          *domain.Team                  `json:"team"`
+	Reassignments []domain.ReviewerReassignment `json:"reassignments"`
+}
+
+// postBulk runs a bulk team operation, or previews it with dryRun, and
+// returns the status and the decoded response.
+func postBulk(t *testing.T, serverURL, path, teamName string, dryRun bool, payload interface{}) (int, bulkResponse) {
+	t.Helper()
+
+	var body []byte
+	if payload != nil {
+		body, _ = json.Marshal(payload)
+	}
+	query := url.Values{"team_name": {teamName}, "dry_run": {strconv.FormatBool(dryRun)}}
+	resp, err := http.Post(serverURL+path+"?"+query.Encode(), "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("Request to %s failed: %v", path, err)
+	}
+	defer resp.Body.Close()
+
+	var result bulkResponse
+	json.NewDecoder(resp.Body).Decode(&result)
+	return resp.StatusCode, result
+}
+
+func TestTeamActivationAndRebalance(t *testing.T) {
+	for name, newBackend := range testBackends {
+		t.Run(name, func(t *testing.T) {
+			server, repos := newBackend(t)
+			defer server.Close()
+			testTeamActivationAndRebalance(t, server.URL, repos)
+		})
+	}
+}
+
+func testTeamActivationAndRebalance(t *testing.T, serverURL string, repos repository.Repository) {
+	ctx := context.Background()
+
+	team := domain.Team{
+		TeamName: "returning",
+		Members: []domain.TeamMember{
+			{UserID: "rt-a1", Username: "Author 1", IsActive: true},
+			{UserID: "rt-a2", Username: "Author 2", IsActive: true},
+			{UserID: "rt-busy1", Username: "Busy 1", IsActive: true},
+			{UserID: "rt-busy2", Username: "Busy 2", IsActive: true},
+			{UserID: "rt-back1", Username: "Back 1", IsActive: false},
+			{UserID: "rt-back2", Username: "Back 2", IsActive: false},
+			{UserID: "rt-away", Username: "Away", IsActive: false},
+		},
+	}
+	if status, _ := postJSON(t, serverURL+"/team/add", team); status != http.StatusCreated {
+		t.Fatalf("Expected status 201 creating team, got %d", status)
+	}
+	seedReviews(t, repos,
+		domain.PullRequest{PullRequestID: "pr-rt-1", AuthorID: "rt-a1", AssignedReviewers: []string{"rt-busy1", "rt-busy2"}},
+		domain.PullRequest{PullRequestID: "pr-rt-2", AuthorID: "rt-a1", AssignedReviewers: []string{"rt-busy1", "rt-busy2"}},
+		domain.PullRequest{PullRequestID: "pr-rt-3", AuthorID: "rt-a2", AssignedReviewers: []string{"rt-busy1", "rt-busy2"}},
+		domain.PullRequest{PullRequestID: "pr-rt-4", AuthorID: "rt-a2", AssignedReviewers: []string{"rt-busy1", "rt-a1"}},
+	)
+
+	t.Run("Unknown member", func(t *testing.T) {
+		status, _ := postBulk(t, serverURL, "/team/activate-all", "returning", false, map[string]interface{}{
+			"user_ids": []string{"rt-back1", "someone-else"},
+		})
+		if status != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", status)
+		}
+	})
+
+	payload := map[string]interface{}{"user_ids": []string{"rt-back1", "rt-back2"}, "rebalance": true}
+
+	status, preview := postBulk(t, serverURL, "/team/activate-all", "returning", true, payload)
+	if status != http.StatusOK {
+		t.Fatalf("Expected status 200 previewing, got %d", status)
+	}
+	if len(preview.Reassignments) == 0 {
+		t.Fatal("Expected the preview to plan moves onto the returning members")
+	}
+	if reviewLoads(t, repos)["rt-busy1"] != 4 {
+		t.Fatal("Expected the preview to leave the reviews alone")
+	}
+
+	status, result := postBulk(t, serverURL, "/team/activate-all", "returning", false, payload)
+	if status != http.StatusOK {
+		t.Fatalf("Expected status 200 activating, got %d", status)
+	}
+	if !reflect.DeepEqual(result.Reassignments, preview.Reassignments) {
+		t.Errorf("Expected the run to match its preview:\n%+v\n%+v", result.Reassignments, preview.Reassignments)
+	}
+	for _, m := range result.Team.Members {
+		if m.IsActive != (m.UserID != "rt-away") {
+			t.Errorf("Expected only rt-away to stay inactive, got %+v", m)
+		}
+	}
+
+	loads := reviewLoads(t, repos)
+	total, lowest, highest := 0, loads["rt-busy1"], loads["rt-busy1"]
+	for _, id := range []string{"rt-a1", "rt-a2", "rt-busy1", "rt-busy2", "rt-back1", "rt-back2"} {
+		total += loads[id]
+		lowest = min(lowest, loads[id])
+		highest = max(highest, loads[id])
+	}
+	if total != 8 || highest-lowest > 1 {
+		t.Errorf("Expected 8 reviews spread evenly over the active members, got %v", loads)
+	}
+	if loads["rt-away"] != 0 {
+		t.Errorf("Expected nothing to move to an inactive member, got %v", loads)
+	}
+
+	history, err := repos.History.ListByPR(ctx, result.Reassignments[0].PullRequestID)
+	if err != nil {
+		t.Fatalf("Failed to list history: %v", err)
+	}
+	var rebalanced int
+	for _, rec := range history {
+		if rec.AssignReason == domain.AssignmentReasonRebalance {
+			rebalanced++
+		}
+	}
+	if rebalanced == 0 {
+		t.Errorf("Expected the moves to be recorded as REBALANCE, got %+v", history)
+	}
+
+	status, again := postBulk(t, serverURL, "/team/rebalance", "returning", false, nil)
+	if status != http.StatusOK {
+		t.Fatalf("Expected status 200 rebalancing, got %d", status)
+	}
+	if len(again.Reassignments) != 0 {
+		t.Errorf("Expected a balanced team to stay as it is, got %+v", again.Reassignments)
+	}
+}
+
+// reviewLoads counts the open reviews of every reviewer.
+func reviewLoads(t *testing.T, repos repository.Repository) map[string]int {
+	t.Helper()
+
+	prs, err := repos.PullRequest.List(context.Background(), domain.PRFilter{
+		Status: domain.PRStatusOpen,
+		SortBy: domain.PRSortCreatedAt,
+	})
+	if err != nil {
+		t.Fatalf("Failed to list PRs: %v", err)
+	}
+	loads := make(map[string]int)
+	for _, pr := range prs {
+		for _, r := range pr.AssignedReviewers {
+			loads[r]++
+		}
+	}
+	return loads
+}
@@ -0,0 +1,271 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sort"
+	"testing"
+
+	"pr-review-service/internal/domain"
+	"pr-review-service/internal/repository"
+)
+
+func TestManualReviewerChanges(t *testing.T) {
+	for name, newBackend := range testBackends {
+		t.Run(name, func(t *testing.T) {
+			server, repos := newBackend(t)
+			defer server.Close()
+			testManualReviewerChanges(t, server.URL, repos)
+		})
+	}
+}
+
+func testManualReviewerChanges(t *testing.T, serverURL string, repos repository.Repository) {
+	ctx := context.Background()
+
+	team := domain.Team{
+		TeamName: "manual",
+		Members: []domain.TeamMember{
+			{UserID: "mn-author", Username: "Author", IsActive: true},
+			{UserID: "mn-r1", Username: "Reviewer 1", IsActive: true},
+			{UserID: "mn-r2", Username: "Reviewer 2", IsActive: true},
+			{UserID: "mn-r3", Username: "Reviewer 3", IsActive: true},
+			{UserID: "mn-off", Username: "Off", IsActive: false},
+		},
+	}
+	if status, _ := postJSON(t, serverURL+"/team/add", team); status != http.StatusCreated {
+		t.Fatalf("Expected status 201 creating team, got %d", status)
+	}
+	seedReviews(t, repos,
+		domain.PullRequest{PullRequestID: "pr-mn-1", AuthorID: "mn-author", AssignedReviewers: []string{"mn-r1"}},
+	)
+
+	for _, tc := range []struct {
+		name    string
+		path    string
+		payload map[string]string
+		status  int
+		code    string
+	}{
+		{"Add the author", "/pullRequest/addReviewer", map[string]string{"user_id": "mn-author"}, http.StatusConflict, domain.ErrCodeReviewerIsAuthor},
+		{"Add an inactive user", "/pullRequest/addReviewer", map[string]string{"user_id": "mn-off"}, http.StatusConflict, domain.ErrCodeReviewerInactive},
+		{"Add an assigned reviewer", "/pullRequest/addReviewer", map[string]string{"user_id": "mn-r1"}, http.StatusConflict, domain.ErrCodeAlreadyAssigned},
+		{"Add an unknown user", "/pullRequest/addReviewer", map[string]string{"user_id": "mn-ghost"}, http.StatusNotFound, domain.ErrCodeNotFound},
+		{"Add", "/pullRequest/addReviewer", map[string]string{"user_id": "mn-r2"}, http.StatusOK, ""},
+		{"Add over the maximum", "/pullRequest/addReviewer", map[string]string{"user_id": "mn-r3"}, http.StatusConflict, domain.ErrCodeTooManyReviewers},
+		{"Swap to an assigned reviewer", "/pullRequest/swapReviewer", map[string]string{"old_user_id": "mn-r1", "new_user_id": "mn-r2"}, http.StatusConflict, domain.ErrCodeAlreadyAssigned},
+		{"Swap", "/pullRequest/swapReviewer", map[string]string{"old_user_id": "mn-r1", "new_user_id": "mn-r3"}, http.StatusOK, ""},
+		{"Swap a removed reviewer", "/pullRequest/swapReviewer", map[string]string{"old_user_id": "mn-r1", "new_user_id": "mn-r1"}, http.StatusConflict, domain.ErrCodeNotAssigned},
+		{"Remove", "/pullRequest/removeReviewer", map[string]string{"user_id": "mn-r2"}, http.StatusOK, ""},
+		{"Remove twice", "/pullRequest/removeReviewer", map[string]string{"user_id": "mn-r2"}, http.StatusConflict, domain.ErrCodeNotAssigned},
+		{"Add to an unknown PR", "/pullRequest/addReviewer", map[string]string{"pull_request_id": "pr-mn-ghost", "user_id": "mn-r2"}, http.StatusNotFound, domain.ErrCodeNotFound},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			payload := map[string]string{"pull_request_id": "pr-mn-1"}
+			for k, v := range tc.payload {
+				payload[k] = v
+			}
+
+			status, result := postJSON(t, serverURL+tc.path, payload)
+			if status != tc.status {
+				t.Fatalf("Expected status %d, got %d: %v", tc.status, status, result)
+			}
+			if tc.code != "" {
+				if code := result["error"].(map[string]interface{})["code"]; code != tc.code {
+					t.Errorf("Expected error %s, got %v", tc.code, code)
+				}
+			}
+		})
+	}
+
+	pr, err := repos.PullRequest.Get(ctx, "pr-mn-1")
+	if err != nil {
+		t.Fatalf("Failed to get PR: %v", err)
+	}
+	if !reflect.DeepEqual(pr.AssignedReviewers, []string{"mn-r3"}) {
+		t.Errorf("Expected mn-r3 to be the only reviewer left, got %v", pr.AssignedReviewers)
+	}
+	// One bump for each of the add, the swap and the removal.
+	if pr.Version != 4 {
+		t.Errorf("Expected the PR at version 4, got %d", pr.Version)
+	}
+
+	resp := postWithIfMatch(t, serverURL+"/pullRequest/removeReviewer", `"1"`,
+		map[string]string{"pull_request_id": "pr-mn-1", "user_id": "mn-r3"})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusPreconditionFailed {
+		t.Errorf("Expected status 412 for a stale If-Match, got %d", resp.StatusCode)
+	}
+
+	history, err := repos.History.ListByPR(ctx, "pr-mn-1")
+	if err != nil {
+		t.Fatalf("Failed to list history: %v", err)
+	}
+	var got []string
+	for _, rec := range history {
+		got = append(got, rec.UserID+" "+string(rec.AssignReason)+" "+string(rec.UnassignReason))
+	}
+	sort.Strings(got)
+	want := []string{
+		"mn-r1 INITIAL MANUAL_REASSIGN",
+		"mn-r2 MANUAL MANUAL",
+		"mn-r3 MANUAL_REASSIGN ",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected history %v, got %v", want, got)
+	}
+}
+
+func TestRequestedReviewersAndReroll(t *testing.T) {
+	for name, newBackend := range testBackends {
+		t.Run(name, func(t *testing.T) {
+			server, repos := newBackend(t)
+			defer server.Close()
+			testRequestedReviewersAndReroll(t, server.URL, repos)
+		})
+	}
+}
+
+func testRequestedReviewersAndReroll(t *testing.T, serverURL string, repos repository.Repository) {
+	ctx := context.Background()
+
+	team := domain.Team{
+		TeamName: "self-service",
+		Members: []domain.TeamMember{
+			{UserID: "ss-author", Username: "Author", IsActive: true},
+			{UserID: "ss-r1", Username: "Reviewer 1", IsActive: true},
+			{UserID: "ss-r2", Username: "Reviewer 2", IsActive: true},
+			{UserID: "ss-r3", Username: "Reviewer 3", IsActive: true},
+			{UserID: "ss-off", Username: "Off", IsActive: false},
+		},
+	}
+	if status, _ := postJSON(t, serverURL+"/team/add", team); status != http.StatusCreated {
+		t.Fatalf("Expected status 201 creating team, got %d", status)
+	}
+
+	createPR := func(prID string, requested ...string) (int, map[string]interface{}) {
+		return postJSON(t, serverURL+"/pullRequest/create", map[string]interface{}{
+			"pull_request_id":     prID,
+			"pull_request_name":   prID,
+			"author_id":           "ss-author",
+			"requested_reviewers": requested,
+		})
+	}
+
+	for _, tc := range []struct {
+		name      string
+		requested []string
+		code      string
+	}{
+		{"Inactive", []string{"ss-off"}, domain.ErrCodeReviewerInactive},
+		{"Author", []string{"ss-author"}, domain.ErrCodeReviewerIsAuthor},
+		{"Duplicate", []string{"ss-r1", "ss-r1"}, domain.ErrCodeAlreadyAssigned},
+		{"Too many", []string{"ss-r1", "ss-r2", "ss-r3"}, domain.ErrCodeTooManyReviewers},
+	} {
+		t.Run("Request "+tc.name, func(t *testing.T) {
+			status, result := createPR("pr-ss-bad", tc.requested...)
+			if status != http.StatusConflict {
+				t.Fatalf("Expected status 409, got %d: %v", status, result)
+			}
+			if code := result["error"].(map[string]interface{})["code"]; code != tc.code {
+				t.Errorf("Expected error %s, got %v", tc.code, code)
+			}
+		})
+	}
+	if exists, err := repos.PullRequest.Exists(ctx, "pr-ss-bad"); err != nil || exists {
+		t.Errorf("Expected rejected requests to create nothing, got %v, %v", exists, err)
+	}
+
+	t.Run("Requested reviewer first", func(t *testing.T) {
+		if status, result := createPR("pr-ss-1", "ss-r2"); status != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %v", status, result)
+		}
+
+		history, err := repos.History.ListByPR(ctx, "pr-ss-1")
+		if err != nil {
+			t.Fatalf("Failed to list history: %v", err)
+		}
+		reasons := make(map[string]domain.AssignmentReason)
+		for _, rec := range history {
+			reasons[rec.UserID] = rec.AssignReason
+		}
+		if len(reasons) != 2 || reasons["ss-r2"] != domain.AssignmentReasonRequested {
+			t.Fatalf("Expected ss-r2 requested and one random reviewer, got %+v", history)
+		}
+		for id, reason := range reasons {
+			if id != "ss-r2" && (reason != domain.AssignmentReasonInitial || id == "ss-off" || id == "ss-author") {
+				t.Errorf("Expected an active teammate picked at random, got %s for %s", reason, id)
+			}
+		}
+	})
+
+	t.Run("Only requested reviewers", func(t *testing.T) {
+		status, result := createPR("pr-ss-2", "ss-r3", "ss-r1")
+		if status != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %v", status, result)
+		}
+		pr, err := repos.PullRequest.Get(ctx, "pr-ss-2")
+		if err != nil {
+			t.Fatalf("Failed to get PR: %v", err)
+		}
+		sort.Strings(pr.AssignedReviewers)
+		if !reflect.DeepEqual(pr.AssignedReviewers, []string{"ss-r1", "ss-r3"}) {
+			t.Errorf("Expected exactly the requested reviewers, got %v", pr.AssignedReviewers)
+		}
+	})
+
+	t.Run("Reroll", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			before, err := repos.PullRequest.Get(ctx, "pr-ss-2")
+			if err != nil {
+				t.Fatalf("Failed to get PR: %v", err)
+			}
+
+			status, result := postJSON(t, serverURL+"/pullRequest/reroll", map[string]string{"pull_request_id": "pr-ss-2"})
+			if status != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %v", status, result)
+			}
+
+			after, err := repos.PullRequest.Get(ctx, "pr-ss-2")
+			if err != nil {
+				t.Fatalf("Failed to get PR: %v", err)
+			}
+			if len(after.AssignedReviewers) != domain.MaxReviewers || after.Version != before.Version+1 {
+				t.Fatalf("Expected a full new set and a version bump, got %+v", after)
+			}
+			for _, id := range after.AssignedReviewers {
+				if id != "ss-r1" && id != "ss-r2" && id != "ss-r3" {
+					t.Errorf("Expected only active teammates, got %s", id)
+				}
+			}
+		}
+
+		history, err := repos.History.ListByPR(ctx, "pr-ss-2")
+		if err != nil {
+			t.Fatalf("Failed to list history: %v", err)
+		}
+		open := 0
+		for _, rec := range history {
+			if rec.UnassignedAt == nil {
+				open++
+			}
+			if rec.UnassignedAt != nil && rec.UnassignReason != domain.AssignmentReasonReroll {
+				t.Errorf("Expected reviewers dropped by a reroll to be closed as REROLL, got %+v", rec)
+			}
+		}
+		if open != domain.MaxReviewers {
+			t.Errorf("Expected the history to end with %d open entries, got %+v", domain.MaxReviewers, history)
+		}
+	})
+
+	t.Run("Reroll merged PR", func(t *testing.T) {
+		if status, _ := postJSON(t, serverURL+"/pullRequest/merge", map[string]string{"pull_request_id": "pr-ss-1"}); status != http.StatusOK {
+			t.Fatalf("Expected status 200 merging, got %d", status)
+		}
+		status, _ := postJSON(t, serverURL+"/pullRequest/reroll", map[string]string{"pull_request_id": "pr-ss-1"})
+		if status != http.StatusConflict {
+			t.Errorf("Expected status 409, got %d", status)
+		}
+	})
+}
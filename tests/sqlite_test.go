@@ -0,0 +1,293 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pr-review-service/internal/domain"
+	"pr-review-service/internal/repository"
+	"pr-review-service/internal/repository/sqlite"
+	"pr-review-service/internal/service"
+	httpTransport "pr-review-service/internal/transport/http"
+	"pr-review-service/migration"
+)
+
+// newSQLiteTestDB opens a fresh, fully migrated database file that is removed
+// after the test.
+func newSQLiteTestDB(t testing.TB) *sql.DB {
+	t.Helper()
+
+	ctx := context.Background()
+	db, err := sqlite.Connect(ctx, "sqlite:"+filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := sqlite.NewMigrator(db, migration.SQLite)
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+	if _, err := migrator.Up(ctx, false); err != nil {
+		t.Fatalf("Failed to run migrations: %v", err)
+	}
+	return db
+}
+
+// newSQLiteTestServer wires the services to the SQLite repositories.
+func newSQLiteTestServer(t *testing.T, db *sql.DB) *httptest.Server {
+	t.Helper()
+
+	teamRepo := sqlite.NewTeamRepo(db)
+	userRepo := sqlite.NewUserRepo(db)
+	prRepo := sqlite.NewPullRequestRepo(db)
+	auditRepo := sqlite.NewAuditRepo(db)
+	historyRepo := sqlite.NewAssignmentHistoryRepo(db)
+	statsRepo := sqlite.NewStatsRepo(db)
+	uow := sqlite.NewUnitOfWork(db)
+
+	teamService := service.NewTeamService(teamRepo, userRepo, uow)
+	prService := service.NewPRService(prRepo, userRepo, teamRepo, historyRepo, uow)
+	userService := service.NewUserService(userRepo, prRepo, prService, uow)
+	auditService := service.NewAuditService(auditRepo)
+	statsService := service.NewStatsService(statsRepo, teamRepo, userRepo)
+	importService := service.NewImportService(uow)
+
+	handler := httpTransport.NewHandler(teamService, userService, prService, auditService, statsService, importService)
+	return httptest.NewServer(httpTransport.NewRouter(handler))
+}
+
+func TestSQLiteStorage(t *testing.T) {
+	db := newSQLiteTestDB(t)
+	server := newSQLiteTestServer(t, db)
+	defer server.Close()
+
+	ctx := context.Background()
+
+	team := domain.Team{
+		TeamName: "sqlite",
+		Members: []domain.TeamMember{
+			{UserID: "s1", Username: "Author", IsActive: true},
+			{UserID: "s2", Username: "Reviewer", IsActive: true},
+			{UserID: "s3", Username: "Secret Name", IsActive: true},
+			{UserID: "s4", Username: "Spare", IsActive: true},
+		},
+	}
+	if status, _ := postJSON(t, server.URL+"/team/add", team); status != http.StatusCreated {
+		t.Fatalf("Expected status 201 creating team, got %d", status)
+	}
+
+	t.Run("Create, reassign and merge", func(t *testing.T) {
+		status, result := postJSON(t, server.URL+"/pullRequest/create", map[string]string{
+			"pull_request_id":   "pr-sqlite-1",
+			"pull_request_name": "SQLite",
+			"author_id":         "s1",
+		})
+		if status != http.StatusCreated {
+			t.Fatalf("Expected status 201 creating PR, got %d", status)
+		}
+		reviewers := result["pr"].(map[string]interface{})["assigned_reviewers"].([]interface{})
+		if len(reviewers) != 2 {
+			t.Fatalf("Expected 2 reviewers, got %v", reviewers)
+		}
+
+		status, _ = postJSON(t, server.URL+"/pullRequest/reassign", map[string]string{
+			"pull_request_id": "pr-sqlite-1",
+			"old_user_id":     reviewers[0].(string),
+		})
+		if status != http.StatusOK {
+			t.Fatalf("Expected status 200 reassigning, got %d", status)
+		}
+
+		status, result = postJSON(t, server.URL+"/pullRequest/merge", map[string]string{"pull_request_id": "pr-sqlite-1"})
+		if status != http.StatusOK {
+			t.Fatalf("Expected status 200 merging, got %d", status)
+		}
+		if s := result["pr"].(map[string]interface{})["status"]; s != string(domain.PRStatusMerged) {
+			t.Errorf("Expected status MERGED, got %v", s)
+		}
+
+		_, page := listPRs(t, server.URL, url.Values{"status": {string(domain.PRStatusMerged)}})
+		if len(page.PullRequests) != 1 || page.PullRequests[0].PullRequestID != "pr-sqlite-1" {
+			t.Errorf("Expected the merged PR in the listing, got %v", page.PullRequests)
+		}
+
+		history, err := sqlite.NewAssignmentHistoryRepo(db).ListByPR(ctx, "pr-sqlite-1")
+		if err != nil {
+			t.Fatalf("Failed to list history: %v", err)
+		}
+		unassigned := 0
+		for _, rec := range history {
+			if rec.UnassignedAt != nil {
+				unassigned++
+			}
+		}
+		if len(history) != 3 || unassigned != 1 {
+			t.Errorf("Expected two initial assignments and a reassignment, got %+v", history)
+		}
+	})
+
+	t.Run("Concurrent create of the same PR", func(t *testing.T) {
+		statuses := fireConcurrently(t, concurrentRequests, server.URL+"/pullRequest/create", map[string]string{
+			"pull_request_id":   "pr-sqlite-race",
+			"pull_request_name": "Race",
+			"author_id":         "s1",
+		})
+
+		counts := countStatuses(statuses)
+		if counts[http.StatusCreated] != 1 || counts[http.StatusConflict] != concurrentRequests-1 {
+			t.Errorf("Expected one 201 and %d conflicts, got statuses %v", concurrentRequests-1, counts)
+		}
+	})
+
+	t.Run("Anonymize scrubs username everywhere", func(t *testing.T) {
+		status, _ := postJSON(t, server.URL+"/users/anonymize", map[string]string{"user_id": "s3"})
+		if status != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", status)
+		}
+
+		page := getAudit(t, server.URL, url.Values{"limit": {"500"}})
+		if len(page.Events) == 0 {
+			t.Fatal("Expected audit events")
+		}
+		for _, e := range page.Events {
+			if strings.Contains(string(e.Before), "Secret Name") || strings.Contains(string(e.After), "Secret Name") {
+				t.Errorf("Audit event %d still contains the original username", e.ID)
+			}
+		}
+	})
+
+	t.Run("Audit log is append-only", func(t *testing.T) {
+		if _, err := db.ExecContext(ctx, "UPDATE audit_events SET actor = 'someone else'"); err == nil {
+			t.Error("Expected updating audit events to fail")
+		}
+		if _, err := db.ExecContext(ctx, "DELETE FROM audit_events"); err == nil {
+			t.Error("Expected deleting audit events to fail")
+		}
+	})
+
+	t.Run("Deactivate team records activation history", func(t *testing.T) {
+		status, _ := postJSON(t, server.URL+"/team/deactivate-all?team_name=sqlite", nil)
+		if status != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", status)
+		}
+
+		team, err := sqlite.NewTeamRepo(db).Get(ctx, "sqlite")
+		if err != nil {
+			t.Fatalf("Failed to get team: %v", err)
+		}
+		for _, m := range team.Members {
+			if m.IsActive {
+				t.Errorf("Expected %s to be inactive", m.UserID)
+			}
+		}
+
+		for _, m := range team.Members {
+			var deactivations int
+			db.QueryRowContext(ctx, "SELECT COUNT(*) FROM user_activation_history WHERE user_id = ? AND is_active = 0",
+				m.UserID).Scan(&deactivations)
+			if deactivations != 1 {
+				t.Errorf("Expected one deactivation of %s in the history, got %d", m.UserID, deactivations)
+			}
+		}
+	})
+
+	t.Run("Schema rejects invalid rows", func(t *testing.T) {
+		if _, err := db.ExecContext(ctx, `
+			INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status)
+			VALUES ('pr-sqlite-bad', 'Bad', 's1', 'CLOSED')`); err == nil {
+			t.Error("Expected an unknown status to be rejected")
+		}
+		if _, err := db.ExecContext(ctx, `
+			INSERT INTO pr_reviewers (pull_request_id, user_id) VALUES ('pr-sqlite-1', 's4'), ('pr-sqlite-1', 's4')`); err == nil {
+			t.Error("Expected a duplicate reviewer to be rejected")
+		}
+		if _, err := db.ExecContext(ctx, `
+			INSERT INTO pr_reviewers (pull_request_id, user_id) VALUES ('pr-missing', 's4')`); err == nil {
+			t.Error("Expected a reviewer of a missing PR to be rejected")
+		}
+	})
+
+	t.Run("Unit of work rolls back on error", func(t *testing.T) {
+		errAbort := errors.New("abort")
+		err := sqlite.NewUnitOfWork(db).WithinTx(ctx, func(ctx context.Context, repos repository.Repository) error {
+			if err := repos.Team.Create(ctx, &domain.Team{TeamName: "rolled-back"}); err != nil {
+				return err
+			}
+			return errAbort
+		})
+		if !errors.Is(err, errAbort) {
+			t.Fatalf("Expected the error from fn, got %v", err)
+		}
+
+		exists, err := sqlite.NewTeamRepo(db).Exists(ctx, "rolled-back")
+		if err != nil {
+			t.Fatalf("Failed to check team: %v", err)
+		}
+		if exists {
+			t.Error("Expected the team created inside the failed unit of work to be gone")
+		}
+	})
+}
+
+func TestSQLiteMigrations(t *testing.T) {
+	ctx := context.Background()
+	db, err := sqlite.Connect(ctx, "sqlite:"+filepath.Join(t.TempDir(), "migrate.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	migrator, err := sqlite.NewMigrator(db, migration.SQLite)
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	applied, err := migrator.Up(ctx, true)
+	if err != nil {
+		t.Fatalf("Failed to apply migrations: %v", err)
+	}
+	if len(applied) < 2 {
+		t.Fatalf("Expected the schema and the demo data to be applied, got %v", applied)
+	}
+
+	prs, err := sqlite.NewPullRequestRepo(db).List(ctx, domain.PRFilter{SortBy: domain.PRSortCreatedAt})
+	if err != nil {
+		t.Fatalf("Failed to list demo PRs: %v", err)
+	}
+	if len(prs) != 4 {
+		t.Errorf("Expected 4 demo PRs, got %d", len(prs))
+	}
+
+	rolledBack, err := migrator.Down(ctx, len(applied))
+	if err != nil || len(rolledBack) != len(applied) {
+		t.Fatalf("Expected every migration to be rolled back, got %v, %v", rolledBack, err)
+	}
+	var tables int
+	db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM sqlite_master
+		WHERE type = 'table' AND name NOT IN ('schema_migrations', 'sqlite_sequence')`).Scan(&tables)
+	if tables != 0 {
+		t.Errorf("Expected rolling back to drop every table, %d left", tables)
+	}
+
+	if _, err := migrator.Up(ctx, false); err != nil {
+		t.Fatalf("Failed to reapply migrations: %v", err)
+	}
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get status: %v", err)
+	}
+	for _, s := range statuses {
+		if (s.AppliedAt != nil) != (s.Kind == "schema") {
+			t.Errorf("Expected only schema migrations to be applied, got %+v", s)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"pr-review-service/internal/domain"
+)
+
+func getMetrics(t *testing.T, baseURL string, params url.Values) (int, domain.ReviewMetrics) {
+	t.Helper()
+
+	resp, err := http.Get(baseURL + "/stats/metrics?" + params.Encode())
+	if err != nil {
+		t.Fatalf("Failed to get metrics: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Metrics domain.ReviewMetrics `json:"metrics"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+	return resp.StatusCode, body.Metrics
+}
+
+func TestReviewMetrics(t *testing.T) {
+	pool, teardown := setupTestDB(t)
+	if pool == nil {
+		return
+	}
+	defer teardown()
+
+	server := newTestServer(t, pool)
+	defer server.Close()
+
+	team := domain.Team{
+		TeamName: "metrics-team",
+		Members: []domain.TeamMember{
+			{UserID: "m1", Username: "Metrics1", IsActive: true},
+			{UserID: "m2", Username: "Metrics2", IsActive: true},
+			{UserID: "m3", Username: "Metrics3", IsActive: true},
+		},
+	}
+	if status, _ := postJSON(t, server.URL+"/team/add", team); status != http.StatusCreated {
+		t.Fatalf("Expected status 201 creating team, got %d", status)
+	}
+	for i := 1; i <= 3; i++ {
+		if status, _ := postJSON(t, server.URL+"/pullRequest/create", map[string]string{
+			"pull_request_id":   fmt.Sprintf("pr-metrics-%d", i),
+			"pull_request_name": "Metrics",
+			"author_id":         "m1",
+		}); status != http.StatusCreated {
+			t.Fatalf("Expected status 201 creating PR, got %d", status)
+		}
+	}
+	for _, id := range []string{"pr-metrics-1", "pr-metrics-2"} {
+		if status, _ := postJSON(t, server.URL+"/pullRequest/merge", map[string]string{"pull_request_id": id}); status != http.StatusOK {
+			t.Fatalf("Expected status 200 merging PR, got %d", status)
+		}
+	}
+
+	t.Run("Team metrics", func(t *testing.T) {
+		status, metrics := getMetrics(t, server.URL, url.Values{"team_name": {"metrics-team"}})
+		if status != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", status)
+		}
+
+		if metrics.TimeToMerge.Count != 2 || metrics.TimeToMerge.MedianSeconds == nil {
+			t.Errorf("Expected time to merge over 2 PRs, got %+v", metrics.TimeToMerge)
+		}
+		if metrics.TimeToFirstReview.Count != 3 {
+			t.Errorf("Expected time to first review over 3 PRs, got %+v", metrics.TimeToFirstReview)
+		}
+
+		var opened, merged int
+		for _, week := range metrics.Throughput {
+			opened += week.Opened
+			merged += week.Merged
+		}
+		if opened != 3 || merged != 2 {
+			t.Errorf("Expected 3 opened and 2 merged PRs, got %d and %d", opened, merged)
+		}
+
+		// m2 and m3 are the only candidates, so both review both merged PRs.
+		if metrics.ReviewsCompleted != 4 {
+			t.Errorf("Expected 4 completed reviews, got %d", metrics.ReviewsCompleted)
+		}
+	})
+
+	t.Run("User metrics", func(t *testing.T) {
+		_, metrics := getMetrics(t, server.URL, url.Values{"user_id": {"m2"}})
+		if metrics.ReviewsCompleted != 2 {
+			t.Errorf("Expected 2 completed reviews for m2, got %d", metrics.ReviewsCompleted)
+		}
+		if metrics.TimeToMerge.Count != 0 || metrics.TimeToMerge.MedianSeconds != nil {
+			t.Errorf("Expected no merged PRs authored by m2, got %+v", metrics.TimeToMerge)
+		}
+	})
+
+	t.Run("Window in the past is empty", func(t *testing.T) {
+		to := time.Now().Add(-24 * time.Hour)
+		_, metrics := getMetrics(t, server.URL, url.Values{
+			"team_name": {"metrics-team"},
+			"from":      {to.Add(-7 * 24 * time.Hour).Format(time.RFC3339)},
+			"to":        {to.Format(time.RFC3339)},
+		})
+		if metrics.TimeToMerge.Count != 0 || metrics.ReviewsCompleted != 0 {
+			t.Errorf("Expected empty metrics, got %+v", metrics)
+		}
+	})
+
+	t.Run("Invalid input", func(t *testing.T) {
+		now := time.Now()
+		for _, params := range []url.Values{
+			{"from": {now.Format(time.RFC3339)}, "to": {now.Add(-time.Hour).Format(time.RFC3339)}},
+			{"from": {now.AddDate(-2, 0, 0).Format(time.RFC3339)}},
+			{"to": {"tomorrow"}},
+		} {
+			if status, _ := getMetrics(t, server.URL, params); status != http.StatusBadRequest {
+				t.Errorf("Expected status 400 for %v, got %d", params, status)
+			}
+		}
+		if status, _ := getMetrics(t, server.URL, url.Values{"team_name": {"missing"}}); status != http.StatusNotFound {
+			t.Errorf("Expected status 404 for unknown team, got %d", status)
+		}
+	})
+
+	t.Run("Leaderboard limit", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/stats?limit=1")
+		if err != nil {
+			t.Fatalf("Failed to get stats: %v", err)
+		}
+		defer resp.Body.Close()
+
+		var body struct {
+			Stats []domain.UserStats `json:"stats"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		if len(body.Stats) != 1 {
+			t.Errorf("Expected 1 stats entry, got %d", len(body.Stats))
+		}
+	})
+}
+
+func TestFairnessReport(t *testing.T) {
+	pool, teardown := setupTestDB(t)
+	if pool == nil {
+		return
+	}
+	defer teardown()
+
+	server := newTestServer(t, pool)
+	defer server.Close()
+
+	team := domain.Team{
+		TeamName: "fairness-team",
+		Members: []domain.TeamMember{
+			{UserID: "f1", Username: "Fairness1", IsActive: true},
+			{UserID: "f2", Username: "Fairness2", IsActive: true},
+			{UserID: "f3", Username: "Fairness3", IsActive: true},
+		},
+	}
+	if status, _ := postJSON(t, server.URL+"/team/add", team); status != http.StatusCreated {
+		t.Fatalf("Expected status 201 creating team, got %d", status)
+	}
+	// f1 authors everything, so f2 and f3 get all reviews and f1 none.
+	for i := 1; i <= 4; i++ {
+		if status, _ := postJSON(t, server.URL+"/pullRequest/create", map[string]string{
+			"pull_request_id":   fmt.Sprintf("pr-fairness-%d", i),
+			"pull_request_name": "Fairness",
+			"author_id":         "f1",
+		}); status != http.StatusCreated {
+			t.Fatalf("Expected status 201 creating PR, got %d", status)
+		}
+	}
+
+	resp, err := http.Get(server.URL + "/stats/fairness?team_name=fairness-team")
+	if err != nil {
+		t.Fatalf("Failed to get fairness report: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Report domain.FairnessReport `json:"report"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+
+	if len(body.Report.Teams) != 1 {
+		t.Fatalf("Expected 1 team, got %d", len(body.Report.Teams))
+	}
+	report := body.Report.Teams[0]
+
+	if report.Assignments != 8 {
+		t.Errorf("Expected 8 assignments, got %d", report.Assignments)
+	}
+	if report.Gini <= 0 {
+		t.Errorf("Expected a positive Gini coefficient, got %f", report.Gini)
+	}
+	if fmt.Sprint(report.Overloaded) != "[f2 f3]" {
+		t.Errorf("Expected f2 and f3 to be overloaded, got %v", report.Overloaded)
+	}
+	if fmt.Sprint(report.Underloaded) != "[f1]" {
+		t.Errorf("Expected f1 to be underloaded, got %v", report.Underloaded)
+	}
+	for _, m := range report.Members {
+		if m.ActiveDays <= 0 || m.LoadRatio == nil {
+			t.Errorf("Expected %s to have active time and a load ratio, got %+v", m.UserID, m)
+		}
+	}
+}
+
+func TestUserStatsWithOffsetBounds(t *testing.T) {
+	for name, newBackend := range testBackends {
+		t.Run(name, func(t *testing.T) {
+			server, _ := newBackend(t)
+			defer server.Close()
+
+			team := domain.Team{
+				TeamName: "stats-offset",
+				Members: []domain.TeamMember{
+					{UserID: "so1", Username: "Offset1", IsActive: true},
+					{UserID: "so2", Username: "Offset2", IsActive: true},
+				},
+			}
+			if status, _ := postJSON(t, server.URL+"/team/add", team); status != http.StatusCreated {
+				t.Fatalf("Expected status 201 creating team, got %d", status)
+			}
+
+			from := time.Now().Add(-time.Minute)
+			if status, _ := postJSON(t, server.URL+"/pullRequest/create", map[string]string{
+				"pull_request_id":   "pr-so-1",
+				"pull_request_name": "Offset",
+				"author_id":         "so1",
+			}); status != http.StatusCreated {
+				t.Fatalf("Expected status 201 creating PR, got %d", status)
+			}
+			to := time.Now().Add(time.Minute)
+
+			reviews := func(params url.Values) map[string]int {
+				t.Helper()
+
+				resp, err := http.Get(server.URL + "/stats?" + params.Encode())
+				if err != nil {
+					t.Fatalf("Failed to get stats: %v", err)
+				}
+				defer resp.Body.Close()
+				if resp.StatusCode != http.StatusOK {
+					t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+				}
+
+				var body struct {
+					Stats []domain.UserStats `json:"stats"`
+				}
+				json.NewDecoder(resp.Body).Decode(&body)
+				counts := make(map[string]int)
+				for _, s := range body.Stats {
+					if s.ReviewCount > 0 {
+						counts[s.UserID] = s.ReviewCount
+					}
+				}
+				return counts
+			}
+
+			for _, zone := range []*time.Location{time.UTC, utcPlus3} {
+				got := reviews(url.Values{
+					"from": {from.In(zone).Format(time.RFC3339)},
+					"to":   {to.In(zone).Format(time.RFC3339)},
+				})
+				if len(got) != 1 || got["so2"] != 1 {
+					t.Errorf("Expected one review by so2 within %s bounds, got %v", zone, got)
+				}
+				if got := reviews(url.Values{"from": {to.In(zone).Format(time.RFC3339)}}); len(got) != 0 {
+					t.Errorf("Expected no reviews after %s, got %v", to.In(zone).Format(time.RFC3339), got)
+				}
+			}
+		})
+	}
+}
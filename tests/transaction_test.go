@@ -0,0 +1,210 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"pr-review-service/internal/domain"
+	"pr-review-service/internal/repository"
+	"pr-review-service/internal/repository/postgres"
+	"pr-review-service/internal/service"
+)
+
+var errInjected = errors.New("injected failure")
+
+// faultyUnitOfWork wraps a real unit of work and lets a test swap the
+// transaction-bound repositories for ones that fail midway.
+type faultyUnitOfWork struct {
+	inner repository.UnitOfWork
+	wrap  func(repos repository.Repository) repository.Repository
+}
+
+func (u *faultyUnitOfWork) WithinTx(ctx context.Context, fn func(ctx context.Context, repos repository.Repository) error) error {
+	return u.inner.WithinTx(ctx, func(ctx context.Context, repos repository.Repository) error {
+		return fn(ctx, u.wrap(repos))
+	})
+}
+
+type failingAssignRepo struct {
+	repository.PullRequestRepository
+}
+
+func (r *failingAssignRepo) AssignReviewer(context.Context, string, string) error {
+	return errInjected
+}
+
+func (r *failingAssignRepo) ReassignReviewersInBatch(context.Context, string, map[string]string) error {
+	return errInjected
+}
+
+type failingUserCreateRepo struct {
+	repository.UserRepository
+	calls  int
+	failAt int
+}
+
+func (r *failingUserCreateRepo) Create(ctx context.Context, user *domain.User) error {
+	r.calls++
+	if r.calls == r.failAt {
+		return errInjected
+	}
+	return r.UserRepository.Create(ctx, user)
+}
+
+func TestTransactionRollback(t *testing.T) {
+	pool, teardown := setupTestDB(t)
+	if pool == nil {
+		return
+	}
+	defer teardown()
+
+	ctx := context.Background()
+	if err := postgres.RunMigrations(ctx, pool, "../migration"); err != nil {
+		t.Fatalf("Failed to run migrations: %v", err)
+	}
+
+	teamRepo := postgres.NewTeamRepo(pool)
+	userRepo := postgres.NewUserRepo(pool)
+	prRepo := postgres.NewPullRequestRepo(pool)
+	historyRepo := postgres.NewAssignmentHistoryRepo(pool)
+	uow := postgres.NewUnitOfWork(pool)
+
+	failAssign := &faultyUnitOfWork{
+		inner: uow,
+		wrap: func(repos repository.Repository) repository.Repository {
+			repos.PullRequest = &failingAssignRepo{PullRequestRepository: repos.PullRequest}
+			return repos
+		},
+	}
+
+	teamService := service.NewTeamService(teamRepo, userRepo, uow)
+	prService := service.NewPRService(prRepo, userRepo, teamRepo, historyRepo, uow)
+	faultyPRService := service.NewPRService(prRepo, userRepo, teamRepo, historyRepo, failAssign)
+
+	_, err := teamService.CreateTeam(ctx, &domain.Team{
+		TeamName: "tx-team",
+		Members: []domain.TeamMember{
+			{UserID: "tx1", Username: "TxUser1", IsActive: true},
+			{UserID: "tx2", Username: "TxUser2", IsActive: true},
+			{UserID: "tx3", Username: "TxUser3", IsActive: true},
+			{UserID: "tx4", Username: "TxUser4", IsActive: true},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create team: %v", err)
+	}
+
+	pr, err := prService.CreatePR(ctx, "pr-tx-001", "Tx Feature", "tx1", nil)
+	if err != nil {
+		t.Fatalf("Failed to create PR: %v", err)
+	}
+	if len(pr.AssignedReviewers) != 2 {
+		t.Fatalf("Expected 2 reviewers, got %v", pr.AssignedReviewers)
+	}
+
+	t.Run("Reassign rolls back removal", func(t *testing.T) {
+		oldReviewer := pr.AssignedReviewers[0]
+
+		_, _, err := faultyPRService.ReassignReviewer(ctx, pr.PullRequestID, oldReviewer, domain.VersionMatch{})
+		if !errors.Is(err, errInjected) {
+			t.Fatalf("Expected injected error, got %v", err)
+		}
+
+		isReviewer, err := prRepo.IsReviewer(ctx, pr.PullRequestID, oldReviewer)
+		if err != nil {
+			t.Fatalf("Failed to check reviewer: %v", err)
+		}
+		if !isReviewer {
+			t.Error("Old reviewer was removed although the reassignment failed")
+		}
+
+		reviewers, err := prRepo.GetReviewers(ctx, pr.PullRequestID)
+		if err != nil {
+			t.Fatalf("Failed to get reviewers: %v", err)
+		}
+		if len(reviewers) != 2 {
+			t.Errorf("Expected 2 reviewers after rollback, got %v", reviewers)
+		}
+	})
+
+	t.Run("Team deactivation rolls back", func(t *testing.T) {
+		_, err := teamService.CreateTeam(ctx, &domain.Team{
+			TeamName: "tx-other",
+			Members: []domain.TeamMember{
+				{UserID: "txo1", Username: "TxOther1", IsActive: true},
+			},
+		})
+		if err != nil {
+			t.Fatalf("Failed to create team: %v", err)
+		}
+
+		// Move one reviewer of pr-tx-001 into another team so that deactivating
+		// it forces a reassignment from the author's team.
+		reviewer, err := userRepo.Get(ctx, pr.AssignedReviewers[0])
+		if err != nil {
+			t.Fatalf("Failed to get reviewer: %v", err)
+		}
+		reviewer.TeamName = "tx-other"
+		if err := userRepo.Update(ctx, reviewer); err != nil {
+			t.Fatalf("Failed to move reviewer: %v", err)
+		}
+
+		_, _, err = faultyPRService.DeactivateTeamAndReassign(ctx, "tx-other", domain.VersionMatch{})
+		if !errors.Is(err, errInjected) {
+			t.Fatalf("Expected injected error, got %v", err)
+		}
+
+		members, err := userRepo.GetByTeam(ctx, "tx-other")
+		if err != nil {
+			t.Fatalf("Failed to get members: %v", err)
+		}
+		for _, m := range members {
+			if !m.IsActive {
+				t.Errorf("User %s was deactivated although the operation failed", m.UserID)
+			}
+		}
+
+		isReviewer, err := prRepo.IsReviewer(ctx, pr.PullRequestID, reviewer.UserID)
+		if err != nil {
+			t.Fatalf("Failed to check reviewer: %v", err)
+		}
+		if !isReviewer {
+			t.Error("Reviewer was removed although the operation failed")
+		}
+	})
+
+	t.Run("Team creation rolls back", func(t *testing.T) {
+		failCreate := &faultyUnitOfWork{
+			inner: uow,
+			wrap: func(repos repository.Repository) repository.Repository {
+				repos.User = &failingUserCreateRepo{UserRepository: repos.User, failAt: 2}
+				return repos
+			},
+		}
+		faultyTeamService := service.NewTeamService(teamRepo, userRepo, failCreate)
+
+		_, err := faultyTeamService.CreateTeam(ctx, &domain.Team{
+			TeamName: "tx-broken",
+			Members: []domain.TeamMember{
+				{UserID: "txb1", Username: "TxBroken1", IsActive: true},
+				{UserID: "txb2", Username: "TxBroken2", IsActive: true},
+			},
+		})
+		if !errors.Is(err, errInjected) {
+			t.Fatalf("Expected injected error, got %v", err)
+		}
+
+		exists, err := teamRepo.Exists(ctx, "tx-broken")
+		if err != nil {
+			t.Fatalf("Failed to check team: %v", err)
+		}
+		if exists {
+			t.Error("Team exists although its creation failed")
+		}
+
+		if _, err := userRepo.Get(ctx, "txb1"); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("Expected first member to be rolled back, got %v", err)
+		}
+	})
+}
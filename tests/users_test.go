@@ -0,0 +1,137 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"pr-review-service/internal/domain"
+)
+
+type userPage struct {
+	Users      []domain.UserProfile `json:"users"`
+	NextCursor string               `json:"next_cursor"`
+}
+
+func listUsers(t *testing.T, baseURL string, params url.Values) (int, userPage) {
+	t.Helper()
+
+	resp, err := http.Get(baseURL + "/users/list?" + params.Encode())
+	if err != nil {
+		t.Fatalf("Failed to list users: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var page userPage
+	json.NewDecoder(resp.Body).Decode(&page)
+	return resp.StatusCode, page
+}
+
+func TestUserDirectory(t *testing.T) {
+	pool, teardown := setupTestDB(t)
+	if pool == nil {
+		return
+	}
+	defer teardown()
+
+	server := newTestServer(t, pool)
+	defer server.Close()
+
+	team := domain.Team{
+		TeamName: "directory",
+		Members: []domain.TeamMember{
+			{UserID: "d1", Username: "Dana", IsActive: true},
+			{UserID: "d2", Username: "Dave", IsActive: true},
+			{UserID: "d3", Username: "Diana", IsActive: true},
+			{UserID: "d4", Username: "Zed", IsActive: false},
+		},
+	}
+	if status, _ := postJSON(t, server.URL+"/team/add", team); status != http.StatusCreated {
+		t.Fatalf("Expected status 201 creating team, got %d", status)
+	}
+	for i := 1; i <= 2; i++ {
+		if status, _ := postJSON(t, server.URL+"/pullRequest/create", map[string]string{
+			"pull_request_id":   fmt.Sprintf("pr-dir-%d", i),
+			"pull_request_name": "Directory",
+			"author_id":         "d1",
+		}); status != http.StatusCreated {
+			t.Fatalf("Expected status 201 creating PR, got %d", status)
+		}
+	}
+
+	t.Run("Get user with activity", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/users/get?user_id=d2")
+		if err != nil {
+			t.Fatalf("Failed to get user: %v", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+
+		var body struct {
+			User domain.UserProfile `json:"user"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+
+		// d2 and d3 are the only active candidates, so both review both PRs.
+		if body.User.OpenReviewCount != 2 {
+			t.Errorf("Expected 2 open reviews, got %d", body.User.OpenReviewCount)
+		}
+		if body.User.LastAssignedAt == nil {
+			t.Error("Expected last_assigned_at to be set")
+		}
+	})
+
+	t.Run("Get unknown user", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/users/get?user_id=missing")
+		if err != nil {
+			t.Fatalf("Failed to get user: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("List by team and activity", func(t *testing.T) {
+		_, page := listUsers(t, server.URL, url.Values{"team_name": {"directory"}, "is_active": {"true"}})
+		if len(page.Users) != 3 {
+			t.Fatalf("Expected 3 active users, got %d", len(page.Users))
+		}
+		for _, u := range page.Users {
+			if u.UserID == "d1" && u.AuthoredOpenPRCount != 2 {
+				t.Errorf("Expected d1 to author 2 open PRs, got %d", u.AuthoredOpenPRCount)
+			}
+		}
+	})
+
+	t.Run("List by name prefix with pagination", func(t *testing.T) {
+		var names []string
+		params := url.Values{"name_prefix": {"Da"}, "limit": {"1"}}
+		for {
+			status, page := listUsers(t, server.URL, params)
+			if status != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", status)
+			}
+			for _, u := range page.Users {
+				names = append(names, u.Username)
+			}
+			if page.NextCursor == "" {
+				break
+			}
+			params.Set("cursor", page.NextCursor)
+		}
+		if fmt.Sprint(names) != "[Dana Dave]" {
+			t.Errorf("Expected [Dana Dave], got %v", names)
+		}
+	})
+
+	t.Run("Invalid is_active", func(t *testing.T) {
+		if status, _ := listUsers(t, server.URL, url.Values{"is_active": {"maybe"}}); status != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", status)
+		}
+	})
+}